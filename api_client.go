@@ -0,0 +1,169 @@
+package main
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// criticalCookieNames 是维持百度登录态所必需的 Cookie
+var criticalCookieNames = []string{"BDUSS", "STOKEN", "PTOKEN"}
+
+// CookieChange 描述服务器通过 Set-Cookie 对登录态做出的修改
+type CookieChange struct {
+	Updated         []string // 值发生变化的 Cookie 名称
+	Expired         []string // 被服务器删除或已过期的 Cookie 名称
+	CriticalUpdated bool     // 是否包含关键 Cookie 的更新
+	CriticalExpired bool     // 是否包含关键 Cookie 的过期
+	Cookie          string   // 变化后的完整 Cookie 请求头
+}
+
+// Client 是访问百度教育平台的 HTTP 客户端，使用 CookieJar 维护登录态，
+// 服务器通过 Set-Cookie 下发的新 Cookie 会在后续请求中自动携带
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+	jar     *trackingJar
+}
+
+// NewClient 使用用户提供的 Cookie 请求头创建客户端，设置了录制器时录制所有请求
+func NewClient(serverBaseURL, cookie string) *Client {
+	jar := newTrackingJar(serverBaseURL)
+	jar.seed(cookie)
+	client := &Client{
+		BaseURL: serverBaseURL,
+		HTTP:    &http.Client{Jar: jar},
+		jar:     jar,
+	}
+	if recorder := defaultRecorder.Load(); recorder != nil {
+		client.HTTP.Transport = recorder.Transport(nil)
+	}
+	return client
+}
+
+// Cookie 返回当前 CookieJar 中对平台接口有效的 Cookie 请求头
+func (c *Client) Cookie() string {
+	return c.jar.header()
+}
+
+// OnCookieChange 注册 Cookie 变化回调，回调在发起请求的 goroutine 中调用
+func (c *Client) OnCookieChange(fn func(CookieChange)) {
+	c.jar.mutex.Lock()
+	defer c.jar.mutex.Unlock()
+
+	c.jar.onChange = append(c.jar.onChange, fn)
+}
+
+// trackingJar 包装标准 CookieJar，记录每个 Cookie 的最新值以便发现变化
+type trackingJar struct {
+	inner    *cookiejar.Jar
+	baseURL  *url.URL
+	mutex    sync.Mutex
+	values   map[string]string
+	onChange []func(CookieChange)
+}
+
+func newTrackingJar(serverBaseURL string) *trackingJar {
+	// 没有公共后缀列表时 Jar 仍可正常工作，只是不限制 Domain 属性
+	inner, _ := cookiejar.New(nil)
+	baseURL, err := url.Parse(serverBaseURL + "/")
+	if err != nil {
+		baseURL = &url.URL{Scheme: "https", Host: "easylearn.baidu.com", Path: "/"}
+	}
+	return &trackingJar{
+		inner:   inner,
+		baseURL: baseURL,
+		values:  map[string]string{},
+	}
+}
+
+// seed 将用户粘贴的 Cookie 请求头写入 Jar
+func (j *trackingJar) seed(cookie string) {
+	cookie = strings.TrimSpace(cookie)
+	if cookie == "" {
+		return
+	}
+	parsed, err := http.ParseCookie(cookie)
+	if err != nil {
+		// 手动粘贴的 Cookie 可能带有非法字符，逐个解析以尽量保留有效部分
+		for _, part := range strings.Split(cookie, ";") {
+			if single, err := http.ParseCookie(strings.TrimSpace(part)); err == nil {
+				parsed = append(parsed, single...)
+			}
+		}
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	for _, c := range parsed {
+		c.Path = "/"
+		j.values[c.Name] = c.Value
+	}
+	j.inner.SetCookies(j.baseURL, parsed)
+}
+
+// SetCookies 实现 http.CookieJar，并在关键 Cookie 变化时通知回调
+func (j *trackingJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	now := time.Now()
+	change := CookieChange{}
+
+	j.mutex.Lock()
+	for _, c := range cookies {
+		if c.Domain != "" {
+			// 带 Domain 的新 Cookie 会与初始的 host-only Cookie 并存，先删除旧值避免发送过期的副本
+			j.inner.SetCookies(j.baseURL, []*http.Cookie{{Name: c.Name, Path: "/", MaxAge: -1}})
+		}
+
+		expired := c.MaxAge < 0 || (!c.Expires.IsZero() && c.Expires.Before(now))
+		old, known := j.values[c.Name]
+		switch {
+		case expired && known:
+			delete(j.values, c.Name)
+			change.Expired = append(change.Expired, c.Name)
+			change.CriticalExpired = change.CriticalExpired || isCriticalCookie(c.Name)
+		case !expired && old != c.Value:
+			j.values[c.Name] = c.Value
+			change.Updated = append(change.Updated, c.Name)
+			change.CriticalUpdated = change.CriticalUpdated || isCriticalCookie(c.Name)
+		}
+	}
+	j.inner.SetCookies(u, cookies)
+	listeners := append([]func(CookieChange){}, j.onChange...)
+	j.mutex.Unlock()
+
+	if len(change.Updated) == 0 && len(change.Expired) == 0 {
+		return
+	}
+	change.Cookie = j.header()
+	for _, fn := range listeners {
+		fn(change)
+	}
+}
+
+// Cookies 实现 http.CookieJar
+func (j *trackingJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.inner.Cookies(u)
+}
+
+// header 生成当前有效的 Cookie 请求头
+func (j *trackingJar) header() string {
+	cookies := j.inner.Cookies(j.baseURL.JoinPath("edushop/"))
+	pairs := make([]string, 0, len(cookies))
+	for _, c := range cookies {
+		pairs = append(pairs, c.Name+"="+c.Value)
+	}
+	return strings.Join(pairs, "; ")
+}
+
+// isCriticalCookie 判断 Cookie 是否属于登录态必需的 Cookie
+func isCriticalCookie(name string) bool {
+	for _, critical := range criticalCookieNames {
+		if name == critical {
+			return true
+		}
+	}
+	return false
+}
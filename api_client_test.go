@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestClientFollowsServerSetCookie(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	client := NewClient(fake.URL, fakeCookie)
+
+	var mutex sync.Mutex
+	var changes []CookieChange
+	client.OnCookieChange(func(change CookieChange) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		changes = append(changes, change)
+	})
+	lastChange := func() (CookieChange, int) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if len(changes) == 0 {
+			return CookieChange{}, 0
+		}
+		return changes[len(changes)-1], len(changes)
+	}
+
+	// 服务器轮换 BDUSS 后旧值失效，之后的请求必须带上新值
+	fake.setCookie(&http.Cookie{Name: "BDUSS", Value: "rotated-bduss", Path: "/"})
+	if info, err := client.GetUserInfo(); err != nil || info.Errno != 0 {
+		t.Fatalf("轮换 Cookie 的请求失败: %+v, %v", info, err)
+	}
+	change, count := lastChange()
+	if count != 1 || !change.CriticalUpdated || change.CriticalExpired || !slices.Equal(change.Updated, []string{"BDUSS"}) {
+		t.Errorf("轮换 BDUSS 的变化 = %+v（共 %d 次）", change, count)
+	}
+	if !strings.Contains(change.Cookie, "BDUSS=rotated-bduss") || !strings.Contains(change.Cookie, "STOKEN=fake-stoken") {
+		t.Errorf("变化后的 Cookie = %q", change.Cookie)
+	}
+	if info, err := client.GetUserInfo(); err != nil || info.Errno != 0 {
+		t.Fatalf("下一个请求应携带新的 BDUSS: %+v, %v", info, err)
+	}
+	if got := client.Cookie(); got != change.Cookie {
+		t.Errorf("Cookie() = %q，期望 %q", got, change.Cookie)
+	}
+
+	// 非关键 Cookie 更新不标记为关键变化，值不变时不通知
+	fake.setCookie(&http.Cookie{Name: "BAIDUID", Value: "tracking", Path: "/"})
+	fake.setCookie(&http.Cookie{Name: "BDUSS", Value: "rotated-bduss", Path: "/"})
+	client.GetUserInfo()
+	if change, count := lastChange(); count != 2 || change.CriticalUpdated || !slices.Equal(change.Updated, []string{"BAIDUID"}) {
+		t.Errorf("非关键 Cookie 的变化 = %+v（共 %d 次）", change, count)
+	}
+
+	// 服务器删除 STOKEN
+	fake.setCookie(&http.Cookie{Name: "STOKEN", Path: "/", MaxAge: -1})
+	client.GetUserInfo()
+	change, count = lastChange()
+	if count != 3 || !change.CriticalExpired || !slices.Equal(change.Expired, []string{"STOKEN"}) {
+		t.Errorf("删除 STOKEN 的变化 = %+v（共 %d 次）", change, count)
+	}
+	if strings.Contains(change.Cookie, "STOKEN") {
+		t.Errorf("删除后的 Cookie 仍包含 STOKEN: %q", change.Cookie)
+	}
+}
@@ -2,36 +2,125 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
-	"net/url"
+	"os"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 const (
-	DefaultServerURL    = "https://easylearn.baidu.com"
-	UserAuthEndpoint    = "http://127.0.0.1:8080/llm/test"
-	PocketBaseURL       = "http://47.109.61.89:5913"
-	BackupPocketBaseURL = "https://pb.pingfury.top"
+	DefaultServerURL = "https://easylearn.baidu.com"
+
+	// 授权服务的默认地址，可通过配置文件、环境变量或命令行参数覆盖，见 Settings
+	DefaultUserAuthEndpoint    = "http://127.0.0.1:8080/llm/test"
+	DefaultPocketBaseURL       = "http://47.109.61.89:5913"
+	DefaultBackupPocketBaseURL = "https://pb.pingfury.top"
+
+	// AutoClaimEventName 是推送给前端的自动认领事件名
+	AutoClaimEventName = "autoclaim:event"
+	// CookieUpdatedEventName 是服务器刷新 Cookie 后推送给前端的事件名
+	CookieUpdatedEventName = "cookie:updated"
+	// ScheduleEventName 是定时方案启动或停止会话时推送给前端的事件名
+	ScheduleEventName = "schedule:changed"
 )
 
+// eventsEmit 向前端推送事件，测试中替换以检查推送的内容
+var eventsEmit = runtime.EventsEmit
+
 // App struct
 type App struct {
-	ctx context.Context
+	ctx           context.Context
+	settingsMutex sync.Mutex
+	settings      Settings
+	settingsErr   error // 配置文件无效时的错误，此时不注册授权方式，拒绝启动认领
+	sessions      *SessionManager
+	schedules     *ScheduleStore
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
-	return &App{}
+	// 配置文件无效时不使用默认授权地址代替，避免悄悄向用户没有配置的服务器授权
+	settings, settingsErr := LoadSettings("")
+	if settingsErr != nil {
+		log.Printf("加载配置失败，已停用授权: %v", settingsErr)
+		settings = DefaultSettings()
+	} else {
+		ApplySettings(settings)
+	}
+
+	schedules, err := DefaultScheduleStore()
+	if err != nil {
+		log.Printf("初始化定时方案存储失败: %v", err)
+	}
+
+	app := &App{settings: settings, settingsErr: settingsErr, sessions: newDefaultSessionManager(settings), schedules: schedules}
+	app.sessions.OnEvent(app.forwardClaimEvent)
+	return app
+}
+
+// newDefaultSessionManager 创建使用默认凭据存储和认领记录的会话管理器，并注册配置的 Webhook
+func newDefaultSessionManager(settings Settings) *SessionManager {
+	credentials, err := DefaultCredentialStore()
+	if err != nil {
+		log.Printf("初始化凭据存储失败: %v", err)
+	}
+	history, err := DefaultClaimHistory()
+	if err != nil {
+		log.Printf("初始化认领记录失败: %v", err)
+	}
+	sessions := NewSessionManager(credentials, history)
+	if settings.PoolSnapshotInterval > 0 {
+		archive, err := DefaultPoolArchive(time.Duration(settings.PoolSnapshotInterval)*time.Second,
+			time.Duration(settings.PoolSnapshotRetentionDays)*24*time.Hour)
+		if err != nil {
+			log.Printf("初始化线索池快照失败: %v", err)
+		}
+		sessions.SetPoolArchive(archive)
+	}
+	if len(settings.Webhooks) > 0 {
+		sessions.OnEvent(NewWebhookNotifier(settings.Webhooks).Notify)
+	}
+	return sessions
 }
 
 // startup is called when the app starts. The context is saved
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+
+	if a.schedules != nil && a.settingsErr == nil {
+		go NewScheduler(a.sessions, a.schedules, func(message string) {
+			eventsEmit(ctx, ScheduleEventName, message)
+		}).Run(ctx)
+	}
+
+	// 配置了控制接口地址时，同时允许通过 HTTP 控制当前会话
+	if a.settings.ControlAddr != "" {
+		server := NewControlServer(a.settings.ControlAddr, a.settings.ControlToken, a.sessions)
+		go func() {
+			if err := server.ListenAndServe(ctx); err != nil {
+				log.Printf("控制接口已退出: %v", err)
+			}
+		}()
+	}
+}
+
+// GetPlatformTimezone 返回解析平台时间使用的时区名，界面据此计算「今天」
+func (a *App) GetPlatformTimezone() string {
+	return PlatformLocation().String()
+}
+
+// GetSettingsError 返回加载配置文件时的错误，配置有效时返回空字符串
+func (a *App) GetSettingsError() string {
+	if a.settingsErr == nil {
+		return ""
+	}
+	return a.settingsErr.Error()
 }
 
 // Greet returns a greeting for the given name
@@ -41,13 +130,23 @@ func (a *App) Greet(name string) string {
 
 // AutoClaimResponse represents the response from starting auto claiming
 type AutoClaimResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
-	TaskID  string `json:"taskId,omitempty"`
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	TaskID  string       `json:"taskId,omitempty"`
+	Errors  ConfigErrors `json:"errors,omitempty"` // 配置无效时的字段错误
 }
 
-// Global variable to store the current auto claimer
-var currentAutoClaimer *AutoClaimer
+// newErrorResponse 将启动失败的错误转换为响应，配置错误会附带字段信息
+func newErrorResponse(err error) AutoClaimResponse {
+	response := AutoClaimResponse{Success: false, Message: err.Error()}
+	errors.As(err, &response.Errors)
+	return response
+}
+
+// ValidateAutoClaimConfig 检查认领配置，供界面在启动前提示字段错误
+func (a *App) ValidateAutoClaimConfig(config AutoClaimConfig) []FieldError {
+	return config.Validate()
+}
 
 // StartAutoClaiming starts the auto claiming process
 func (a *App) StartAutoClaiming(config AutoClaimConfig) AutoClaimResponse {
@@ -55,78 +154,195 @@ func (a *App) StartAutoClaiming(config AutoClaimConfig) AutoClaimResponse {
 	config.ServerBaseURL = DefaultServerURL
 	log.Printf("StartAutoClaiming called with config: %+v", config)
 
-	// 根据授权类型进行验证
-	switch config.AuthType {
-	case "official":
-		// 官方授权：验证用户名
-		if config.AuthUsername == "" {
-			log.Printf("官方授权验证失败: 用户名不能为空")
-			return AutoClaimResponse{
-				Success: false,
-				Message: "官方授权用户名不能为空",
-			}
-		}
-
-		err := a.validateOfficialAuth(config.AuthUsername)
-		if err != nil {
-			log.Printf("官方授权验证失败: %v", err)
-			return AutoClaimResponse{
-				Success: false,
-				Message: fmt.Sprintf("官方授权验证失败: %v", err),
-			}
-		}
-
-		log.Printf("官方授权验证成功: %s", config.AuthUsername)
-
-	case "custom":
-		// 定制授权：使用原有的LLM测试端点验证
-		userName, err := a.validateUserAndLLM(config.Cookie)
-		if err != nil {
-			log.Printf("定制授权验证失败: %v", err)
-			return AutoClaimResponse{
-				Success: false,
-				Message: fmt.Sprintf("验证失败: %v", err),
-			}
-		}
-
-		log.Printf("定制授权验证成功: %s", userName)
-
-	default:
-		log.Printf("未知的授权类型: %s", config.AuthType)
-		return AutoClaimResponse{
-			Success: false,
-			Message: "未知的授权类型",
-		}
-	}
-
 	// Start auto claiming
 	if config.Interval < 1 {
 		log.Printf("传递给StartAutoClaiming的Interval值为: %.3f秒 (%.0f毫秒)", config.Interval, config.Interval*1000)
 	} else {
 		log.Printf("传递给StartAutoClaiming的Interval值为: %.1f秒", config.Interval)
 	}
-	autoClaimer, err := StartAutoClaiming(a.ctx, config)
+
+	if a.settingsErr != nil {
+		return AutoClaimResponse{Success: false, Message: fmt.Sprintf("配置文件无效，请修正后重启: %v", a.settingsErr)}
+	}
+
+	ctx := a.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	message, err := a.sessions.Start(ctx, config)
 	if err != nil {
 		log.Printf("Error starting auto claiming: %v", err)
-		return AutoClaimResponse{
-			Success: false,
-			Message: fmt.Sprintf("启动自动认领失败: %v", err),
-		}
+		return newErrorResponse(err)
 	}
 
-	// Store the auto claimer globally for later access
-	currentAutoClaimer = autoClaimer
-
 	log.Printf("Auto claiming started successfully")
 
 	// Return success response
 	return AutoClaimResponse{
 		Success: true,
-		Message: "自动认领已启动",
-		TaskID:  "task_" + fmt.Sprintf("%d", config.ClaimLimit), // Simple task ID generation
+		Message: message,
+		TaskID:  a.sessions.SessionID(),
 	}
 }
 
+// AuthPlanResponse 表示授权套餐信息
+type AuthPlanResponse struct {
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+	UserName  string `json:"userName"`
+	ExpiresAt string `json:"expiresAt"`
+	Limit     int    `json:"limit"`
+	PlanType  string `json:"planType"`
+	Coze      bool   `json:"coze"`
+}
+
+// newAuthPlanResponse 将授权结果转换为前端使用的套餐信息
+func newAuthPlanResponse(result *AuthResult) AuthPlanResponse {
+	response := AuthPlanResponse{
+		Success:  true,
+		Message:  "获取授权信息成功",
+		UserName: result.UserName,
+		Limit:    result.Limit,
+		PlanType: result.PlanType,
+		Coze:     result.Coze,
+	}
+	if !result.ExpiresAt.IsZero() {
+		response.ExpiresAt = result.ExpiresAt.Local().Format("2006-01-02 15:04:05")
+	}
+	return response
+}
+
+// GetAuthorizationPlan 查询官方授权用户的套餐信息（过期时间、认领上限等）
+func (a *App) GetAuthorizationPlan(username string) AuthPlanResponse {
+	if a.settingsErr != nil {
+		return AuthPlanResponse{Success: false, Message: fmt.Sprintf("配置文件无效，请修正后重启: %v", a.settingsErr)}
+	}
+	authorizer, ok := LookupAuthorizer("official")
+	if !ok {
+		return AuthPlanResponse{Success: false, Message: "未配置官方授权"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	result, err := authorizer.Authorize(ctx, AutoClaimConfig{
+		ServerBaseURL: DefaultServerURL,
+		AuthType:      "official",
+		AuthUsername:  username,
+	})
+	if err != nil {
+		return AuthPlanResponse{Success: false, Message: fmt.Sprintf("获取授权信息失败: %v", err)}
+	}
+	return newAuthPlanResponse(result)
+}
+
+// GetCurrentAuthorizationPlan 返回最近一次启动自动认领时使用的授权套餐信息
+func (a *App) GetCurrentAuthorizationPlan() AuthPlanResponse {
+	authResult := a.sessions.AuthResult()
+	if authResult == nil {
+		return AuthPlanResponse{Success: false, Message: "尚未进行授权验证"}
+	}
+	return newAuthPlanResponse(authResult)
+}
+
+// forwardClaimEvent 将自动认领事件推送给前端
+func (a *App) forwardClaimEvent(event ClaimEvent) {
+	log.Printf("自动认领事件: %s %s", event.Type, event.Message)
+	if a.ctx == nil {
+		return
+	}
+	eventsEmit(a.ctx, AutoClaimEventName, event)
+	if event.Type == EventCookieUpdated {
+		eventsEmit(a.ctx, CookieUpdatedEventName, event.Cookie)
+	}
+	if notification, ok := a.GetNotificationSettings().notificationFor(event); ok {
+		go a.showNotification(notification)
+	}
+}
+
+// showNotification 显示系统通知，再推送给前端播放提示音；系统通知不可用时由前端在窗口内提示
+func (a *App) showNotification(notification DesktopNotification) {
+	if err := sendNativeNotification(notification.Title, notification.Body); err != nil {
+		log.Printf("%v，改为窗口内提示", err)
+	} else {
+		notification.Delivered = true
+	}
+	eventsEmit(a.ctx, NotifyEventName, notification)
+}
+
+// GetSchedules 返回所有定时方案
+func (a *App) GetSchedules() ([]ClaimSchedule, error) {
+	if a.schedules == nil {
+		return []ClaimSchedule{}, nil
+	}
+	return a.schedules.Load()
+}
+
+// SaveSchedule 保存定时方案，已有同名方案时替换
+func (a *App) SaveSchedule(schedule ClaimSchedule) error {
+	if a.schedules == nil {
+		return fmt.Errorf("定时方案存储不可用")
+	}
+	schedules, err := a.schedules.Load()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range schedules {
+		if schedules[i].Name == schedule.Name {
+			schedules[i] = schedule
+			replaced = true
+		}
+	}
+	if !replaced {
+		schedules = append(schedules, schedule)
+	}
+	return a.schedules.Save(schedules)
+}
+
+// DeleteSchedule 删除定时方案
+func (a *App) DeleteSchedule(name string) error {
+	if a.schedules == nil {
+		return fmt.Errorf("定时方案存储不可用")
+	}
+	schedules, err := a.schedules.Load()
+	if err != nil {
+		return err
+	}
+	kept := schedules[:0]
+	for _, schedule := range schedules {
+		if schedule.Name != name {
+			kept = append(kept, schedule)
+		}
+	}
+	return a.schedules.Save(kept)
+}
+
+// GetNotificationSettings 返回桌面通知开关
+func (a *App) GetNotificationSettings() NotificationSettings {
+	a.settingsMutex.Lock()
+	defer a.settingsMutex.Unlock()
+
+	return a.settings.Notifications
+}
+
+// SaveNotificationSettings 保存桌面通知开关到配置文件
+func (a *App) SaveNotificationSettings(notifications NotificationSettings) error {
+	a.settingsMutex.Lock()
+	defer a.settingsMutex.Unlock()
+
+	// 只修改配置文件中的 notifications，其他内容（包括默认值和未知字段）保持原样
+	if err := saveSettingsKey("", "notifications", notifications); err != nil {
+		return fmt.Errorf("保存通知设置失败: %v", err)
+	}
+	a.settings.Notifications = notifications
+	return nil
+}
+
+// GetSavedCookie 返回凭据存储中保存的 Cookie
+func (a *App) GetSavedCookie() string {
+	return a.sessions.SavedCookie()
+}
+
 // GetTaskLabels 获取任务标签数据
 func (a *App) GetTaskLabels(taskType, cookie string) (map[string]any, error) {
 	response, err := GetAuditTaskLabel(taskType, DefaultServerURL, cookie)
@@ -147,15 +363,13 @@ func (a *App) GetTaskLabels(taskType, cookie string) (map[string]any, error) {
 
 // StopAutoClaiming 停止自动认领过程
 func (a *App) StopAutoClaiming() AutoClaimResponse {
-	if currentAutoClaimer == nil {
+	if err := a.sessions.Stop(); err != nil {
 		return AutoClaimResponse{
 			Success: false,
-			Message: "没有运行的自动认领任务",
+			Message: err.Error(),
 		}
 	}
 
-	currentAutoClaimer.Stop()
-
 	return AutoClaimResponse{
 		Success: true,
 		Message: "自动认领已停止",
@@ -169,21 +383,126 @@ type AutoClaimStatusResponse struct {
 	IsActive         bool   `json:"isActive"`
 	SuccessfulClaims int    `json:"successfulClaims"`
 	LastError        string `json:"lastError"`
+	State            string `json:"state"`
+	StopReason       string `json:"stopReason"`
+	// 授权过期时间及剩余秒数，授权不过期时为空和 -1
+	LicenseExpiresAt        string `json:"licenseExpiresAt"`
+	LicenseRemainingSeconds int64  `json:"licenseRemainingSeconds"`
+	SessionID               string `json:"sessionId"`
+	// 模拟运行的筛选统计，非模拟运行时为空
+	DryRun *DryRunSummary `json:"dryRun,omitempty"`
+
+	LastResponse *ClaimResponse `json:"lastResponse,omitempty"` // 最后一次认领接口的响应
+	// 本次会话的统计（包括尝试次数和活跃任务数），没有会话时为空
+	Stats *SessionStats `json:"stats,omitempty"`
 }
 
 // GetAutoClaimStatus 获取自动认领状态
 func (a *App) GetAutoClaimStatus() AutoClaimStatusResponse {
-	if currentAutoClaimer == nil {
+	return newAutoClaimStatusResponse(a.sessions)
+}
+
+// FilterPreviewResponse 表示筛选预览的结果
+type FilterPreviewResponse struct {
+	Success bool           `json:"success"`
+	Message string         `json:"message"`
+	Errors  ConfigErrors   `json:"errors,omitempty"` // 配置无效时的字段错误
+	Preview *FilterPreview `json:"preview,omitempty"`
+}
+
+// PreviewFilter 用给定的筛选条件检查线索池前 pages 页的任务，返回每个任务是否通过及原因，不认领任务。
+// 未提供 Cookie 时使用保存的 Cookie
+func (a *App) PreviewFilter(config AutoClaimConfig, pages int) FilterPreviewResponse {
+	if errs := config.Validate(); len(errs) > 0 {
+		return FilterPreviewResponse{Success: false, Message: errs.Error(), Errors: errs}
+	}
+	if config.Cookie == "" {
+		config.Cookie = a.sessions.SavedCookie()
+	}
+	if config.Cookie == "" {
+		return FilterPreviewResponse{Success: false, Message: "请先填写 Cookie"}
+	}
+
+	preview, err := previewFilter(NewClient(DefaultServerURL, config.Cookie), config, pages, time.Now())
+	if err != nil {
+		return FilterPreviewResponse{Success: false, Message: err.Error()}
+	}
+	return FilterPreviewResponse{
+		Success: true,
+		Message: fmt.Sprintf("共 %d 个任务，通过筛选 %d 个", len(preview.Tasks), preview.Matched),
+		Preview: preview,
+	}
+}
+
+// GetPoolReport 根据最近 days 天的线索池快照生成趋势报告，days 不大于 0 时使用全部快照
+func (a *App) GetPoolReport(taskType string, days int) (*PoolReport, error) {
+	return LoadPoolReport(taskType, days, time.Now())
+}
+
+// ExportClaimHistory 将认领记录导出为 CSV 或 XLSX，由用户选择保存位置。
+// since、until 为日期（until 包含当天），account 为空时导出所有账号；用户取消时返回空路径
+func (a *App) ExportClaimHistory(format, since, until, account string) (string, error) {
+	format, err := ParseExportFormat(format, "")
+	if err != nil {
+		return "", err
+	}
+	filter, err := ExportFilter(since, until, account)
+	if err != nil {
+		return "", err
+	}
+	records, err := a.sessions.History(filter)
+	if err != nil {
+		return "", err
+	}
+	if len(records) == 0 {
+		return "", fmt.Errorf("所选范围内没有认领记录")
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出认领记录",
+		DefaultFilename: fmt.Sprintf("认领记录-%s.%s", time.Now().Format("20060102"), format),
+		Filters:         []runtime.FileFilter{{DisplayName: strings.ToUpper(format), Pattern: "*." + format}},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("创建导出文件失败: %v", err)
+	}
+	if err := ExportHistory(f, format, records); err != nil {
+		f.Close()
+		return "", fmt.Errorf("导出认领记录失败: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("导出认领记录失败: %v", err)
+	}
+	return path, nil
+}
+
+// newAutoClaimStatusResponse 生成会话状态响应，图形界面和控制接口共用
+func newAutoClaimStatusResponse(sessions *SessionManager) AutoClaimStatusResponse {
+	status, ok := sessions.Status()
+	if !ok {
 		return AutoClaimStatusResponse{
 			Success:          true,
 			Message:          "无运行任务",
 			IsActive:         false,
 			SuccessfulClaims: 0,
 			LastError:        "",
+			State:            string(SessionIdle),
+
+			LicenseRemainingSeconds: -1,
 		}
 	}
 
-	status := currentAutoClaimer.GetStatus()
+	licenseExpiresAt := ""
+	licenseRemaining := int64(-1)
+	if !status.LicenseExpiresAt.IsZero() {
+		licenseExpiresAt = status.LicenseExpiresAt.Local().Format("2006-01-02 15:04:05")
+		licenseRemaining = max(int64(time.Until(status.LicenseExpiresAt).Seconds()), 0)
+	}
 
 	return AutoClaimStatusResponse{
 		Success:          true,
@@ -191,6 +510,16 @@ func (a *App) GetAutoClaimStatus() AutoClaimStatusResponse {
 		IsActive:         status.IsActive,
 		SuccessfulClaims: status.SuccessfulClaims,
 		LastError:        status.LastError,
+		State:            string(status.State),
+		StopReason:       status.StopReason,
+
+		LicenseExpiresAt:        licenseExpiresAt,
+		LicenseRemainingSeconds: licenseRemaining,
+		SessionID:               sessions.SessionID(),
+		DryRun:                  status.DryRun,
+
+		LastResponse: status.LastResponse,
+		Stats:        &status.Stats,
 	}
 }
 
@@ -215,220 +544,7 @@ func (a *App) GetUserInfo(cookie string) (map[string]any, error) {
 	return result, nil
 }
 
-// validateUserAndLLM 验证用户信息和LLM测试端点
-func (a *App) validateUserAndLLM(cookie string) (string, error) {
-	// 获取用户信息
-	userInfo, err := GetUserInfo(DefaultServerURL, cookie)
-	if err != nil {
-		return "", fmt.Errorf("无权使用该软件，请联系管理员")
-	}
-
-	if userInfo == nil || userInfo.Errno != 0 {
-		return "", fmt.Errorf("无权使用该软件，请联系管理员")
-	}
-
-	userName := userInfo.Data.UserName
-	if userName == "" {
-		return "", fmt.Errorf("无权使用该软件，请联系管理员")
-	}
-
-	// 验证LLM测试端点
-	encodedUserName := url.QueryEscape(userName)
-
-	req, err := http.NewRequest("GET", UserAuthEndpoint, nil)
-	if err != nil {
-		return "", fmt.Errorf("创建请求失败: %v", err)
-	}
-
-	// 设置认证头部，使用URL编码后的用户名
-	req.Header.Set("Authorization", encodedUserName)
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("连接LLM测试端点失败: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("LLM测试端点返回错误: HTTP %d", resp.StatusCode)
-	}
-
-	var responseData map[string]any
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("读取响应失败: %v", err)
-	}
-
-	err = json.Unmarshal(body, &responseData)
-	if err != nil {
-		return "", fmt.Errorf("解析响应失败: %v", err)
-	}
-
-	// 检查响应格式
-	if responseData["text"] != nil {
-		text := responseData["text"].(string)
-		if text != "ok" && text != "" {
-			return "", fmt.Errorf("无权使用该软件，请联系管理员")
-		}
-	}
-
-	// 检查响应格式 {"error": "ok"}
-	if responseData["error"] != nil {
-		errorMsg := responseData["error"].(string)
-		if errorMsg != "ok" {
-			return "", fmt.Errorf("无权使用该软件，请联系管理员")
-		}
-	}
-
-	// 如果没有预期的字段，也视为失败
-	if responseData["text"] == nil && responseData["error"] == nil {
-		return "", fmt.Errorf("无权使用该软件，请联系管理员")
-	}
-
-	return userName, nil
-}
-
-// BaiduEduUserResponse 表示百度教育用户API响应
-type BaiduEduUserResponse struct {
-	ID             string    `json:"id"`
-	CollectionID   string    `json:"collectionId"`
-	CollectionName string    `json:"collectionName"`
-	CreatedRaw     string    `json:"created"`
-	UpdatedRaw     string    `json:"updated"`
-	Name           string    `json:"name"`
-	ExpTimeRaw     string    `json:"exp_time"`
-	Created        time.Time `json:"-"`
-	Updated        time.Time `json:"-"`
-	ExpTime        time.Time `json:"-"`
-	Remark         string    `json:"remark"`
-	Limit          int       `json:"limit"`
-	XufeiType      string    `json:"xufei_type"`
-	Coze           bool      `json:"coze"`
-}
-
-// validateOfficialAuth 验证官方授权用户
-func (a *App) validateOfficialAuth(username string) error {
-	if username == "" {
-		return fmt.Errorf("用户名不能为空")
-	}
-
-	// 尝试主服务器和备用服务器
-	var lastErr error
-	var userResponse BaiduEduUserResponse
-	servers := []string{PocketBaseURL, BackupPocketBaseURL}
-	var success bool
-
-	for i, serverURL := range servers {
-		// 构建API URL
-		apiURL := fmt.Sprintf("%s/api/collections/baidu_edu_users/records/%s", serverURL, username)
-
-		// 创建HTTP请求
-		req, err := http.NewRequest("GET", apiURL, nil)
-		if err != nil {
-			lastErr = fmt.Errorf("创建请求失败: %v", err)
-			continue
-		}
-
-		// 设置请求头
-		req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-
-		// 发送请求，设置较短的超时时间
-		client := &http.Client{Timeout: 5 * time.Second}
-		resp, err := client.Do(req)
-		if err != nil {
-			lastErr = fmt.Errorf("请求用户信息失败: %v", err)
-			if i < len(servers)-1 {
-				log.Printf("主服务器连接失败，尝试备用服务器: %v", err)
-				continue
-			}
-			return lastErr
-		}
-		defer resp.Body.Close()
-
-		// 检查HTTP状态码
-		if resp.StatusCode == http.StatusNotFound {
-			return fmt.Errorf("用户不存在或无权使用")
-		}
-		if resp.StatusCode != http.StatusOK {
-			lastErr = fmt.Errorf("API请求失败: HTTP %d", resp.StatusCode)
-			if i < len(servers)-1 {
-				log.Printf("主服务器返回错误，尝试备用服务器: HTTP %d", resp.StatusCode)
-				continue
-			}
-			return lastErr
-		}
-
-		// 读取响应体
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			lastErr = fmt.Errorf("读取响应失败: %v", err)
-			if i < len(servers)-1 {
-				log.Printf("读取响应失败，尝试备用服务器: %v", err)
-				continue
-			}
-			return lastErr
-		}
-
-		// 解析JSON响应
-		err = json.Unmarshal(body, &userResponse)
-		if err != nil {
-			lastErr = fmt.Errorf("解析响应失败: %v", err)
-			if i < len(servers)-1 {
-				log.Printf("解析响应失败，尝试备用服务器: %v", err)
-				continue
-			}
-			return lastErr
-		}
-
-		// 成功获取响应，跳出循环
-		log.Printf("成功连接到服务器: %s", serverURL)
-		success = true
-		break
-	}
-
-	// 如果所有服务器都尝试失败
-	if !success {
-		return lastErr
-	}
-
-	// PocketBase时间解析函数
-	parsePocketBaseTime := func(timeStr string) (time.Time, error) {
-		// PocketBase返回的格式: "2025-01-07 11:06:37.080Z"
-		return time.Parse("2006-01-02 15:04:05.000Z", timeStr)
-	}
-
-	// 解析过期时间
-	expTime, err := parsePocketBaseTime(userResponse.ExpTimeRaw)
-	if err != nil {
-		return fmt.Errorf("解析过期时间失败: %v", err)
-	}
-	userResponse.ExpTime = expTime
-
-	// 同时解析其他时间字段用于日志记录
-	if userResponse.CreatedRaw != "" {
-		if createdTime, err := parsePocketBaseTime(userResponse.CreatedRaw); err == nil {
-			userResponse.Created = createdTime
-		}
-	}
-	if userResponse.UpdatedRaw != "" {
-		if updatedTime, err := parsePocketBaseTime(userResponse.UpdatedRaw); err == nil {
-			userResponse.Updated = updatedTime
-		}
-	}
-
-	// 检查过期时间
-	now := time.Now()
-	if now.After(userResponse.ExpTime) {
-		return fmt.Errorf("授权已过期，过期时间: %s", userResponse.ExpTime.Format("2006-01-02 15:04:05"))
-	}
-
-	// 检查是否在有效期前1小时内（可选警告）
-	if now.Add(time.Hour).After(userResponse.ExpTime) {
-		log.Printf("警告：用户授权即将过期，过期时间: %s", userResponse.ExpTime.Format("2006-01-02 15:04:05"))
-	}
-
-	log.Printf("官方授权验证成功: 用户 %s, 过期时间: %s", userResponse.Name, userResponse.ExpTime.Format("2006-01-02 15:04:05"))
-	return nil
+// ImportCookies 解析浏览器导出的 Cookie 文件内容（cookies.txt、HAR 或 JSON）
+func (a *App) ImportCookies(content string) (*CookieImportResult, error) {
+	return ParseCookieExport([]byte(content), CookieImportURL, time.Now())
 }
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// authCacheEntry 是缓存文件中的一条授权记录，Signature 防止手工修改延长授权
+type authCacheEntry struct {
+	Result      AuthResult `json:"result"`
+	ValidatedAt time.Time  `json:"validatedAt"`
+	Signature   string     `json:"signature"`
+}
+
+// AuthCache 将最近一次成功的授权结果保存在本地，TTL 内重启会话无需访问授权服务器
+type AuthCache struct {
+	path    string
+	keyPath string
+	ttl     time.Duration
+	mutex   sync.Mutex
+	secret  []byte
+	now     func() time.Time
+}
+
+// NewAuthCache 创建保存在 dir 目录下的授权缓存
+func NewAuthCache(dir string, ttl time.Duration) *AuthCache {
+	return &AuthCache{
+		path:    filepath.Join(dir, "auth-cache.json"),
+		keyPath: filepath.Join(dir, "auth-cache.key"),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// Get 返回仍在 TTL 内、签名有效且授权未过期的缓存结果
+func (c *AuthCache) Get(key string) (*AuthResult, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entries, err := c.load()
+	if err != nil {
+		log.Printf("读取授权缓存失败: %v", err)
+		return nil, false
+	}
+	entry, ok := entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	now := c.now()
+	if now.Sub(entry.ValidatedAt) > c.ttl || now.Before(entry.ValidatedAt) {
+		return nil, false
+	}
+	if !entry.Result.ExpiresAt.IsZero() && !now.Before(entry.Result.ExpiresAt) {
+		return nil, false
+	}
+	expected, err := c.sign(key, entry)
+	if err != nil || !hmac.Equal([]byte(expected), []byte(entry.Signature)) {
+		return nil, false
+	}
+
+	result := entry.Result
+	return &result, true
+}
+
+// Put 保存授权结果
+func (c *AuthCache) Put(key string, result *AuthResult) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entries, err := c.load()
+	if err != nil {
+		entries = map[string]authCacheEntry{}
+	}
+
+	entry := authCacheEntry{Result: *result, ValidatedAt: c.now()}
+	if entry.Signature, err = c.sign(key, entry); err != nil {
+		return err
+	}
+	entries[key] = entry
+	return c.save(entries)
+}
+
+// Delete 删除缓存的授权结果
+func (c *AuthCache) Delete(key string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entries, err := c.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[key]; !ok {
+		return nil
+	}
+	delete(entries, key)
+	return c.save(entries)
+}
+
+// load 读取缓存文件，文件不存在时返回空缓存
+func (c *AuthCache) load() (map[string]authCacheEntry, error) {
+	entries := map[string]authCacheEntry{}
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// save 写入缓存文件
+func (c *AuthCache) save(entries map[string]authCacheEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0o600)
+}
+
+// sign 使用本机密钥计算缓存记录的 HMAC 签名
+func (c *AuthCache) sign(key string, entry authCacheEntry) (string, error) {
+	secret, err := c.secretKey()
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(struct {
+		Key         string     `json:"key"`
+		Result      AuthResult `json:"result"`
+		ValidatedAt int64      `json:"validatedAt"`
+	}{key, entry.Result, entry.ValidatedAt.UnixNano()})
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// secretKey 读取本机签名密钥，不存在时生成一个新的随机密钥
+func (c *AuthCache) secretKey() ([]byte, error) {
+	if c.secret != nil {
+		return c.secret, nil
+	}
+
+	data, err := os.ReadFile(c.keyPath)
+	if err == nil && len(data) == 64 {
+		if secret, err := hex.DecodeString(string(data)); err == nil {
+			c.secret = secret
+			return secret, nil
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.keyPath), 0o700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(c.keyPath, []byte(hex.EncodeToString(secret)), 0o600); err != nil {
+		return nil, fmt.Errorf("保存授权缓存密钥失败: %v", err)
+	}
+	c.secret = secret
+	return secret, nil
+}
+
+// CachedAuthorizer 包装 Authorizer，TTL 内直接使用缓存的授权结果
+type CachedAuthorizer struct {
+	Name  string
+	Scope string // 授权方式使用的服务器等配置，变化后不再使用之前的缓存
+	Inner Authorizer
+	Cache *AuthCache
+}
+
+// Authorize 优先使用缓存，缓存失效时访问授权服务器并更新缓存
+func (c *CachedAuthorizer) Authorize(ctx context.Context, config AutoClaimConfig) (*AuthResult, error) {
+	key := authCacheKey(c.Name, c.Scope, config)
+	if result, ok := c.Cache.Get(key); ok {
+		log.Printf("使用缓存的授权结果 (%s): %s", c.Name, result.UserName)
+		return result, nil
+	}
+
+	return c.Refresh(ctx, config)
+}
+
+// Refresh 跳过缓存直接访问授权服务器，并用结果更新缓存
+func (c *CachedAuthorizer) Refresh(ctx context.Context, config AutoClaimConfig) (*AuthResult, error) {
+	key := authCacheKey(c.Name, c.Scope, config)
+	result, err := c.Inner.Authorize(ctx, config)
+	if err != nil {
+		if IsAuthDenied(err) {
+			c.Cache.Delete(key)
+		}
+		return nil, err
+	}
+	if err := c.Cache.Put(key, result); err != nil {
+		log.Printf("保存授权缓存失败: %v", err)
+	}
+	return result, nil
+}
+
+// refreshAuthorize 重新验证授权，带缓存的授权方式会绕过缓存
+func refreshAuthorize(ctx context.Context, authorizer Authorizer, config AutoClaimConfig) (*AuthResult, error) {
+	if cached, ok := authorizer.(*CachedAuthorizer); ok {
+		return cached.Refresh(ctx, config)
+	}
+	return authorizer.Authorize(ctx, config)
+}
+
+// authCacheKey 生成缓存键，官方授权按用户名区分，其他授权方式按 Cookie 区分；
+// scope 是授权服务器等配置，切换服务器后不会命中之前服务器的结果
+func authCacheKey(name, scope string, config AutoClaimConfig) string {
+	identity := config.AuthUsername
+	if name != "official" {
+		identity = config.Cookie
+	}
+	sum := sha256.Sum256([]byte(name + "\x00" + scope + "\x00" + config.ServerBaseURL + "\x00" + identity))
+	return name + ":" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+// newTestAuthCache 创建使用可控时间的授权缓存
+func newTestAuthCache(t *testing.T, ttl time.Duration) (*AuthCache, *time.Time) {
+	t.Helper()
+
+	now := time.Date(2025, 1, 7, 9, 0, 0, 0, time.UTC)
+	cache := NewAuthCache(t.TempDir(), ttl)
+	cache.now = func() time.Time { return now }
+	return cache, &now
+}
+
+func TestAuthCacheTTL(t *testing.T) {
+	cache, now := newTestAuthCache(t, 10*time.Minute)
+	if err := cache.Put("official:a", &AuthResult{UserName: "alice", Limit: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	*now = now.Add(10 * time.Minute)
+	if result, ok := cache.Get("official:a"); !ok || result.UserName != "alice" || result.Limit != 5 {
+		t.Fatalf("TTL 内应命中缓存，得到 %+v, %v", result, ok)
+	}
+	if _, ok := cache.Get("official:b"); ok {
+		t.Error("其他键不应命中")
+	}
+
+	*now = now.Add(time.Second)
+	if _, ok := cache.Get("official:a"); ok {
+		t.Error("超过 TTL 后不应命中")
+	}
+}
+
+func TestAuthCacheRejectsTamperedEntry(t *testing.T) {
+	cache, _ := newTestAuthCache(t, time.Hour)
+	if err := cache.Put("official:a", &AuthResult{UserName: "alice", Limit: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 手工修改上限后签名不再匹配
+	data, err := os.ReadFile(cache.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries map[string]authCacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatal(err)
+	}
+	entry := entries["official:a"]
+	entry.Result.Limit = 500
+	entries["official:a"] = entry
+	if data, err = json.Marshal(entries); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cache.path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if result, ok := cache.Get("official:a"); ok {
+		t.Errorf("被修改的缓存不应命中，得到 %+v", result)
+	}
+}
+
+func TestAuthCacheSkipsExpiredLicense(t *testing.T) {
+	cache, now := newTestAuthCache(t, time.Hour)
+	if err := cache.Put("official:a", &AuthResult{UserName: "alice", ExpiresAt: now.Add(30 * time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get("official:a"); !ok {
+		t.Fatal("授权未过期时应命中")
+	}
+
+	*now = now.Add(30 * time.Minute)
+	if _, ok := cache.Get("official:a"); ok {
+		t.Error("授权过期后即使仍在 TTL 内也不应命中")
+	}
+}
+
+func TestCachedAuthorizer(t *testing.T) {
+	cache, _ := newTestAuthCache(t, time.Hour)
+	inner := &stubAuthorizer{}
+	authorizer := &CachedAuthorizer{Name: "official", Scope: "http://pb-a", Inner: inner, Cache: cache}
+	config := AutoClaimConfig{AuthUsername: "alice"}
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := authorizer.Authorize(ctx, config); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls := inner.callCount(); calls != 1 {
+		t.Errorf("第二次授权应使用缓存，实际访问授权服务 %d 次", calls)
+	}
+
+	// 切换授权服务器后不使用之前服务器的结果
+	other := &CachedAuthorizer{Name: "official", Scope: "http://pb-b", Inner: inner, Cache: cache}
+	if _, err := other.Authorize(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	if calls := inner.callCount(); calls != 2 {
+		t.Errorf("切换服务器后应重新授权，实际访问授权服务 %d 次", calls)
+	}
+
+	// 复核时被拒绝，删除缓存，之后的授权不再使用旧结果
+	inner.fail(authDenied("授权已过期"))
+	if _, err := authorizer.Refresh(ctx, config); !IsAuthDenied(err) {
+		t.Fatalf("Refresh 应返回拒绝，得到 %v", err)
+	}
+	if _, ok := cache.Get(authCacheKey("official", "http://pb-a", config)); ok {
+		t.Error("授权被拒绝后缓存应被删除")
+	}
+	if _, err := authorizer.Authorize(ctx, config); !IsAuthDenied(err) {
+		t.Errorf("缓存删除后应重新授权并被拒绝，得到 %v", err)
+	}
+
+	// 网络错误不删除缓存
+	if _, ok := cache.Get(authCacheKey("official", "http://pb-b", config)); !ok {
+		t.Fatal("其他服务器的缓存不应受影响")
+	}
+	inner.fail(context.DeadlineExceeded)
+	other.Refresh(ctx, config)
+	if _, ok := cache.Get(authCacheKey("official", "http://pb-b", config)); !ok {
+		t.Error("网络错误时不应删除缓存")
+	}
+}
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuthResult 表示授权验证的结果
+type AuthResult struct {
+	UserName  string    `json:"userName"`  // 授权对应的用户名
+	ExpiresAt time.Time `json:"expiresAt"` // 授权过期时间，零值表示不过期
+	Limit     int       `json:"limit"`     // 授权允许的认领上限，0 表示不限制
+	PlanType  string    `json:"planType"`  // 续费类型
+	Coze      bool      `json:"coze"`      // 是否开通 Coze 功能
+}
+
+// ApplyLimits 按授权上限收紧认领配置，返回做出的调整说明
+func (r *AuthResult) ApplyLimits(config *AutoClaimConfig) []string {
+	if r == nil || r.Limit <= 0 {
+		return nil
+	}
+
+	var adjustments []string
+	if config.ClaimLimit <= 0 || config.ClaimLimit > r.Limit {
+		adjustments = append(adjustments, fmt.Sprintf("认领上限已按授权限制调整为 %d", r.Limit))
+		config.ClaimLimit = r.Limit
+	}
+	if config.ConcurrentClaims <= 0 || config.ConcurrentClaims > r.Limit {
+		adjustments = append(adjustments, fmt.Sprintf("并发数已按授权限制调整为 %d", r.Limit))
+		config.ConcurrentClaims = r.Limit
+	}
+	return adjustments
+}
+
+// AuthDeniedError 表示授权服务明确拒绝了授权（用户不存在、无权使用或已过期），
+// 用于与网络错误等暂时性失败区分
+type AuthDeniedError struct {
+	Reason string
+}
+
+func (e *AuthDeniedError) Error() string {
+	return e.Reason
+}
+
+// authDenied 创建 AuthDeniedError
+func authDenied(format string, args ...any) error {
+	return &AuthDeniedError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// IsAuthDenied 判断错误是否表示授权被明确拒绝
+func IsAuthDenied(err error) bool {
+	var denied *AuthDeniedError
+	return errors.As(err, &denied)
+}
+
+// Authorizer 在启动自动认领前校验用户是否有权使用软件
+type Authorizer interface {
+	Authorize(ctx context.Context, config AutoClaimConfig) (*AuthResult, error)
+}
+
+var (
+	authorizersMutex sync.RWMutex
+	authorizers      = map[string]Authorizer{}
+)
+
+// RegisterAuthorizer 按名称注册授权方式，AutoClaimConfig.AuthType 使用该名称选择授权方式，
+// 同名注册会覆盖之前的实现
+func RegisterAuthorizer(name string, authorizer Authorizer) {
+	authorizersMutex.Lock()
+	defer authorizersMutex.Unlock()
+
+	authorizers[name] = authorizer
+}
+
+// LookupAuthorizer 返回指定名称的授权方式
+func LookupAuthorizer(name string) (Authorizer, bool) {
+	authorizersMutex.RLock()
+	defer authorizersMutex.RUnlock()
+
+	authorizer, ok := authorizers[name]
+	return authorizer, ok
+}
+
+// AuthorizerNames 返回所有已注册的授权方式名称
+func AuthorizerNames() []string {
+	authorizersMutex.RLock()
+	defer authorizersMutex.RUnlock()
+
+	names := make([]string, 0, len(authorizers))
+	for name := range authorizers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	ApplySettings(DefaultSettings())
+}
+
+// cookieUserName 通过 Cookie 获取百度教育平台的用户名，ctx 结束时取消请求
+func cookieUserName(ctx context.Context, config AutoClaimConfig) (string, error) {
+	userInfo, err := NewClient(config.ServerBaseURL, config.Cookie).GetUserInfoContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("无权使用该软件，请联系管理员")
+	}
+
+	if userInfo == nil || userInfo.Errno != 0 {
+		return "", fmt.Errorf("无权使用该软件，请联系管理员")
+	}
+
+	userName := userInfo.Data.UserName
+	if userName == "" {
+		return "", fmt.Errorf("无权使用该软件，请联系管理员")
+	}
+	return userName, nil
+}
+
+// AllowlistAuthorizer 使用本地白名单文件授权，文件每行一个百度教育用户名（不区分大小写），# 开头为注释
+type AllowlistAuthorizer struct {
+	Path string // 白名单文件路径
+}
+
+// Authorize 检查 Cookie 对应的用户是否在白名单中
+func (w *AllowlistAuthorizer) Authorize(ctx context.Context, config AutoClaimConfig) (*AuthResult, error) {
+	path := w.Path
+	if path == "" {
+		return nil, fmt.Errorf("未配置授权白名单文件")
+	}
+
+	userName, err := cookieUserName(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取授权白名单失败: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.EqualFold(line, userName) {
+			return &AuthResult{UserName: userName}, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取授权白名单失败: %v", err)
+	}
+	return nil, authDenied("无权使用该软件，请联系管理员")
+}
+
+// LLMEndpointAuthorizer 是定制授权：用 Cookie 对应的用户名请求 LLM 测试端点
+type LLMEndpointAuthorizer struct {
+	Endpoint string // LLM 测试端点地址
+}
+
+// Authorize 验证用户信息和LLM测试端点
+func (l *LLMEndpointAuthorizer) Authorize(ctx context.Context, config AutoClaimConfig) (*AuthResult, error) {
+	// 获取用户信息
+	userName, err := cookieUserName(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// 验证LLM测试端点
+	encodedUserName := url.QueryEscape(userName)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", l.Endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %v", err)
+	}
+
+	// 设置认证头部，使用URL编码后的用户名
+	req.Header.Set("Authorization", encodedUserName)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("连接LLM测试端点失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("LLM测试端点返回错误: HTTP %d", resp.StatusCode)
+	}
+
+	var responseData map[string]any
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %v", err)
+	}
+
+	err = json.Unmarshal(body, &responseData)
+	if err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+
+	// 检查响应格式，字段类型不对时视为端点异常而不是拒绝授权
+	if responseData["text"] != nil {
+		text, ok := responseData["text"].(string)
+		if !ok {
+			return nil, fmt.Errorf("LLM测试端点返回了无法识别的响应: text 不是字符串")
+		}
+		if text != "ok" && text != "" {
+			return nil, authDenied("无权使用该软件，请联系管理员")
+		}
+	}
+
+	// 检查响应格式 {"error": "ok"}
+	if responseData["error"] != nil {
+		errorMsg, ok := responseData["error"].(string)
+		if !ok {
+			return nil, fmt.Errorf("LLM测试端点返回了无法识别的响应: error 不是字符串")
+		}
+		if errorMsg != "ok" {
+			return nil, authDenied("无权使用该软件，请联系管理员")
+		}
+	}
+
+	// 如果没有预期的字段，也视为失败
+	if responseData["text"] == nil && responseData["error"] == nil {
+		return nil, authDenied("无权使用该软件，请联系管理员")
+	}
+
+	return &AuthResult{UserName: userName}, nil
+}
+
+// BaiduEduUserResponse 表示百度教育用户API响应
+type BaiduEduUserResponse struct {
+	ID             string    `json:"id"`
+	CollectionID   string    `json:"collectionId"`
+	CollectionName string    `json:"collectionName"`
+	CreatedRaw     string    `json:"created"`
+	UpdatedRaw     string    `json:"updated"`
+	Name           string    `json:"name"`
+	ExpTimeRaw     string    `json:"exp_time"`
+	Created        time.Time `json:"-"`
+	Updated        time.Time `json:"-"`
+	ExpTime        time.Time `json:"-"`
+	Remark         string    `json:"remark"`
+	Limit          int       `json:"limit"`
+	XufeiType      string    `json:"xufei_type"`
+	Coze           bool      `json:"coze"`
+}
+
+// PocketBaseAuthorizer 是官方授权：在 PocketBase 的 baidu_edu_users 集合中查询授权用户
+type PocketBaseAuthorizer struct {
+	Servers []string // 按顺序尝试的 PocketBase 服务器
+}
+
+// Authorize 验证官方授权用户
+func (p *PocketBaseAuthorizer) Authorize(ctx context.Context, config AutoClaimConfig) (*AuthResult, error) {
+	username := config.AuthUsername
+	if username == "" {
+		return nil, fmt.Errorf("官方授权用户名不能为空")
+	}
+
+	// 按顺序尝试配置的服务器，前一个不可用时回退到下一个
+	var lastErr error
+	var userResponse BaiduEduUserResponse
+	servers := p.Servers
+	var success bool
+
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("未配置官方授权服务器")
+	}
+
+	for i, serverURL := range servers {
+		// 构建API URL
+		apiURL := fmt.Sprintf("%s/api/collections/baidu_edu_users/records/%s", serverURL, username)
+
+		// 创建HTTP请求
+		req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+		if err != nil {
+			lastErr = fmt.Errorf("创建请求失败: %v", err)
+			continue
+		}
+
+		// 设置请求头
+		req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+
+		// 发送请求，设置较短的超时时间
+		client := &http.Client{Timeout: 5 * time.Second}
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("请求用户信息失败: %v", err)
+			if i < len(servers)-1 {
+				log.Printf("服务器 %s 连接失败，尝试下一个服务器: %v", serverURL, err)
+				continue
+			}
+			return nil, lastErr
+		}
+		defer resp.Body.Close()
+
+		// 检查HTTP状态码
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, authDenied("用户不存在或无权使用")
+		}
+		if resp.StatusCode != http.StatusOK {
+			lastErr = fmt.Errorf("API请求失败: HTTP %d", resp.StatusCode)
+			if i < len(servers)-1 {
+				log.Printf("服务器 %s 返回错误，尝试下一个服务器: HTTP %d", serverURL, resp.StatusCode)
+				continue
+			}
+			return nil, lastErr
+		}
+
+		// 读取响应体
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			lastErr = fmt.Errorf("读取响应失败: %v", err)
+			if i < len(servers)-1 {
+				log.Printf("读取服务器 %s 响应失败，尝试下一个服务器: %v", serverURL, err)
+				continue
+			}
+			return nil, lastErr
+		}
+
+		// 解析JSON响应
+		err = json.Unmarshal(body, &userResponse)
+		if err != nil {
+			lastErr = fmt.Errorf("解析响应失败: %v", err)
+			if i < len(servers)-1 {
+				log.Printf("解析服务器 %s 响应失败，尝试下一个服务器: %v", serverURL, err)
+				continue
+			}
+			return nil, lastErr
+		}
+
+		// 成功获取响应，跳出循环
+		log.Printf("成功连接到服务器: %s", serverURL)
+		success = true
+		break
+	}
+
+	// 如果所有服务器都尝试失败
+	if !success {
+		return nil, lastErr
+	}
+
+	// 解析过期时间
+	expTime, err := parsePocketBaseTime(userResponse.ExpTimeRaw)
+	if err != nil {
+		return nil, fmt.Errorf("解析过期时间失败: %v", err)
+	}
+	userResponse.ExpTime = expTime
+
+	// 同时解析其他时间字段用于日志记录
+	if userResponse.CreatedRaw != "" {
+		if createdTime, err := parsePocketBaseTime(userResponse.CreatedRaw); err == nil {
+			userResponse.Created = createdTime
+		}
+	}
+	if userResponse.UpdatedRaw != "" {
+		if updatedTime, err := parsePocketBaseTime(userResponse.UpdatedRaw); err == nil {
+			userResponse.Updated = updatedTime
+		}
+	}
+
+	// 检查过期时间
+	now := time.Now()
+	if now.After(userResponse.ExpTime) {
+		return nil, authDenied("授权已过期，过期时间: %s", userResponse.ExpTime.Local().Format("2006-01-02 15:04:05"))
+	}
+
+	// 检查是否在有效期前1小时内（可选警告）
+	if now.Add(time.Hour).After(userResponse.ExpTime) {
+		log.Printf("警告：用户授权即将过期，过期时间: %s", userResponse.ExpTime.Local().Format("2006-01-02 15:04:05"))
+	}
+
+	log.Printf("官方授权验证成功: 用户 %s, 过期时间: %s", userResponse.Name, userResponse.ExpTime.Local().Format("2006-01-02 15:04:05"))
+	return &AuthResult{
+		UserName:  userResponse.Name,
+		ExpiresAt: userResponse.ExpTime,
+		Limit:     userResponse.Limit,
+		PlanType:  userResponse.XufeiType,
+		Coze:      userResponse.Coze,
+	}, nil
+}
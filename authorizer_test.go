@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLLMEndpointAuthorizerResponses(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	tests := []struct {
+		body   string
+		ok     bool
+		denied bool
+	}{
+		{`{"text": "ok"}`, true, false},
+		{`{"text": ""}`, true, false},
+		{`{"error": "ok"}`, true, false},
+		{`{"text": "no"}`, false, true},
+		{`{"error": "forbidden"}`, false, true},
+		{`{}`, false, true},
+		{`{"text": 123}`, false, false},
+		{`{"error": {"code": 1}}`, false, false},
+		{`{"text": "ok", "error": false}`, false, false},
+	}
+	for _, tt := range tests {
+		endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(tt.body))
+		}))
+		authorizer := &LLMEndpointAuthorizer{Endpoint: endpoint.URL}
+		result, err := authorizer.Authorize(context.Background(), AutoClaimConfig{ServerBaseURL: fake.URL, Cookie: fakeCookie})
+		endpoint.Close()
+
+		if tt.ok {
+			if err != nil || result == nil || result.UserName == "" {
+				t.Errorf("%s: 应授权成功，得到 %+v, %v", tt.body, result, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: 应返回错误", tt.body)
+			continue
+		}
+		if IsAuthDenied(err) != tt.denied {
+			t.Errorf("%s: IsAuthDenied = %v，期望 %v（%v）", tt.body, IsAuthDenied(err), tt.denied, err)
+		}
+	}
+}
+
+func TestLookupAuthorizer(t *testing.T) {
+	RegisterAuthorizer(t.Name(), &stubAuthorizer{})
+	if _, ok := LookupAuthorizer(t.Name()); !ok {
+		t.Error("已注册的授权方式应能找到")
+	}
+	if authorizer, ok := LookupAuthorizer("no-such-authorizer"); ok || authorizer != nil {
+		t.Errorf("未注册的名称 = %v, %v", authorizer, ok)
+	}
+
+	// 会话使用未知的授权类型时拒绝启动，不会访问平台
+	fake := newFakeEasylearn(t)
+	sessions := NewSessionManager(nil, nil)
+	config := AutoClaimConfig{TaskType: "audittask", Interval: 60, Cookie: fakeCookie, ServerBaseURL: fake.URL, AuthType: "no-such-authorizer"}
+	if _, err := sessions.Start(context.Background(), config); err == nil {
+		sessions.Stop()
+		t.Fatal("未知的授权类型应返回错误")
+	}
+	if list, claim := fake.calls(); list != 0 || claim != 0 {
+		t.Errorf("未知的授权类型访问了平台: 列表 %d 次，认领 %d 次", list, claim)
+	}
+}
+
+func TestAllowlistAuthorizer(t *testing.T) {
+	fake := newFakeEasylearn(t) // 用户名为 fake-user
+	path := filepath.Join(t.TempDir(), "allowlist.txt")
+	config := AutoClaimConfig{ServerBaseURL: fake.URL, Cookie: fakeCookie}
+
+	tests := []struct {
+		name   string
+		lines  string
+		ok     bool
+		denied bool
+	}{
+		{"精确匹配", "alice\nfake-user\n", true, false},
+		{"不区分大小写", "  FAKE-User  \n", true, false},
+		{"忽略注释和空行", "# fake-user\n\nalice\n", false, true},
+		{"不在白名单中", "fake-user-2\nfake\n", false, true},
+		{"空白名单", "", false, true},
+	}
+	for _, tt := range tests {
+		if err := os.WriteFile(path, []byte(tt.lines), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		result, err := (&AllowlistAuthorizer{Path: path}).Authorize(context.Background(), config)
+		if tt.ok {
+			if err != nil || result.UserName != "fake-user" {
+				t.Errorf("%s: 应允许，得到 %+v, %v", tt.name, result, err)
+			}
+			continue
+		}
+		if err == nil || IsAuthDenied(err) != tt.denied {
+			t.Errorf("%s: 应拒绝，得到 %+v, %v", tt.name, result, err)
+		}
+	}
+
+	// 配置问题不是授权拒绝，不会删除授权缓存
+	for name, authorizer := range map[string]*AllowlistAuthorizer{
+		"未配置文件": {},
+		"文件不存在": {Path: filepath.Join(t.TempDir(), "missing.txt")},
+	} {
+		if _, err := authorizer.Authorize(context.Background(), config); err == nil || IsAuthDenied(err) {
+			t.Errorf("%s: 应返回非拒绝的错误，得到 %v", name, err)
+		}
+	}
+
+	fake.logout()
+	if _, err := (&AllowlistAuthorizer{Path: path}).Authorize(context.Background(), config); err == nil {
+		t.Error("Cookie 失效时应返回错误")
+	}
+}
+
+func TestCookieUserNameHonorsContext(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	fake.hold(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := (&AllowlistAuthorizer{Path: "allowlist.txt"}).Authorize(ctx, AutoClaimConfig{ServerBaseURL: fake.URL, Cookie: fakeCookie})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("请求被取消时应返回错误")
+		}
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			t.Errorf("应在 ctx 超时后返回，ctx.Err() = %v", ctx.Err())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ctx 超时后获取用户信息的请求没有取消")
+	}
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock 是手动推进的时钟，只有调用 Advance 时定时器才会触发
+type fakeClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock   *fakeClock
+	ch      chan time.Time
+	when    time.Time
+	period  time.Duration // 0 表示只触发一次
+	stopped bool
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) Timer { return c.add(d, d) }
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer { return c.add(d, 0) }
+
+func (c *fakeClock) add(d, period time.Duration) *fakeTimer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	t := &fakeTimer{clock: c, ch: make(chan time.Time, 1), when: c.now.Add(d), period: period}
+	if period == 0 && d <= 0 {
+		t.ch <- c.now
+		t.stopped = true
+	}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance 将时间推进 d，按时间顺序触发到期的定时器。
+// 与 time.Ticker 一样，接收方来不及读取时多余的触发会被丢弃
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	end := c.now.Add(d)
+	for {
+		var next *fakeTimer
+		for _, t := range c.timers {
+			if !t.stopped && !t.when.After(end) && (next == nil || t.when.Before(next.when)) {
+				next = t
+			}
+		}
+		if next == nil {
+			break
+		}
+		c.now = next.when
+		select {
+		case next.ch <- c.now:
+		default:
+		}
+		if next.period > 0 {
+			next.when = next.when.Add(next.period)
+		} else {
+			next.stopped = true
+		}
+	}
+	c.now = end
+}
+
+// activeTimers 返回尚未停止的定时器数量，用于等待会话的循环创建好定时器
+func (c *fakeClock) activeTimers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	count := 0
+	for _, t := range c.timers {
+		if !t.stopped {
+			count++
+		}
+	}
+	return count
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.ch }
+
+func (t *fakeTimer) Stop() {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+	t.stopped = true
+}
+
+// sequenceRand 依次返回预设的随机数，用完后返回 0
+type sequenceRand struct {
+	mutex  sync.Mutex
+	values []int
+}
+
+func (r *sequenceRand) Intn(n int) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.values) == 0 {
+		return 0
+	}
+	v := r.values[0]
+	r.values = r.values[1:]
+	return v % n
+}
+
+// waitForAttempt 等待第 n 次认领尝试开始并结束
+func waitForAttempt(t *testing.T, claimer *AutoClaimer, n int) {
+	t.Helper()
+
+	waitFor(t, "认领尝试结束", func() bool {
+		status := claimer.GetStatus()
+		return status.AttemptCount >= n && status.ActiveTasks == 0
+	})
+}
+
+func TestAutoClaimerFollowsClockAndRandomPages(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	fake.addTasks("audittask",
+		TaskItem{TaskID: 1, Brief: "数学 函数"},
+		TaskItem{TaskID: 2, Brief: "英语 语法"},
+	)
+	start := mustPlatformTime(t, "2025-01-07 09:00:00").Time
+	clock := newFakeClock(start)
+
+	claimer, events := startTestClaimer(t, fake, AutoClaimConfig{
+		TaskType:         "audittask",
+		Interval:         1,
+		MaxPages:         3,
+		ClaimLimit:       2,
+		ConcurrentClaims: 1,
+		IncludeKeywords:  []string{"数学"},
+	}, WithClock(clock), WithRand(&sequenceRand{values: []int{1, 0, 2, 0}}))
+
+	// 启动时立即尝试一次，之后每次推进一个间隔尝试一次
+	waitForAttempt(t, claimer, 1)
+	waitFor(t, "创建定时器", func() bool { return clock.activeTimers() == 2 })
+
+	clock.Advance(time.Second)
+	claimed := waitForEvent(t, events, EventTaskClaimed)
+	if want := start.Add(time.Second); !claimed.Time.Equal(want) {
+		t.Errorf("事件时间 = %s，期望 %s", claimed.Time, want)
+	}
+	waitForAttempt(t, claimer, 2)
+
+	fake.addTasks("audittask", TaskItem{TaskID: 5, Brief: "数学 几何"})
+	clock.Advance(time.Second)
+	waitForAttempt(t, claimer, 3)
+	clock.Advance(time.Second)
+
+	stopped := waitForEvent(t, events, EventStopped)
+	if stopped.State != SessionLimitReached {
+		t.Fatalf("停止状态 = %s（%s）", stopped.State, stopped.Message)
+	}
+	want := []string{
+		"list audittask p2",
+		"list audittask p1",
+		"claim audittask [1]",
+		"list audittask p3",
+		"list audittask p1",
+		"claim audittask [5]",
+	}
+	if got := fake.requestLog(); !slices.Equal(got, want) {
+		t.Errorf("请求顺序 = %q\n期望 %q", got, want)
+	}
+	if status := claimer.GetStatus(); status.AttemptCount != 4 {
+		t.Errorf("尝试次数 = %d，期望 4", status.AttemptCount)
+	}
+}
+
+func TestAutoClaimerDoesNotPollWithoutTicks(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	clock := newFakeClock(time.Date(2025, 1, 7, 1, 0, 0, 0, time.UTC))
+
+	claimer, _ := startTestClaimer(t, fake, AutoClaimConfig{TaskType: "audittask", Interval: 0.01}, WithClock(clock))
+
+	waitForAttempt(t, claimer, 1)
+	waitFor(t, "创建定时器", func() bool { return clock.activeTimers() == 2 })
+	time.Sleep(50 * time.Millisecond)
+	if got := fake.requestLog(); !slices.Equal(got, []string{"list audittask p1"}) {
+		t.Errorf("时钟未推进时不应继续轮询，请求 = %q", got)
+	}
+}
+
+func TestAutoClaimerLicenseExpiresOnClock(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	start := time.Date(2025, 1, 7, 1, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	expiresAt := start.Add(2 * time.Hour)
+
+	_, events := startTestClaimer(t, fake, AutoClaimConfig{TaskType: "audittask", Interval: 60},
+		WithClock(clock),
+		WithLicense(&AuthResult{ExpiresAt: expiresAt}, AutoClaimConfig{}, nil),
+	)
+
+	// 认领循环的两个定时器，加上授权复核、过期提醒和过期定时器
+	waitFor(t, "创建定时器", func() bool { return clock.activeTimers() == 5 })
+
+	clock.Advance(time.Hour)
+	if warning := waitForEvent(t, events, EventLicenseExpiring); !warning.Time.Equal(start.Add(time.Hour)) {
+		t.Errorf("提醒时间 = %s", warning.Time)
+	}
+
+	clock.Advance(time.Hour)
+	stopped := waitForEvent(t, events, EventStopped)
+	if stopped.State != SessionLicenseEnded || !stopped.Time.Equal(expiresAt) {
+		t.Errorf("停止事件 = %+v，期望在 %s 因授权过期停止", stopped, expiresAt)
+	}
+}
+
+func TestAutoClaimerLicenseRecheckAppliesPlanChanges(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	start := time.Date(2025, 1, 7, 1, 0, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+
+	var mutex sync.Mutex
+	plan := &AuthResult{ExpiresAt: start.Add(48 * time.Hour), Limit: 5}
+	recheck := func(ctx context.Context) (*AuthResult, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		result := *plan
+		return &result, nil
+	}
+	setPlan := func(result AuthResult) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		plan = &result
+	}
+
+	requested := AutoClaimConfig{TaskType: "audittask", Interval: 3600, LicenseCheckInterval: 60, ConcurrentClaims: 2}
+	config := requested
+	plan.ApplyLimits(&config)
+	claimer, events := startTestClaimer(t, fake, config, WithClock(clock), WithLicense(plan, requested, recheck))
+	limits := func() (int, int) {
+		claimer.mutex.RLock()
+		defer claimer.mutex.RUnlock()
+		return claimer.config.ClaimLimit, claimer.config.ConcurrentClaims
+	}
+	waitFor(t, "创建定时器", func() bool { return clock.activeTimers() == 5 })
+
+	// 升级为不过期的更高上限：按用户配置放宽，并提示不再过期
+	setPlan(AuthResult{Limit: 20})
+	clock.Advance(time.Minute)
+	if event := waitForEvent(t, events, EventLicenseRenewed); !strings.Contains(event.Message, "认领上限调整为 20") {
+		t.Errorf("方案变更提示 = %q", event.Message)
+	}
+	if event := waitForEvent(t, events, EventLicenseRenewed); event.Message != "授权已续期，不再过期" {
+		t.Errorf("续期提示 = %q", event.Message)
+	}
+	if claimLimit, concurrency := limits(); claimLimit != 20 || concurrency != 2 {
+		t.Errorf("升级后上限 = %d，并发数 = %d", claimLimit, concurrency)
+	}
+
+	// 降级后立即收紧
+	setPlan(AuthResult{Limit: 1})
+	clock.Advance(time.Minute)
+	waitForEvent(t, events, EventLicenseRenewed)
+	if claimLimit, concurrency := limits(); claimLimit != 1 || concurrency != 1 {
+		t.Errorf("降级后上限 = %d，并发数 = %d", claimLimit, concurrency)
+	}
+}
+
+func TestAutoClaimerLicenseRecheckOverlapsClaims(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	fake.addTasks("audittask", TaskItem{TaskID: 1, Brief: "数学"})
+	fake.failClaim(2001, "超出每日认领数量")
+	clock := newFakeClock(time.Date(2025, 1, 7, 1, 0, 0, 0, time.UTC))
+
+	// 每次复核在 2 和 100 之间切换上限，与认领尝试在同一时刻触发
+	var mutex sync.Mutex
+	rechecks := 0
+	recheck := func(ctx context.Context) (*AuthResult, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		rechecks++
+		if rechecks%2 == 1 {
+			return &AuthResult{Limit: 2}, nil
+		}
+		return &AuthResult{Limit: 100}, nil
+	}
+
+	plan := &AuthResult{Limit: 100}
+	requested := AutoClaimConfig{TaskType: "audittask", Interval: 1, LicenseCheckInterval: 1, ConcurrentClaims: 8}
+	config := requested
+	plan.ApplyLimits(&config)
+	release := fake.hold(t)
+	claimer, _ := startTestClaimer(t, fake, config, WithClock(clock), WithLicense(plan, requested, recheck))
+	waitFor(t, "首次尝试进行中", func() bool { return claimer.GetStatus().ActiveTasks == 1 })
+	waitFor(t, "创建定时器", func() bool { return clock.activeTimers() == 3 })
+
+	// 首次尝试停在列表请求中时复核降级，并发上限随之收紧
+	clock.Advance(time.Second)
+	waitFor(t, "并发上限收紧", func() bool { return claimer.GetStatus().Stats.MaxConcurrent == maxConcurrentFor(2) })
+	release()
+	waitForAttempt(t, claimer, 2)
+
+	for n := 3; n <= 12; n++ {
+		clock.Advance(time.Second)
+		waitForAttempt(t, claimer, n)
+	}
+	// 第 11 次复核再次降级
+	waitFor(t, "并发上限再次收紧", func() bool {
+		mutex.Lock()
+		done := rechecks == 11
+		mutex.Unlock()
+		return done && claimer.GetStatus().Stats.MaxConcurrent == maxConcurrentFor(2)
+	})
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTestClaimer 使用替身服务器创建并启动认领会话，返回事件通道
+func startTestClaimer(t *testing.T, fake *fakeEasylearn, config AutoClaimConfig, opts ...AutoClaimerOption) (*AutoClaimer, <-chan ClaimEvent) {
+	t.Helper()
+
+	config.ServerBaseURL = fake.URL
+	if config.Cookie == "" {
+		config.Cookie = fakeCookie
+	}
+	if config.Interval == 0 {
+		config.Interval = 0.05
+	}
+	if config.ConcurrentClaims == 0 {
+		config.ConcurrentClaims = 2
+	}
+
+	events := make(chan ClaimEvent, 100)
+	opts = append(opts, WithEventListener(func(event ClaimEvent) {
+		events <- event
+	}))
+	claimer, err := StartAutoClaiming(context.Background(), config, opts...)
+	if err != nil {
+		t.Fatalf("启动认领失败: %v", err)
+	}
+	t.Cleanup(claimer.Stop)
+	return claimer, events
+}
+
+// waitForEvent 等待指定类型的事件，之前的其他事件被丢弃
+func waitForEvent(t *testing.T, events <-chan ClaimEvent, eventType ClaimEventType) ClaimEvent {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Type == eventType {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("等待 %s 事件超时", eventType)
+		}
+	}
+}
+
+// waitForLog 等待包含 substr 的日志，之前的其他日志被丢弃
+func waitForLog(t *testing.T, claimer *AutoClaimer, substr string) {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case message := <-claimer.logCh:
+			if strings.Contains(message, substr) {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("等待日志 %q 超时", substr)
+		}
+	}
+}
+
+// waitFor 轮询直到条件满足
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待%s超时", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAutoClaimerClaimsMatchingAuditTasks(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	fake.addTasks("audittask",
+		TaskItem{TaskID: 1, Brief: "数学 二次函数"},
+		TaskItem{TaskID: 2, Brief: "英语 阅读理解"},
+		TaskItem{TaskID: 3, Brief: "数学 几何（草稿）"},
+		TaskItem{TaskID: 4, Brief: "数学 概率"},
+	)
+
+	claimer, events := startTestClaimer(t, fake, AutoClaimConfig{
+		TaskType:        "audittask",
+		ClaimLimit:      2,
+		IncludeKeywords: []string{"数学"},
+		ExcludeKeywords: []string{"草稿"},
+	})
+
+	stopped := waitForEvent(t, events, EventStopped)
+	if stopped.State != SessionLimitReached {
+		t.Fatalf("停止状态 = %s，期望 limit_reached（%s）", stopped.State, stopped.Message)
+	}
+	got := fake.claimedIDs("audittask")
+	slices.Sort(got)
+	if !slices.Equal(got, []int{1, 4}) {
+		t.Errorf("认领的任务 = %v，期望 [1 4]", got)
+	}
+	if status := claimer.GetStatus(); status.SuccessfulClaims != 2 || status.IsActive {
+		t.Errorf("状态 = %+v", status)
+	}
+}
+
+func TestAutoClaimerEmitsTaskClaimedEvents(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	fake.addTasks("audittask", TaskItem{TaskID: 7, Brief: "物理 电路"})
+
+	_, events := startTestClaimer(t, fake, AutoClaimConfig{TaskType: "audittask", ClaimLimit: 1})
+
+	claimed := waitForEvent(t, events, EventTaskClaimed)
+	if claimed.Task == nil || claimed.Task.TaskID != 7 {
+		t.Fatalf("task_claimed 事件缺少任务: %+v", claimed)
+	}
+	if batch := waitForEvent(t, events, EventBatchClaimed); batch.Claimed != 1 {
+		t.Errorf("batch_claimed 事件的认领数 = %d", batch.Claimed)
+	}
+}
+
+func TestAutoClaimerProduceTaskDispatchWindow(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	fake.addTasks("producetask",
+		TaskItem{TaskID: 1, ClueID: 101, Brief: "旧任务", DispatchTime: mustPlatformTime(t, "2025-01-06 23:59:59")},
+		TaskItem{TaskID: 2, ClueID: 102, Brief: "新任务", DispatchTime: mustPlatformTime(t, "2025-01-07 08:00:00")},
+		TaskItem{TaskID: 3, ClueID: 103, Brief: "没有发布时间"},
+		TaskItem{TaskID: 4, ClueID: 104, Brief: "新任务", DispatchTime: mustPlatformTime(t, "2025-01-07 09:30:00")},
+	)
+
+	_, events := startTestClaimer(t, fake, AutoClaimConfig{
+		TaskType:   "producetask",
+		ClaimLimit: 2,
+		StartTime:  "2025-01-07 00:00:00",
+		EndTime:    "2025-01-07 23:59:59",
+	})
+
+	waitForEvent(t, events, EventStopped)
+	got := fake.claimedIDs("producetask")
+	slices.Sort(got)
+	if !slices.Equal(got, []int{102, 104}) {
+		t.Errorf("认领的线索 = %v，期望 [102 104]（生产任务按 ClueID 认领）", got)
+	}
+}
+
+func TestAutoClaimerRaceLossesAreNotCounted(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	fake.addTasks("audittask",
+		TaskItem{TaskID: 1, Brief: "a"},
+		TaskItem{TaskID: 2, Brief: "b"},
+		TaskItem{TaskID: 3, Brief: "c"},
+	)
+	fake.loseRace(1, 2)
+
+	claimer, events := startTestClaimer(t, fake, AutoClaimConfig{TaskType: "audittask", ClaimLimit: 5})
+
+	waitForEvent(t, events, EventTaskClaimed)
+	waitFor(t, "任务池清空", func() bool {
+		_, claims := fake.calls()
+		return claims >= 3
+	})
+	if got := fake.claimedIDs("audittask"); !slices.Equal(got, []int{3}) {
+		t.Errorf("认领的任务 = %v，期望 [3]", got)
+	}
+	status := claimer.GetStatus()
+	if status.SuccessfulClaims != 1 || !status.IsActive {
+		t.Errorf("被抢先的任务不应计入认领数，状态 = %+v", status)
+	}
+}
+
+func TestAutoClaimerStopsWhenLoginExpires(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	fake.logout()
+
+	claimer, events := startTestClaimer(t, fake, AutoClaimConfig{TaskType: "audittask"})
+
+	waitForEvent(t, events, EventAuthExpired)
+	stopped := waitForEvent(t, events, EventStopped)
+	if stopped.State != SessionAuthExpired {
+		t.Errorf("停止状态 = %s，期望 auth_expired", stopped.State)
+	}
+	if status := claimer.GetStatus(); status.IsActive || !strings.Contains(status.StopReason, "未登录") {
+		t.Errorf("状态 = %+v", status)
+	}
+}
+
+func TestAutoClaimerKeepsRunningOnListErrno(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	fake.failList(500001, "系统繁忙")
+
+	claimer, events := startTestClaimer(t, fake, AutoClaimConfig{TaskType: "audittask", ClaimLimit: 1})
+
+	waitFor(t, "记录列表错误", func() bool {
+		return strings.Contains(claimer.GetStatus().LastError, "系统繁忙")
+	})
+	if !claimer.GetStatus().IsActive {
+		t.Fatal("列表接口临时出错时不应停止会话")
+	}
+
+	// 接口恢复后继续认领
+	fake.failList(0, "")
+	fake.addTasks("audittask", TaskItem{TaskID: 9, Brief: "恢复后"})
+	if stopped := waitForEvent(t, events, EventStopped); stopped.State != SessionLimitReached {
+		t.Errorf("停止状态 = %s，期望 limit_reached", stopped.State)
+	}
+}
+
+func TestAutoClaimerClaimErrnoIsReported(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	fake.addTasks("audittask", TaskItem{TaskID: 1, Brief: "a"})
+	fake.failClaim(2001, "超出每日认领数量")
+
+	claimer, _ := startTestClaimer(t, fake, AutoClaimConfig{TaskType: "audittask"})
+
+	waitFor(t, "认领请求", func() bool {
+		_, claims := fake.calls()
+		return claims > 0
+	})
+	waitFor(t, "记录认领响应", func() bool {
+		res := claimer.GetStatus().LastResponse
+		return res != nil && res.Errno == 2001
+	})
+	if got := fake.claimedIDs("audittask"); len(got) != 0 {
+		t.Errorf("认领失败时不应有任务被认领: %v", got)
+	}
+}
+
+func TestAutoClaimerSkipsTicksWhileRequestsAreSlow(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	release := fake.hold(t)
+	clock := newFakeClock(time.Date(2025, 1, 7, 1, 0, 0, 0, time.UTC))
+
+	claimer, _ := startTestClaimer(t, fake, AutoClaimConfig{TaskType: "audittask", Interval: 1, ConcurrentClaims: 1}, WithClock(clock))
+
+	waitFor(t, "创建定时器", func() bool { return clock.activeTimers() == 2 })
+
+	// 请求未返回时每次触发都开始新的尝试，直到占满并发上限
+	limit := claimer.maxConcurrent
+	for n := 1; ; n++ {
+		waitFor(t, "尝试进行中", func() bool {
+			status := claimer.GetStatus()
+			return status.AttemptCount == n && status.ActiveTasks == n
+		})
+		if n == limit {
+			break
+		}
+		clock.Advance(time.Second)
+	}
+
+	// 达到上限后的触发都应跳过，而不是叠加新的尝试
+	for range 3 {
+		clock.Advance(time.Second)
+		waitForLog(t, claimer, "跳过认领尝试")
+	}
+	if status := claimer.GetStatus(); status.AttemptCount != limit || status.ActiveTasks != limit {
+		t.Errorf("尝试次数 = %d，活跃任务 = %d，期望都为 %d", status.AttemptCount, status.ActiveTasks, limit)
+	}
+
+	release()
+	waitForAttempt(t, claimer, limit)
+	clock.Advance(time.Second)
+	waitForAttempt(t, claimer, limit+1)
+}
+
+func TestAutoClaimerDryRunDoesNotClaim(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	fake.addTasks("audittask",
+		TaskItem{TaskID: 1, Brief: "数学"},
+		TaskItem{TaskID: 2, Brief: "英语"},
+	)
+
+	claimer, events := startTestClaimer(t, fake, AutoClaimConfig{TaskType: "audittask", DryRun: true, IncludeKeywords: []string{"数学"}})
+
+	if event := waitForEvent(t, events, EventWouldClaim); event.Task == nil || event.Task.TaskID != 1 {
+		t.Fatalf("would_claim 事件 = %+v", event)
+	}
+	waitFor(t, "多轮筛选", func() bool {
+		return claimer.GetStatus().DryRun.Polls >= 3
+	})
+	claimer.Stop()
+
+	if _, claims := fake.calls(); claims != 0 {
+		t.Errorf("模拟运行不应调用认领接口，调用了 %d 次", claims)
+	}
+	summary := claimer.GetStatus().DryRun
+	if summary == nil || summary.UniqueMatched != 1 || summary.Polls < 3 || summary.TasksMatched < summary.Polls {
+		t.Errorf("模拟运行统计 = %+v", summary)
+	}
+}
+
+func TestClientAgainstFakeEasylearn(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	client := NewClient(fake.URL, fakeCookie)
+
+	labels, err := client.GetAuditTaskLabel("producetask")
+	if err != nil || labels.Errno != 0 || len(labels.Data.Filter) == 0 {
+		t.Fatalf("获取标签失败: %+v, %v", labels, err)
+	}
+	info, err := client.GetUserInfo()
+	if err != nil || info.Data.UserName != "fake-user" {
+		t.Fatalf("获取用户信息失败: %+v, %v", info, err)
+	}
+
+	loggedOut, err := NewClient(fake.URL, "BDUSS=wrong").GetUserInfo()
+	if err != nil || !IsAuthFailureErrno(loggedOut.Errno, loggedOut.Errmsg) {
+		t.Errorf("错误的 Cookie 应返回未登录: %+v, %v", loggedOut, err)
+	}
+}
+
+func mustPlatformTime(t *testing.T, value string) PlatformTime {
+	t.Helper()
+
+	pt, err := ParsePlatformTime(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pt
+}
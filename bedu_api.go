@@ -2,12 +2,15 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
 // Subject 表示教育系统中的学科
@@ -35,19 +38,19 @@ type LabelResponse struct {
 
 // TaskItem 表示任务列表响应中的单个任务项
 type TaskItem struct {
-	TaskID       int    `json:"taskID"`
-	ClueID       int    `json:"clueID"`
-	Brief        string `json:"brief"`
-	Step         int    `json:"step"`
-	Subject      int    `json:"subject"`
-	State        int    `json:"state"`
-	StepName     string `json:"stepName"`
-	SubjectName  string `json:"subjectName"`
-	ClueType     int    `json:"clueType"`
-	ClueTypeName string `json:"clueTypeName"`
-	StateName    string `json:"stateName"`
-	CreateTime   string `json:"createTime"`
-	DispatchTime string `json:"dispatchTime"`
+	TaskID       int          `json:"taskID"`
+	ClueID       int          `json:"clueID"`
+	Brief        string       `json:"brief"`
+	Step         int          `json:"step"`
+	Subject      int          `json:"subject"`
+	State        int          `json:"state"`
+	StepName     string       `json:"stepName"`
+	SubjectName  string       `json:"subjectName"`
+	ClueType     int          `json:"clueType"`
+	ClueTypeName string       `json:"clueTypeName"`
+	StateName    string       `json:"stateName"`
+	CreateTime   PlatformTime `json:"createTime"`
+	DispatchTime PlatformTime `json:"dispatchTime"`
 }
 
 // TaskListData 表示任务列表响应中的数据字段
@@ -70,14 +73,75 @@ type ClaimResponse struct {
 	Data   interface{} `json:"data"`
 }
 
+// ErrNotLoggedIn 表示请求被重定向到了登录页，Cookie 已失效
+var ErrNotLoggedIn = errors.New("登录态已失效，请重新获取Cookie")
+
+// HTTPStatusError 表示服务器返回了非 200 的状态码
+type HTTPStatusError struct {
+	StatusCode int
+	URL        string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("HTTP error! status: %d, URL: %s", e.StatusCode, e.URL)
+}
+
+// checkResponse 检查响应状态，未登录时平台会跳转到 passport 登录页
+func checkResponse(resp *http.Response, apiURL string) error {
+	if resp.Request != nil && strings.Contains(resp.Request.URL.Host, "passport.baidu.com") {
+		return ErrNotLoggedIn
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &HTTPStatusError{StatusCode: resp.StatusCode, URL: apiURL}
+	}
+	return nil
+}
+
+// authFailureKeywords 是平台在登录态失效时 errmsg 中常见的关键词
+var authFailureKeywords = []string{"未登录", "请登录", "登录失效", "登录已过期", "重新登录", "not login", "unauthorized"}
+
+// IsAuthFailure 判断请求错误是否由登录态失效引起
+func IsAuthFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrNotLoggedIn) {
+		return true
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden
+	}
+	return false
+}
+
+// IsAuthFailureErrno 判断接口返回的 errno/errmsg 是否表示登录态失效
+func IsAuthFailureErrno(errno int, errmsg string) bool {
+	if errno == 0 {
+		return false
+	}
+	// errno 是平台的业务错误码而非 HTTP 状态码，没有录制到把 401/403 用作登录失效的响应，只按 errmsg 判断
+	msg := strings.ToLower(errmsg)
+	for _, keyword := range authFailureKeywords {
+		if strings.Contains(msg, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetAuditTaskLabel 从服务器获取审核任务标签
 func GetAuditTaskLabel(taskType, serverBaseURL, cookie string) (*LabelResponse, error) {
+	return NewClient(serverBaseURL, cookie).GetAuditTaskLabel(taskType)
+}
+
+// GetAuditTaskLabel 从服务器获取审核任务标签
+func (c *Client) GetAuditTaskLabel(taskType string) (*LabelResponse, error) {
 	if taskType == "" {
 		taskType = "audittask"
 	}
 
-	// 使用传入的服务器基础URL和cookie
-	apiURL := fmt.Sprintf("%s/edushop/question/%s/getlabel", serverBaseURL, taskType)
+	apiURL := fmt.Sprintf("%s/edushop/question/%s/getlabel", c.BaseURL, taskType)
 
 	// 创建请求
 	req, err := http.NewRequest("GET", apiURL, nil)
@@ -85,24 +149,19 @@ func GetAuditTaskLabel(taskType, serverBaseURL, cookie string) (*LabelResponse,
 		return nil, err
 	}
 
-	// 如果cookie存在，设置头部
-	if cookie != "" {
-		req.Header.Set("Cookie", cookie)
-	}
 	// 设置User-Agent头部，模拟正常浏览器
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 
-	// 执行请求
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	// 执行请求，Cookie 由客户端的 CookieJar 携带
+	resp, err := c.HTTP.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	// 检查响应状态
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP error! status: %d, URL: %s", resp.StatusCode, apiURL)
+	if err := checkResponse(resp, apiURL); err != nil {
+		return nil, err
 	}
 
 	// 读取并解析响应体
@@ -153,6 +212,11 @@ func GetAuditTaskLabel(taskType, serverBaseURL, cookie string) (*LabelResponse,
 
 // GetAuditTaskList 从服务器获取审核任务列表
 func GetAuditTaskList(options map[string]interface{}, serverBaseURL, cookie string) (*TaskListResponse, error) {
+	return NewClient(serverBaseURL, cookie).GetAuditTaskList(options)
+}
+
+// GetAuditTaskList 从服务器获取审核任务列表
+func (c *Client) GetAuditTaskList(options map[string]interface{}) (*TaskListResponse, error) {
 	// Set default values
 	pn := 1
 	rn := 20
@@ -194,8 +258,7 @@ func GetAuditTaskList(options map[string]interface{}, serverBaseURL, cookie stri
 	queryParams.Add("step", strconv.Itoa(step))
 	queryParams.Add("subject", strconv.Itoa(subject))
 
-	// 使用传入的服务器基础URL和cookie
-	apiURL := fmt.Sprintf("%s/edushop/question/%s/list?%s", serverBaseURL, taskType, queryParams.Encode())
+	apiURL := fmt.Sprintf("%s/edushop/question/%s/list?%s", c.BaseURL, taskType, queryParams.Encode())
 
 	// 创建请求
 	req, err := http.NewRequest("GET", apiURL, nil)
@@ -203,25 +266,19 @@ func GetAuditTaskList(options map[string]interface{}, serverBaseURL, cookie stri
 		return nil, err
 	}
 
-	// 如果cookie存在，设置头部
-	if cookie != "" {
-		req.Header.Set("Cookie", cookie)
-	}
-
 	// 设置User-Agent头部，模拟正常浏览器
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 
-	// 执行请求
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	// 执行请求，Cookie 由客户端的 CookieJar 携带
+	resp, err := c.HTTP.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	// 检查响应状态
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP error! status: %d, URL: %s", resp.StatusCode, apiURL)
+	if err := checkResponse(resp, apiURL); err != nil {
+		return nil, err
 	}
 
 	// 读取并解析响应体
@@ -253,34 +310,38 @@ type UserInfoResponse struct {
 
 // GetUserInfo 获取用户信息
 func GetUserInfo(serverBaseURL, cookie string) (*UserInfoResponse, error) {
+	return NewClient(serverBaseURL, cookie).GetUserInfo()
+}
+
+// GetUserInfo 获取用户信息
+func (c *Client) GetUserInfo() (*UserInfoResponse, error) {
+	return c.GetUserInfoContext(context.Background())
+}
+
+// GetUserInfoContext 获取用户信息，ctx 结束时取消请求
+func (c *Client) GetUserInfoContext(ctx context.Context) (*UserInfoResponse, error) {
 	// 构建用户信息API URL
-	apiURL := fmt.Sprintf("%s/edushop/user/common/info", serverBaseURL)
+	apiURL := fmt.Sprintf("%s/edushop/user/common/info", c.BaseURL)
 
 	// 创建请求
-	req, err := http.NewRequest("GET", apiURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	// 如果cookie存在，设置头部
-	if cookie != "" {
-		req.Header.Set("Cookie", cookie)
-	}
-
 	// 设置User-Agent头部，模拟正常浏览器
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 
-	// 执行请求
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	// 执行请求，Cookie 由客户端的 CookieJar 携带
+	resp, err := c.HTTP.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	// 检查响应状态
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP error! status: %d, URL: %s", resp.StatusCode, apiURL)
+	if err := checkResponse(resp, apiURL); err != nil {
+		return nil, err
 	}
 
 	// 读取并解析响应体
@@ -300,6 +361,11 @@ func GetUserInfo(serverBaseURL, cookie string) (*UserInfoResponse, error) {
 
 // ClaimAuditTask 认领一个或多个审核任务
 func ClaimAuditTask(taskIDs []string, taskType, serverBaseURL, cookie string) (*ClaimResponse, error) {
+	return NewClient(serverBaseURL, cookie).ClaimAuditTask(taskIDs, taskType)
+}
+
+// ClaimAuditTask 认领一个或多个审核任务
+func (c *Client) ClaimAuditTask(taskIDs []string, taskType string) (*ClaimResponse, error) {
 	if taskType == "" {
 		taskType = "audittask"
 	}
@@ -345,8 +411,7 @@ func ClaimAuditTask(taskIDs []string, taskType, serverBaseURL, cookie string) (*
 		return nil, err
 	}
 
-	// 使用传入的服务器基础URL和cookie
-	apiURL := fmt.Sprintf("%s/edushop/question/%s/claim", serverBaseURL, commitType)
+	apiURL := fmt.Sprintf("%s/edushop/question/%s/claim", c.BaseURL, commitType)
 
 	// 创建请求
 	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(requestJSON))
@@ -356,24 +421,20 @@ func ClaimAuditTask(taskIDs []string, taskType, serverBaseURL, cookie string) (*
 
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
-	if cookie != "" {
-		req.Header.Set("Cookie", cookie)
-	}
 
 	// 设置User-Agent头部，模拟正常浏览器
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 
-	// 执行请求
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	// 执行请求，Cookie 由客户端的 CookieJar 携带
+	resp, err := c.HTTP.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	// 检查响应状态
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP error! status: %d, URL: %s", resp.StatusCode, apiURL)
+	if err := checkResponse(resp, apiURL); err != nil {
+		return nil, err
 	}
 
 	// 读取并解析响应体
@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestIsAuthFailureErrno(t *testing.T) {
+	tests := []struct {
+		errno  int
+		errmsg string
+		want   bool
+	}{
+		{0, "", false},
+		{0, "用户未登录", false},
+		{110000, "用户未登录", true},
+		{1, "登录已过期，请重新登录", true},
+		{1, "Unauthorized", true},
+		{2001, "超出每日认领数量", false},
+		// errno 是业务错误码，不能当作 HTTP 状态码
+		{401, "任务已被领取", false},
+		{403, "无权限操作该任务", false},
+	}
+	for _, tt := range tests {
+		if got := IsAuthFailureErrno(tt.errno, tt.errmsg); got != tt.want {
+			t.Errorf("IsAuthFailureErrno(%d, %q) = %v，期望 %v", tt.errno, tt.errmsg, got, tt.want)
+		}
+	}
+}
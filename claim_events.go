@@ -0,0 +1,67 @@
+package main
+
+import "time"
+
+// SessionState 表示自动认领会话当前所处的状态
+type SessionState string
+
+const (
+	SessionIdle         SessionState = "idle"          // 尚未启动
+	SessionRunning      SessionState = "running"       // 正在认领
+	SessionStopped      SessionState = "stopped"       // 被手动停止
+	SessionLimitReached SessionState = "limit_reached" // 已达到认领上限
+	SessionAuthExpired  SessionState = "auth_expired"  // Cookie 登录态失效
+	SessionLicenseEnded SessionState = "license_ended" // 软件授权过期或被撤销
+)
+
+// ClaimEventType 表示自动认领过程中产生的事件类型
+type ClaimEventType string
+
+const (
+	EventAuthExpired     ClaimEventType = "auth_expired"     // Cookie 登录态失效
+	EventStopped         ClaimEventType = "stopped"          // 会话停止（包含停止原因）
+	EventCookieUpdated   ClaimEventType = "cookie_updated"   // 服务器通过 Set-Cookie 刷新了 Cookie
+	EventCookieExpired   ClaimEventType = "cookie_expired"   // 关键 Cookie 被服务器删除或已过期
+	EventLicenseExpiring ClaimEventType = "license_expiring" // 软件授权将在 1 小时内过期
+	EventLicenseRenewed  ClaimEventType = "license_renewed"  // 复核时发现授权已续期或方案变更
+	EventTaskClaimed     ClaimEventType = "task_claimed"     // 成功认领了一个任务
+	EventBatchClaimed    ClaimEventType = "batch_claimed"    // 一轮并发认领中至少认领成功一个任务
+	EventWouldClaim      ClaimEventType = "would_claim"      // 模拟运行中首次发现通过筛选的任务
+)
+
+// ClaimEvent 表示自动认领过程中产生的一个事件
+type ClaimEvent struct {
+	Type    ClaimEventType `json:"type"`
+	Time    time.Time      `json:"time"`
+	State   SessionState   `json:"state"`
+	Message string         `json:"message"`
+	Claimed int            `json:"claimed"`
+	Task    *TaskItem      `json:"task,omitempty"` // 认领成功或模拟运行中将认领的任务
+	Cookie  string         `json:"-"`              // 刷新后的 Cookie，不随事件推送到外部
+}
+
+// OnEvent 注册事件监听器，监听器在产生事件的 goroutine 中同步调用，不应阻塞
+func (ac *AutoClaimer) OnEvent(listener func(ClaimEvent)) {
+	ac.mutex.Lock()
+	defer ac.mutex.Unlock()
+
+	ac.listeners = append(ac.listeners, listener)
+}
+
+// emit 将事件分发给所有监听器，调用时不能持有 ac.mutex
+func (ac *AutoClaimer) emit(event ClaimEvent) {
+	ac.mutex.RLock()
+	listeners := append([]func(ClaimEvent){}, ac.listeners...)
+	if event.State == "" {
+		event.State = ac.status.State
+	}
+	event.Claimed = ac.actualClaims
+	ac.mutex.RUnlock()
+
+	if event.Time.IsZero() {
+		event.Time = ac.clock.Now()
+	}
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,7 +16,7 @@ type AutoClaimConfig struct {
 	Cookie        string  // 认证 cookie
 	TaskType      string  // 要认领的任务类型（"audittask" 或 "producetask"）
 	ClaimLimit    int     // 要认领的最大任务数
-	Interval      float64 // 认领尝试之间的间隔（秒），支持小数，最小 0.001 秒（1毫秒）
+	Interval      float64 // 认领尝试之间的间隔（秒），支持小数，最小 0.01 秒（10毫秒，见 minInterval）
 
 	// 随机页面参数
 	MaxPages int // 请求时的最大随机页码，0 表示禁用随机页码（始终请求第1页）
@@ -35,12 +34,22 @@ type AutoClaimConfig struct {
 	ExcludeKeywords []string // 任务简介中不能存在的关键词
 
 	// 发布时间过滤器
-	StartTime string // 开始时间，格式: "2006-01-02 15:04:05"
-	EndTime   string // 结束时间，格式: "2006-01-02 15:04:05"
+	StartTime      string // 开始时间，格式: "2006-01-02 15:04:05"，按平台时区解析
+	EndTime        string // 结束时间，格式: "2006-01-02 15:04:05"，按平台时区解析
+	DispatchWindow string `json:"dispatchWindow,omitempty"` // 相对时间窗口（today、session、last:30m），设置后代替 StartTime/EndTime
+
+	// 模拟运行：只获取和筛选任务列表，不调用认领接口
+	DryRun bool `json:"dryRun,omitempty"`
 
 	// 授权参数
 	AuthType     string `json:"authType"`     // 授权类型："official" 或 "custom"
 	AuthUsername string `json:"authUsername"` // 官方授权用户名
+
+	// 登录态检查参数
+	AuthCheckInterval float64 `json:"authCheckInterval,omitempty"` // 定期校验 Cookie 的间隔（秒），默认 60 秒
+
+	// 授权复核参数
+	LicenseCheckInterval float64 `json:"licenseCheckInterval,omitempty"` // 定期复核软件授权的间隔（秒），默认 600 秒
 }
 
 // ClaimStatus 表示自动认领过程的当前状态
@@ -51,6 +60,11 @@ type ClaimStatus struct {
 	LastResponse     *ClaimResponse // 来自认领 API 的最后响应
 	ActiveTasks      int            // 当前活跃的任务数
 	AttemptCount     int            // 总尝试次数
+	State            SessionState   // 会话状态
+	StopReason       string         // 会话停止的原因
+	LicenseExpiresAt time.Time      // 软件授权过期时间，零值表示不过期
+	DryRun           *DryRunSummary // 模拟运行的筛选统计，非模拟运行时为 nil
+	Stats            SessionStats   // 本次会话的请求和任务统计
 }
 
 // AutoClaimer 处理任务的自动认领
@@ -64,85 +78,35 @@ type AutoClaimer struct {
 	activeTasks   int         // 当前活跃的任务数
 	logCh         chan string // 用于非阻塞日志记录的通道
 	maxConcurrent int         // 最大并发任务数
+	listeners     []func(ClaimEvent)
+	client        *Client // 使用 CookieJar 维护登录态的接口客户端
+	license       *licenseWatch
+	metrics       *Metrics
+	archive       *PoolArchive
+	stats         *sessionStats
+	startedAt     time.Time           // 本次会话的启动时间，用于 session 时间窗口
+	wouldClaim    map[string]struct{} // 模拟运行中已通过筛选的任务 ID
+	clock         Clock               // 时间和定时器，默认使用系统时间
+	rand          Rand                // 随机页码的随机源，默认使用 math/rand
 }
 
-// filterByKeywords 根据包含和排除关键词筛选任务
-func filterByKeywords(text string, includeKeywords, excludeKeywords []string) bool {
-	text = strings.ToLower(text)
+// AutoClaimerOption 用于在创建 AutoClaimer 时注入可选依赖
+type AutoClaimerOption func(*AutoClaimer)
 
-	// Check exclude keywords first (faster rejection)
-	for _, keyword := range excludeKeywords {
-		if keyword != "" && strings.Contains(text, strings.ToLower(keyword)) {
-			return false
-		}
-	}
-
-	// If no include keywords are specified, accept the task
-	if len(includeKeywords) == 0 {
-		return true
-	}
-
-	// Check if any include keyword is present
-	for _, keyword := range includeKeywords {
-		if keyword != "" && strings.Contains(text, strings.ToLower(keyword)) {
-			return true
-		}
+// WithEventListener 在启动前注册事件监听器
+func WithEventListener(listener func(ClaimEvent)) AutoClaimerOption {
+	return func(ac *AutoClaimer) {
+		ac.listeners = append(ac.listeners, listener)
 	}
-
-	// If include keywords are specified but none match, reject the task
-	return false
 }
 
-// filterByDispatchTime 根据发布时间筛选任务
-func filterByDispatchTime(dispatchTime, startTime, endTime string) bool {
-	// 如果没有设置时间过滤器，接受所有任务
-	if startTime == "" && endTime == "" {
-		return true
-	}
-
-	// 如果任务没有dispatchTime，拒绝
-	if dispatchTime == "" {
-		return false
-	}
-
-	// 解析任务的发布时间
-	taskTime, err := time.Parse("2006-01-02 15:04:05", dispatchTime)
-	if err != nil {
-		// 如果时间格式不正确，拒绝
-		return false
-	}
-
-	// 检查开始时间约束
-	if startTime != "" {
-		startTimeObj, err := time.Parse("2006-01-02 15:04:05", startTime)
-		if err != nil {
-			// 如果开始时间格式不正确，跳过开始时间检查
-		} else if taskTime.Before(startTimeObj) {
-			return false
-		}
-	}
-
-	// 检查结束时间约束
-	if endTime != "" {
-		endTimeObj, err := time.Parse("2006-01-02 15:04:05", endTime)
-		if err != nil {
-			// 如果结束时间格式不正确，跳过结束时间检查
-		} else if taskTime.After(endTimeObj) {
-			return false
-		}
-	}
-
-	return true
-}
-
-// NewAutoClaimer 使用给定的配置创建一个新的 AutoClaimer
-func NewAutoClaimer(config AutoClaimConfig) *AutoClaimer {
-	// Set default values if not provided
+// withConfigDefaults 为未设置的参数填入默认值，无效值应先由 Validate 拒绝
+func withConfigDefaults(config AutoClaimConfig) AutoClaimConfig {
 	if config.TaskType == "" {
 		config.TaskType = "audittask"
 	}
 
-	if config.Interval < 0.001 {
+	if config.Interval < minInterval {
 		config.Interval = 1.0
 	}
 
@@ -158,15 +122,75 @@ func NewAutoClaimer(config AutoClaimConfig) *AutoClaimer {
 		config.ConcurrentClaims = 10
 	}
 
-	maxConcurrent := max(config.ConcurrentClaims*2, 4) // 允许比单个任务的并发数更多的并发任务，最少4个
+	if config.AuthCheckInterval <= 0 {
+		config.AuthCheckInterval = 60
+	}
+
+	if config.LicenseCheckInterval <= 0 {
+		config.LicenseCheckInterval = 600
+	}
+
+	return config
+}
+
+// maxConcurrentFor 返回同时进行的认领尝试上限，
+// 允许比单个任务的并发数更多的并发任务，最少4个
+func maxConcurrentFor(concurrentClaims int) int {
+	return max(concurrentClaims*2, 4)
+}
 
-	return &AutoClaimer{
+// NewAutoClaimer 使用给定的配置创建一个新的 AutoClaimer
+func NewAutoClaimer(config AutoClaimConfig, opts ...AutoClaimerOption) *AutoClaimer {
+	config = withConfigDefaults(config)
+
+	maxConcurrent := maxConcurrentFor(config.ConcurrentClaims)
+
+	ac := &AutoClaimer{
 		config: config,
 		status: ClaimStatus{
 			IsActive: false,
+			State:    SessionIdle,
 		},
 		logCh:         make(chan string, 100), // 创建带缓冲的日志通道，避免阻塞
 		maxConcurrent: maxConcurrent,
+		client:        NewClient(config.ServerBaseURL, config.Cookie),
+		clock:         realClock{},
+		rand:          globalRand{},
+		stats:         newSessionStats(),
+	}
+	ac.client.OnCookieChange(ac.handleCookieChange)
+	for _, opt := range opts {
+		opt(ac)
+	}
+	if ac.license != nil {
+		ac.status.LicenseExpiresAt = ac.license.expiresAt
+	}
+
+	return ac
+}
+
+// Cookie 返回当前使用的 Cookie，包含服务器通过 Set-Cookie 刷新后的值
+func (ac *AutoClaimer) Cookie() string {
+	return ac.client.Cookie()
+}
+
+// handleCookieChange 在服务器刷新或删除 Cookie 时通知监听器
+func (ac *AutoClaimer) handleCookieChange(change CookieChange) {
+	if len(change.Updated) > 0 {
+		ac.logf("服务器刷新了 Cookie：%s", strings.Join(change.Updated, ", "))
+		ac.emit(ClaimEvent{
+			Type:    EventCookieUpdated,
+			Message: fmt.Sprintf("服务器刷新了 Cookie：%s", strings.Join(change.Updated, ", ")),
+			Cookie:  change.Cookie,
+		})
+	}
+	if change.CriticalExpired {
+		ac.logf("关键 Cookie 已被服务器删除或过期：%s", strings.Join(change.Expired, ", "))
+		ac.emit(ClaimEvent{
+			Type:    EventCookieExpired,
+			Message: fmt.Sprintf("关键 Cookie 已被服务器删除或过期：%s", strings.Join(change.Expired, ", ")),
+			Cookie:  change.Cookie,
+		})
 	}
 }
 
@@ -179,6 +203,16 @@ func (ac *AutoClaimer) GetStatus() ClaimStatus {
 	status := ac.status
 	status.ActiveTasks = ac.activeTasks
 	status.AttemptCount = ac.attemptCount
+	if status.DryRun != nil {
+		summary := *status.DryRun
+		status.DryRun = &summary
+	}
+	status.Stats = ac.stats.snapshot(ac.clock.Now())
+	status.Stats.IntervalSeconds = ac.config.Interval
+	status.Stats.Attempts = ac.attemptCount
+	status.Stats.ActiveTasks = ac.activeTasks
+	status.Stats.MaxConcurrent = ac.maxConcurrent
+	status.Stats.TasksClaimed = ac.actualClaims
 
 	return status
 }
@@ -193,6 +227,11 @@ func (ac *AutoClaimer) Start(ctx context.Context) error {
 		return fmt.Errorf("auto-claiming is already active")
 	}
 
+	// 授权已过期时不允许启动
+	if ac.license != nil && ac.license.expired(ac.clock.Now()) {
+		return fmt.Errorf("授权已过期，过期时间: %s", ac.license.expiresAt.Local().Format("2006-01-02 15:04:05"))
+	}
+
 	// 重置计数器
 	ac.actualClaims = 0
 	ac.attemptCount = 0
@@ -202,29 +241,100 @@ func (ac *AutoClaimer) Start(ctx context.Context) error {
 	ac.status.LastResponse = nil
 	ac.status.ActiveTasks = 0
 	ac.status.AttemptCount = 0
+	ac.status.StopReason = ""
+	ac.startedAt = ac.clock.Now()
+	ac.stats.reset(ac.startedAt)
+	ac.status.DryRun = nil
+	if ac.config.DryRun {
+		ac.status.DryRun = &DryRunSummary{}
+		ac.wouldClaim = make(map[string]struct{})
+	}
 
 	// 创建一个带有取消功能的新上下文
 	ctxWithCancel, cancel := context.WithCancel(ctx)
 	ac.cancel = cancel
 	ac.status.IsActive = true
+	ac.status.State = SessionRunning
 
 	// 日志处理由调用者处理
 
 	// 在一个 goroutine 中启动自动认领循环
 	go ac.autoClaimLoop(ctxWithCancel)
+	if ac.license != nil {
+		go ac.licenseLoop(ctxWithCancel)
+	}
 
 	return nil
 }
 
 // Stop 停止自动认领过程
 func (ac *AutoClaimer) Stop() {
+	ac.stop(SessionStopped, "已手动停止")
+}
+
+// stop 停止自动认领过程并记录停止状态和原因，会话未运行时不做任何处理
+func (ac *AutoClaimer) stop(state SessionState, reason string) {
 	ac.mutex.Lock()
-	defer ac.mutex.Unlock()
+	if !ac.status.IsActive || ac.cancel == nil {
+		ac.mutex.Unlock()
+		return
+	}
+	ac.cancel()
+	ac.status.IsActive = false
+	ac.cancel = nil
+	if ac.status.DryRun != nil {
+		reason = fmt.Sprintf("%s；%s", reason, ac.status.DryRun)
+	}
+	ac.status.State = state
+	ac.status.StopReason = reason
+	ac.mutex.Unlock()
+	ac.stats.finish(ac.clock.Now())
 
-	if ac.status.IsActive && ac.cancel != nil {
-		ac.cancel()
-		ac.status.IsActive = false
-		ac.cancel = nil
+	ac.logf("自动认领已停止：%s", reason)
+	ac.emit(ClaimEvent{Type: EventStopped, State: state, Message: reason})
+}
+
+// logf 以非阻塞方式发送带时间戳的日志消息
+func (ac *AutoClaimer) logf(format string, args ...any) {
+	select {
+	case ac.logCh <- fmt.Sprintf("[%s] %s", ac.clock.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf(format, args...)):
+	default:
+	}
+}
+
+// expireAuth 将会话标记为登录态失效并停止认领
+func (ac *AutoClaimer) expireAuth(reason string) {
+	ac.mutex.Lock()
+	if !ac.status.IsActive {
+		ac.mutex.Unlock()
+		return
+	}
+	ac.status.LastError = reason
+	ac.mutex.Unlock()
+
+	ac.emit(ClaimEvent{Type: EventAuthExpired, State: SessionAuthExpired, Message: reason})
+	ac.stop(SessionAuthExpired, reason)
+}
+
+// checkAuth 通过用户信息接口校验 Cookie 是否仍然有效
+func (ac *AutoClaimer) checkAuth(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	userInfo, err := ac.client.GetUserInfo()
+	if err != nil {
+		if IsAuthFailure(err) {
+			ac.expireAuth(fmt.Sprintf("Cookie 已失效：%v", err))
+			return
+		}
+		// 网络错误不代表登录态失效，等待下一次检查
+		ac.logf("校验登录态失败：%v", err)
+		return
+	}
+
+	if IsAuthFailureErrno(userInfo.Errno, userInfo.Errmsg) || (userInfo.Errno == 0 && userInfo.Data.UserName == "") {
+		ac.expireAuth(fmt.Sprintf("Cookie 已失效：%s", userInfo.Errmsg))
 	}
 }
 
@@ -234,27 +344,46 @@ func (ac *AutoClaimer) autoClaimLoop(ctx context.Context) {
 	go ac.performAutoClaiming(ctx)
 
 	// 设置定时器进行周期性认领尝试
-	ticker := time.NewTicker(time.Duration(ac.config.Interval * float64(time.Second)))
+	ticker := ac.clock.NewTicker(time.Duration(ac.config.Interval * float64(time.Second)))
 	defer ticker.Stop()
 
+	// 定期校验登录态，避免 Cookie 失效后无休止地重试
+	authTicker := ac.clock.NewTicker(time.Duration(ac.config.AuthCheckInterval * float64(time.Second)))
+	defer authTicker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			// Context was cancelled, exit the loop
 			select {
-			case ac.logCh <- fmt.Sprintf("[%s] 由于上下文取消，自动认领已停止", time.Now().Format("2006-01-02 15:04:05")):
+			case ac.logCh <- fmt.Sprintf("[%s] 由于上下文取消，自动认领已停止", ac.clock.Now().Format("2006-01-02 15:04:05")):
 				// 消息已发送到通道
 			default:
 				// 通道已满，但我们不想阻塞，所以忽略
 			}
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			// Time to attempt another claim - 异步执行，不等待完成
 			go ac.performAutoClaiming(ctx)
+		case <-authTicker.C():
+			go ac.checkAuth(ctx)
 		}
 	}
 }
 
+// taskListOptions 生成获取任务列表的请求参数
+func taskListOptions(config AutoClaimConfig, pageNum int) map[string]any {
+	return map[string]any{
+		"pn":       pageNum,
+		"rn":       20,
+		"clueID":   "",
+		"clueType": config.ClueTypeID,
+		"step":     config.StepID,
+		"subject":  config.SubjectID,
+		"taskType": config.TaskType,
+	}
+}
+
 // performAutoClaiming 尝试根据配置认领任务（异步执行）
 func (ac *AutoClaimer) performAutoClaiming(ctx context.Context) {
 	// Check if context is already cancelled
@@ -265,10 +394,11 @@ func (ac *AutoClaimer) performAutoClaiming(ctx context.Context) {
 	// 检查并发限制
 	ac.mutex.Lock()
 	if ac.activeTasks >= ac.maxConcurrent {
+		activeTasks := ac.activeTasks
 		ac.mutex.Unlock()
 		// 使用非阻塞方式发送日志消息
 		select {
-		case ac.logCh <- fmt.Sprintf("[%s] 跳过认领尝试：已达到最大并发数 (%d)", time.Now().Format("2006-01-02 15:04:05"), ac.activeTasks):
+		case ac.logCh <- fmt.Sprintf("[%s] 跳过认领尝试：已达到最大并发数 (%d)", ac.clock.Now().Format("2006-01-02 15:04:05"), activeTasks):
 		default:
 		}
 		return
@@ -278,12 +408,17 @@ func (ac *AutoClaimer) performAutoClaiming(ctx context.Context) {
 	ac.attemptCount++
 	attemptNum := ac.attemptCount
 	actualClaims := ac.actualClaims
+	// 授权复核可能调整上限，本轮尝试只读取开始时的配置快照
+	config := ac.config
+	maxConcurrent := ac.maxConcurrent
+	ac.metrics.setConcurrency(ac.activeTasks, ac.maxConcurrent)
 	ac.mutex.Unlock()
 
 	// 确保在函数退出时减少活跃任务数
 	defer func() {
 		ac.mutex.Lock()
 		ac.activeTasks--
+		ac.metrics.setConcurrency(ac.activeTasks, ac.maxConcurrent)
 		ac.mutex.Unlock()
 	}()
 
@@ -293,100 +428,98 @@ func (ac *AutoClaimer) performAutoClaiming(ctx context.Context) {
 	ac.mutex.RUnlock()
 
 	select {
-	case ac.logCh <- fmt.Sprintf("[%s] 认领尝试 #%d 开始，当前认领数：%d/%d，活跃任务：%d/%d", time.Now().Format("2006-01-02 15:04:05"), attemptNum, actualClaims, ac.config.ClaimLimit, currentActive, ac.maxConcurrent):
+	case ac.logCh <- fmt.Sprintf("[%s] 认领尝试 #%d 开始，当前认领数：%d/%d，活跃任务：%d/%d", ac.clock.Now().Format("2006-01-02 15:04:05"), attemptNum, actualClaims, config.ClaimLimit, currentActive, maxConcurrent):
 		// 消息已发送到通道
 	default:
 		// 通道已满，但我们不想阻塞，所以忽略
 	}
 
 	// Check if we've reached the claim limit
-	if actualClaims >= ac.config.ClaimLimit {
+	if actualClaims >= config.ClaimLimit {
 		// 使用非阻塞方式发送日志消息
-		select {
-		case ac.logCh <- fmt.Sprintf("[%s] 认领限制已达到 (%d/%d)，停止自动认领", time.Now().Format("2006-01-02 15:04:05"), actualClaims, ac.config.ClaimLimit):
-			// 消息已发送到通道
-		default:
-			// 通道已满，但我们不想阻塞，所以忽略
-		}
-		ac.Stop()
+		ac.stop(SessionLimitReached, fmt.Sprintf("认领限制已达到 (%d/%d)", actualClaims, config.ClaimLimit))
 		return
 	}
 
 	// 计算还需要认领多少个任务
-	remainingClaimsNeeded := ac.config.ClaimLimit - actualClaims
+	remainingClaimsNeeded := config.ClaimLimit - actualClaims
 
 	// 确定页码
 	pageNum := 1
-	if ac.config.MaxPages > 1 {
+	if config.MaxPages > 1 {
 		// 使用随机页码，范围从 1 到 MaxPages
-		pageNum = rand.Intn(ac.config.MaxPages) + 1
+		pageNum = ac.rand.Intn(config.MaxPages) + 1
 		// 记录使用的随机页码
 		select {
-		case ac.logCh <- fmt.Sprintf("[%s] 使用随机页码：第 %d 页（共 %d 页）", time.Now().Format("2006-01-02 15:04:05"), pageNum, ac.config.MaxPages):
+		case ac.logCh <- fmt.Sprintf("[%s] 使用随机页码：第 %d 页（共 %d 页）", ac.clock.Now().Format("2006-01-02 15:04:05"), pageNum, config.MaxPages):
 		default:
 		}
 	}
 
 	// 尝试获取任务列表
-	options := map[string]any{
-		"pn":       pageNum,
-		"rn":       20,
-		"clueID":   "",
-		"clueType": ac.config.ClueTypeID,
-		"step":     ac.config.StepID,
-		"subject":  ac.config.SubjectID,
-		"taskType": ac.config.TaskType,
-	}
-
-	// 直接使用配置中的服务器URL和Cookie
-	serverBaseURL := ac.config.ServerBaseURL
-	cookie := ac.config.Cookie
-
-	// 获取任务列表
-	res, err := GetAuditTaskList(options, serverBaseURL, cookie)
+	options := taskListOptions(config, pageNum)
+
+	// 获取任务列表（Cookie 由客户端的 CookieJar 维护）
+	listStart := ac.clock.Now()
+	res, err := ac.client.GetAuditTaskList(options)
+	listElapsed := ac.clock.Now().Sub(listStart)
+	ac.metrics.observeList(listElapsed, err)
+	ac.stats.observeList(listElapsed, res, err)
 	if err != nil {
+		if IsAuthFailure(err) {
+			ac.expireAuth(fmt.Sprintf("获取任务列表时 Cookie 已失效：%v", err))
+			return
+		}
 		ac.setError(fmt.Sprintf("获取任务列表出错：%v", err))
 		return
 	}
 
 	// 检查请求是否成功
+	if IsAuthFailureErrno(res.Errno, res.Errmsg) {
+		ac.expireAuth(fmt.Sprintf("获取任务列表时 Cookie 已失效：%s", res.Errmsg))
+		return
+	}
 	if res.Errno != 0 || res.Data.List == nil {
 		ac.setError(fmt.Sprintf("获取任务列表失败：%s", res.Errmsg))
 		return
 	}
+	ac.archive.observe(config.TaskType, pageNum, res.Data, ac.clock.Now())
+
+	// 根据关键词和发布时间筛选任务，相对时间窗口在每次认领时重新计算
+	ac.mutex.RLock()
+	dispatch := resolveDispatchRange(config, ac.clock.Now(), ac.startedAt)
+	ac.mutex.RUnlock()
 
-	// 根据关键词和发布时间筛选任务
 	var filteredTasks []TaskItem
 	for _, task := range res.Data.List {
-		textToCheck := task.Brief
-		// 首先检查关键词过滤
-		if !filterByKeywords(textToCheck, ac.config.IncludeKeywords, ac.config.ExcludeKeywords) {
-			continue
+		if evaluateTask(task, config, dispatch).Passed {
+			filteredTasks = append(filteredTasks, task)
 		}
-		// 只有生产任务才检查发布时间过滤
-		if ac.config.TaskType == "producetask" {
-			if !filterByDispatchTime(task.DispatchTime, ac.config.StartTime, ac.config.EndTime) {
-				continue
-			}
-		}
-		// 条件满足，添加到筛选结果
-		filteredTasks = append(filteredTasks, task)
 	}
 
+	ac.metrics.observeTasks(len(res.Data.List), len(filteredTasks))
+	ac.stats.observeTasks(len(res.Data.List), len(filteredTasks))
+
 	// 使用非阻塞方式发送日志消息
 	var filterMsg string
-	if ac.config.TaskType == "producetask" && (ac.config.StartTime != "" || ac.config.EndTime != "") {
+	if config.TaskType == "producetask" && dispatch.active() {
 		filterMsg = "（关键词+时间筛选）"
 	} else {
 		filterMsg = "（关键词筛选）"
 	}
 	select {
-	case ac.logCh <- fmt.Sprintf("[%s] 已筛选任务：%d/%d %s", time.Now().Format("2006-01-02 15:04:05"), len(filteredTasks), len(res.Data.List), filterMsg):
+	case ac.logCh <- fmt.Sprintf("[%s] 已筛选任务：%d/%d %s", ac.clock.Now().Format("2006-01-02 15:04:05"), len(filteredTasks), len(res.Data.List), filterMsg):
 		// 消息已发送到通道
 	default:
 		// 通道已满，但我们不想阻塞，所以忽略
 	}
 
+	// 模拟运行只记录筛选结果，不认领
+	if config.DryRun {
+		ac.recordDryRun(len(res.Data.List), filteredTasks)
+		return
+	}
+
 	// 检查是否有任务可认领
 	if len(filteredTasks) == 0 {
 		ac.setError("线索池中没任务")
@@ -400,17 +533,19 @@ func (ac *AutoClaimer) performAutoClaiming(ctx context.Context) {
 
 	// 根据任务类型提取任务 ID
 	var taskIDs []string
+	tasksByID := make(map[string]TaskItem, len(filteredTasks))
 	for _, task := range filteredTasks {
-		if ac.config.TaskType == "producetask" {
-			taskIDs = append(taskIDs, strconv.Itoa(task.ClueID))
-		} else {
-			taskIDs = append(taskIDs, strconv.Itoa(task.TaskID))
+		id := strconv.Itoa(task.TaskID)
+		if config.TaskType == "producetask" {
+			id = strconv.Itoa(task.ClueID)
 		}
+		taskIDs = append(taskIDs, id)
+		tasksByID[id] = task
 	}
 
 	// 使用非阻塞方式发送日志消息
 	select {
-	case ac.logCh <- fmt.Sprintf("[%s] 尝试并发认领 %d 个任务（并发数：%d）", time.Now().Format("2006-01-02 15:04:05"), len(taskIDs), ac.config.ConcurrentClaims):
+	case ac.logCh <- fmt.Sprintf("[%s] 尝试并发认领 %d 个任务（并发数：%d）", ac.clock.Now().Format("2006-01-02 15:04:05"), len(taskIDs), config.ConcurrentClaims):
 		// 消息已发送到通道
 	default:
 		// 通道已满，但我们不想阻塞，所以忽略
@@ -421,6 +556,8 @@ func (ac *AutoClaimer) performAutoClaiming(ctx context.Context) {
 	var mu sync.Mutex
 	var lastClaimRes *ClaimResponse
 	var lastErr error
+	var authFailure string
+	var claimedTasks []TaskItem
 	successCount := 0
 
 	// 创建任务通道用于并发处理
@@ -431,7 +568,7 @@ func (ac *AutoClaimer) performAutoClaiming(ctx context.Context) {
 	close(taskChan)
 
 	// 启动并发工作池
-	concurrentClaims := min(ac.config.ConcurrentClaims, len(taskIDs))
+	concurrentClaims := min(config.ConcurrentClaims, len(taskIDs))
 
 	for range concurrentClaims {
 		wg.Add(1)
@@ -440,18 +577,29 @@ func (ac *AutoClaimer) performAutoClaiming(ctx context.Context) {
 
 			for taskID := range taskChan {
 				// 认领单个任务
-				claimRes, err := ClaimAuditTask([]string{taskID}, ac.config.TaskType, serverBaseURL, cookie)
+				claimStart := ac.clock.Now()
+				claimRes, err := ac.client.ClaimAuditTask([]string{taskID}, config.TaskType)
+				claimElapsed := ac.clock.Now().Sub(claimStart)
 
 				mu.Lock()
 				if err != nil {
+					ac.metrics.observeClaim(claimElapsed, nil, err, 0)
+					ac.stats.observeClaim(claimElapsed, nil, err, 0)
 					lastErr = err
+					if IsAuthFailure(err) {
+						authFailure = err.Error()
+					}
 					mu.Unlock()
 					continue
 				}
 
 				lastClaimRes = claimRes
+				if IsAuthFailureErrno(claimRes.Errno, claimRes.Errmsg) {
+					authFailure = claimRes.Errmsg
+				}
 
 				// 检查认领是否成功
+				claimed := 0
 				if claimRes.Errno == 0 {
 					// 尝试提取成功认领的任务数
 					taskSuccessCount := 0
@@ -467,7 +615,13 @@ func (ac *AutoClaimer) performAutoClaiming(ctx context.Context) {
 					}
 
 					successCount += taskSuccessCount
+					claimed = taskSuccessCount
+					if taskSuccessCount > 0 {
+						claimedTasks = append(claimedTasks, tasksByID[taskID])
+					}
 				}
+				ac.metrics.observeClaim(claimElapsed, claimRes, nil, claimed)
+				ac.stats.observeClaim(claimElapsed, claimRes, nil, claimed)
 				mu.Unlock()
 			}
 		}()
@@ -476,6 +630,11 @@ func (ac *AutoClaimer) performAutoClaiming(ctx context.Context) {
 	// 等待所有并发任务完成
 	wg.Wait()
 
+	if authFailure != "" && successCount == 0 {
+		ac.expireAuth(fmt.Sprintf("认领任务时 Cookie 已失效：%s", authFailure))
+		return
+	}
+
 	// 如果所有任务都失败了，设置错误
 	if lastErr != nil && successCount == 0 {
 		ac.setError(fmt.Sprintf("认领任务出错：%v", lastErr))
@@ -489,8 +648,9 @@ func (ac *AutoClaimer) performAutoClaiming(ctx context.Context) {
 		return
 	}
 
-	// 用认领响应更新状态
+	// 用认领响应更新状态，上限以授权复核后的最新值为准
 	ac.mutex.Lock()
+	claimLimit := ac.config.ClaimLimit
 	ac.status.LastResponse = claimRes
 
 	// 更新认领计数（successCount 已在循环中计算）
@@ -501,10 +661,10 @@ func (ac *AutoClaimer) performAutoClaiming(ctx context.Context) {
 	// 使用非阻塞方式发送日志消息，包含认领的任务ID
 	idsStr := strings.Join(taskIDs, ", ")
 	var logMessage string
-	if ac.config.TaskType == "producetask" {
-		logMessage = fmt.Sprintf("[%s] 并发认领完成：成功认领 %d 个任务（并发数：%d），ClueID: [%s]，总计：%d/%d", time.Now().Format("2006-01-02 15:04:05"), successCount, ac.config.ConcurrentClaims, idsStr, ac.actualClaims, ac.config.ClaimLimit)
+	if config.TaskType == "producetask" {
+		logMessage = fmt.Sprintf("[%s] 并发认领完成：成功认领 %d 个任务（并发数：%d），ClueID: [%s]，总计：%d/%d", ac.clock.Now().Format("2006-01-02 15:04:05"), successCount, config.ConcurrentClaims, idsStr, ac.actualClaims, claimLimit)
 	} else {
-		logMessage = fmt.Sprintf("[%s] 并发认领完成：成功认领 %d 个任务（并发数：%d），TaskID: [%s]，总计：%d/%d", time.Now().Format("2006-01-02 15:04:05"), successCount, ac.config.ConcurrentClaims, idsStr, ac.actualClaims, ac.config.ClaimLimit)
+		logMessage = fmt.Sprintf("[%s] 并发认领完成：成功认领 %d 个任务（并发数：%d），TaskID: [%s]，总计：%d/%d", ac.clock.Now().Format("2006-01-02 15:04:05"), successCount, config.ConcurrentClaims, idsStr, ac.actualClaims, claimLimit)
 	}
 	select {
 	case ac.logCh <- logMessage:
@@ -514,22 +674,20 @@ func (ac *AutoClaimer) performAutoClaiming(ctx context.Context) {
 	}
 
 	// Check if we've reached the claim limit
-	if ac.actualClaims >= ac.config.ClaimLimit {
-		// 使用非阻塞方式发送日志消息
-		select {
-		case ac.logCh <- fmt.Sprintf("[%s] 认领限制已达到（%d/%d），停止自动认领", time.Now().Format("2006-01-02 15:04:05"), ac.actualClaims, ac.config.ClaimLimit):
-			// 消息已发送到通道
-		default:
-			// 通道已满，但我们不想阻塞，所以忽略
-		}
-		ac.status.IsActive = false
-		if ac.cancel != nil {
-			ac.cancel()
-			ac.cancel = nil
-		}
+	limitReached := ac.actualClaims >= claimLimit
+	totalClaims := ac.actualClaims
+	ac.mutex.Unlock()
+
+	for i := range claimedTasks {
+		ac.emit(ClaimEvent{Type: EventTaskClaimed, Message: fmt.Sprintf("已认领任务：%s", claimedTasks[i].Brief), Task: &claimedTasks[i]})
+	}
+	if successCount > 0 {
+		ac.emit(ClaimEvent{Type: EventBatchClaimed, Message: fmt.Sprintf("本轮成功认领 %d 个任务，总计 %d/%d", successCount, totalClaims, claimLimit)})
 	}
 
-	ac.mutex.Unlock()
+	if limitReached {
+		ac.stop(SessionLimitReached, fmt.Sprintf("认领限制已达到（%d/%d）", totalClaims, claimLimit))
+	}
 }
 
 // setError 更新错误状态
@@ -540,7 +698,7 @@ func (ac *AutoClaimer) setError(errMsg string) {
 	ac.status.LastError = errMsg
 	// 使用非阻塞方式发送日志消息
 	select {
-	case ac.logCh <- fmt.Sprintf("[%s] %s", time.Now().Format("2006-01-02 15:04:05"), errMsg):
+	case ac.logCh <- fmt.Sprintf("[%s] %s", ac.clock.Now().Format("2006-01-02 15:04:05"), errMsg):
 		// 消息已发送到通道
 	default:
 		// 通道已满，但我们不想阻塞，所以忽略
@@ -548,7 +706,7 @@ func (ac *AutoClaimer) setError(errMsg string) {
 }
 
 // StartAutoClaiming 是一个便捷函数，用于创建并启动 AutoClaimer
-func StartAutoClaiming(ctx context.Context, config AutoClaimConfig) (*AutoClaimer, error) {
+func StartAutoClaiming(ctx context.Context, config AutoClaimConfig, opts ...AutoClaimerOption) (*AutoClaimer, error) {
 	// 验证必需参数
 	if config.ServerBaseURL == "" {
 		return nil, fmt.Errorf("server base URL is required")
@@ -558,6 +716,10 @@ func StartAutoClaiming(ctx context.Context, config AutoClaimConfig) (*AutoClaime
 		return nil, fmt.Errorf("cookie is required")
 	}
 
+	if errs := config.Validate(); len(errs) > 0 {
+		return nil, errs
+	}
+
 	if config.Interval < 1 {
 		fmt.Printf("CLI接收到的Interval值为: %.3f秒 (%.0f毫秒)\n", config.Interval, config.Interval*1000)
 	} else {
@@ -565,7 +727,7 @@ func StartAutoClaiming(ctx context.Context, config AutoClaimConfig) (*AutoClaime
 	}
 
 	// 创建自动认领器
-	autoClaimer := NewAutoClaimer(config)
+	autoClaimer := NewAutoClaimer(config, opts...)
 
 	// 启动自动认领过程
 	err := autoClaimer.Start(ctx)
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Clock 提供认领会话使用的当前时间和定时器，测试中可以替换为手动推进的时钟
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Timer // 每隔 d 触发一次
+	NewTimer(d time.Duration) Timer  // d 之后触发一次，d 不大于 0 时立即触发
+}
+
+// Timer 是 Clock 创建的定时器
+type Timer interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Rand 为随机页码提供随机数，会被多个认领尝试并发调用
+type Rand interface {
+	Intn(n int) int // 返回 [0, n) 内的随机数
+}
+
+// realClock 使用系统时间
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTicker(d time.Duration) Timer { return realTicker{time.NewTicker(d)} }
+
+func (realClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+type realTicker struct{ ticker *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.ticker.C }
+func (t realTicker) Stop()               { t.ticker.Stop() }
+
+type realTimer struct{ timer *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.timer.C }
+func (t realTimer) Stop()               { t.timer.Stop() }
+
+// globalRand 使用 math/rand 的全局随机源，可以并发调用
+type globalRand struct{}
+
+func (globalRand) Intn(n int) int { return rand.Intn(n) }
+
+// WithClock 让会话使用 clock 获取时间和创建定时器，包括认领间隔、登录态检查、授权复核和日志时间戳
+func WithClock(clock Clock) AutoClaimerOption {
+	return func(ac *AutoClaimer) {
+		ac.clock = clock
+	}
+}
+
+// WithRand 让会话使用 r 选择随机页码，r 需要支持并发调用
+func WithRand(r Rand) AutoClaimerOption {
+	return func(ac *AutoClaimer) {
+		ac.rand = r
+	}
+}
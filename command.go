@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// CookieImportURL 是导入 Cookie 时用于匹配域名和路径的接口地址
+const CookieImportURL = DefaultServerURL + "/edushop/"
+
+// command 表示一个无界面模式下的子命令
+type command struct {
+	usage string
+	run   func(args []string) int
+}
+
+// commands 是所有可用的无界面子命令
+var commands = map[string]command{
+	"check-auth": {
+		usage: "check-auth -type official|custom|allowlist [-user 用户名] [-cookie Cookie] [-pocketbase 地址]...  验证软件授权",
+		run:   runCheckAuth,
+	},
+	"pool-report": {
+		usage: "pool-report [-type audittask|producetask] [-days 天数] [-json]  根据线索池快照分析任务出现时段和被认领速度",
+		run:   runPoolReport,
+	},
+	"serve": {
+		usage: "serve [-addr 地址] [-token 令牌]  以无界面模式运行 HTTP 控制接口和定时方案",
+		run:   runServe,
+	},
+	"export-history": {
+		usage: "export-history [-format csv|xlsx] [-since 日期] [-until 日期] [-account 账号] [-o 文件]  导出认领记录",
+		run:   runExportHistory,
+	},
+	"import-cookies": {
+		usage: "import-cookies [-url 地址] <文件|->  从浏览器导出文件（cookies.txt/HAR/JSON）生成 Cookie 请求头",
+		run:   runImportCookies,
+	},
+}
+
+// runCommand 执行命令行子命令，未识别的参数交给图形界面处理
+func runCommand(args []string) (int, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printCommandUsage(os.Stdout)
+		return 0, true
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return 0, false
+	}
+	return cmd.run(args[1:]), true
+}
+
+// printCommandUsage 输出所有子命令的用法
+func printCommandUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "用法: bedu-claim <命令> [参数]")
+	fmt.Fprintln(w, "不带命令运行时启动图形界面。可用命令:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\n", commands[name].usage)
+	}
+}
+
+// runImportCookies 解析浏览器导出的 Cookie 文件并输出 Cookie 请求头
+func runImportCookies(args []string) int {
+	fs := flag.NewFlagSet("import-cookies", flag.ContinueOnError)
+	targetURL := fs.String("url", CookieImportURL, "用于匹配 Cookie 域名和路径的地址")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "用法: bedu-claim import-cookies [-url 地址] <文件|->")
+		return 2
+	}
+
+	var data []byte
+	var err error
+	if fs.Arg(0) == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(fs.Arg(0))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "读取文件失败: %v\n", err)
+		return 1
+	}
+
+	result, err := ParseCookieExport(data, *targetURL, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "导入Cookie失败: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "格式: %s，导入 %d 个Cookie，跳过 %d 个\n", result.Format, len(result.Names), result.Skipped)
+	fmt.Println(result.Cookie)
+	return 0
+}
+
+// runCheckAuth 使用配置的授权方式验证授权，便于检查授权服务器配置
+func runCheckAuth(args []string) int {
+	fs := flag.NewFlagSet("check-auth", flag.ContinueOnError)
+	authType := fs.String("type", "official", "授权类型: "+strings.Join(AuthorizerNames(), ", "))
+	username := fs.String("user", "", "官方授权用户名")
+	cookie := fs.String("cookie", "", "百度教育 Cookie（定制授权和白名单授权需要）")
+	timeout := fs.Duration("timeout", 15*time.Second, "验证超时时间")
+	settingsFlags := addSettingsFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if _, err := settingsFlags.load(); err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		return 1
+	}
+
+	authorizer, ok := LookupAuthorizer(*authType)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "未知的授权类型: %s\n", *authType)
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	result, err := authorizer.Authorize(ctx, AutoClaimConfig{
+		ServerBaseURL: DefaultServerURL,
+		Cookie:        *cookie,
+		AuthType:      *authType,
+		AuthUsername:  *username,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "授权验证失败: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("授权验证成功: %s\n", result.UserName)
+	if !result.ExpiresAt.IsZero() {
+		fmt.Printf("过期时间: %s\n", result.ExpiresAt.Local().Format("2006-01-02 15:04:05"))
+	}
+	return 0
+}
+
+// runServe 以无界面模式运行控制接口，收到中断信号时停止会话并退出
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", "", "监听地址，默认使用配置文件中的 controlAddr 或 127.0.0.1:8765")
+	token := fs.String("token", "", "访问令牌，默认使用配置文件中的 controlToken")
+	settingsFlags := addSettingsFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	settings, err := settingsFlags.load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		return 1
+	}
+
+	if *addr == "" {
+		*addr = settings.ControlAddr
+	}
+	if *addr == "" {
+		*addr = "127.0.0.1:8765"
+	}
+	if *token == "" {
+		*token = settings.ControlToken
+	}
+	if len(*token) < minControlTokenLength {
+		fmt.Fprintf(os.Stderr, "请通过 -token、BEDU_CONTROL_TOKEN 或配置文件设置至少 %d 个字符的访问令牌\n", minControlTokenLength)
+		return 2
+	}
+
+	sessions := newDefaultSessionManager(settings)
+	sessions.OnEvent(func(event ClaimEvent) {
+		log.Printf("自动认领事件: %s %s", event.Type, event.Message)
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if schedules, err := DefaultScheduleStore(); err == nil {
+		go NewScheduler(sessions, schedules, nil).Run(ctx)
+	}
+	err = NewControlServer(*addr, *token, sessions).ListenAndServe(ctx)
+	sessions.Stop()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "控制接口启动失败: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runPoolReport 输出线索池快照的趋势报告
+func runPoolReport(args []string) int {
+	fs := flag.NewFlagSet("pool-report", flag.ContinueOnError)
+	taskType := fs.String("type", "audittask", "任务类型: audittask 或 producetask")
+	days := fs.Int("days", 7, "统计最近几天的快照，0 表示全部")
+	asJSON := fs.Bool("json", false, "以 JSON 格式输出")
+	settingsFlags := addSettingsFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	// 按配置的平台时区统计小时
+	if _, err := settingsFlags.load(); err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		return 1
+	}
+
+	report, err := LoadPoolReport(*taskType, *days, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "生成报告失败: %v\n", err)
+		return 1
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "输出报告失败: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	report.Write(os.Stdout)
+	return 0
+}
+
+// runExportHistory 按日期范围和账号导出认领记录，未指定 -o 时写到标准输出
+func runExportHistory(args []string) int {
+	fs := flag.NewFlagSet("export-history", flag.ContinueOnError)
+	format := fs.String("format", "", "导出格式: csv 或 xlsx（默认根据 -o 的扩展名判断，否则为 csv）")
+	since := fs.String("since", "", "开始日期（平台时区），如 2025-01-01 或 \"2025-01-01 08:00:00\"")
+	until := fs.String("until", "", "结束日期，只写日期时包含当天")
+	account := fs.String("account", "", "只导出该百度教育账号的记录")
+	output := fs.String("o", "", "输出文件，默认写到标准输出")
+	settingsFlags := addSettingsFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	// 按配置的平台时区解析 -since 和 -until
+	if _, err := settingsFlags.load(); err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		return 1
+	}
+
+	exportFormat, err := ParseExportFormat(*format, *output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	filter, err := ExportFilter(*since, *until, *account)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	history, err := DefaultClaimHistory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "初始化认领记录失败: %v\n", err)
+		return 1
+	}
+	records, err := history.List(filter)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	f := os.Stdout
+	if *output != "" {
+		if f, err = os.Create(*output); err != nil {
+			fmt.Fprintf(os.Stderr, "创建导出文件失败: %v\n", err)
+			return 1
+		}
+	}
+	err = ExportHistory(f, exportFormat, records)
+	if *output != "" {
+		err = errors.Join(err, f.Close())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "导出认领记录失败: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "已导出 %d 条认领记录\n", len(records))
+	return 0
+}
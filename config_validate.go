@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// minInterval 是认领间隔的下限（秒），与界面允许的最小值 10 毫秒一致。
+// 每次触发都会在新的协程中开始一轮尝试，不等待上一轮的请求结束，同时进行的尝试最多 maxConcurrentFor 轮，
+// 超出时跳过该次触发；间隔短于接口耗时时会有多个列表请求同时进行
+const minInterval = 0.01
+
+// FieldError 表示认领配置中某个字段的错误，Field 为 AutoClaimConfig 中的字段名
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ConfigErrors 是认领配置中的所有字段错误
+type ConfigErrors []FieldError
+
+func (e ConfigErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Message
+	}
+	return "配置无效：" + strings.Join(messages, "；")
+}
+
+// Validate 检查认领配置，返回所有字段错误，配置有效时返回 nil。
+// 零值表示使用默认值（见 NewAutoClaimer），不视为错误；Cookie 和服务器地址由启动方负责
+func (c AutoClaimConfig) Validate() ConfigErrors {
+	var errs ConfigErrors
+	add := func(field, format string, args ...any) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch c.TaskType {
+	case "", "audittask", "producetask":
+	default:
+		add("TaskType", "未知的任务类型 %q，可选 audittask 或 producetask", c.TaskType)
+	}
+
+	if c.Interval != 0 && c.Interval < minInterval {
+		add("Interval", "认领间隔不能小于 %g 秒", minInterval)
+	}
+	if c.ClaimLimit < 0 {
+		add("ClaimLimit", "认领上限不能为负数")
+	}
+	if c.ConcurrentClaims < 0 {
+		add("ConcurrentClaims", "并发数不能为负数")
+	}
+	if c.MaxPages < 0 {
+		add("MaxPages", "随机页码上限不能为负数")
+	}
+
+	for i, keyword := range c.IncludeKeywords {
+		if strings.TrimSpace(keyword) == "" {
+			add("IncludeKeywords", "第 %d 个包含关键词为空", i+1)
+		}
+	}
+	for i, keyword := range c.ExcludeKeywords {
+		if strings.TrimSpace(keyword) == "" {
+			add("ExcludeKeywords", "第 %d 个排除关键词为空", i+1)
+		}
+	}
+
+	start, startErr := ParsePlatformTime(c.StartTime)
+	if startErr != nil {
+		add("StartTime", "无效的开始时间 %q，格式为 2006-01-02 15:04:05", c.StartTime)
+	}
+	end, endErr := ParsePlatformTime(c.EndTime)
+	if endErr != nil {
+		add("EndTime", "无效的结束时间 %q，格式为 2006-01-02 15:04:05", c.EndTime)
+	}
+	if startErr == nil && endErr == nil && !start.IsZero() && !end.IsZero() && end.Before(start.Time) {
+		add("EndTime", "结束时间不能早于开始时间")
+	}
+	if err := validateDispatchWindow(c.DispatchWindow); err != nil {
+		add("DispatchWindow", "%v", err)
+	}
+
+	if c.AuthCheckInterval < 0 {
+		add("AuthCheckInterval", "登录态检查间隔不能为负数")
+	}
+	if c.LicenseCheckInterval < 0 {
+		add("LicenseCheckInterval", "授权复核间隔不能为负数")
+	}
+	return errs
+}
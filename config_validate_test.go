@@ -0,0 +1,66 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateZeroValueUsesDefaults(t *testing.T) {
+	if errs := (AutoClaimConfig{}).Validate(); errs != nil {
+		t.Errorf("零值配置应表示使用默认值，得到 %v", errs)
+	}
+}
+
+func TestValidateFieldErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config AutoClaimConfig
+		fields []string // 期望出错的字段，为空表示配置有效
+	}{
+		{"有效配置", AutoClaimConfig{TaskType: "producetask", Interval: 0.5, ClaimLimit: 5, IncludeKeywords: []string{"数学"}}, nil},
+		{"未知任务类型", AutoClaimConfig{TaskType: "reviewtask"}, []string{"TaskType"}},
+		{"间隔低于下限", AutoClaimConfig{Interval: 0.001}, []string{"Interval"}},
+		{"间隔为负数", AutoClaimConfig{Interval: -1}, []string{"Interval"}},
+		{"间隔等于下限", AutoClaimConfig{Interval: minInterval}, nil},
+		{"认领上限为负数", AutoClaimConfig{ClaimLimit: -1}, []string{"ClaimLimit"}},
+		{"并发数为负数", AutoClaimConfig{ConcurrentClaims: -2}, []string{"ConcurrentClaims"}},
+		{"页码上限为负数", AutoClaimConfig{MaxPages: -1}, []string{"MaxPages"}},
+		{"空包含关键词", AutoClaimConfig{IncludeKeywords: []string{"数学", " "}}, []string{"IncludeKeywords"}},
+		{"空排除关键词", AutoClaimConfig{ExcludeKeywords: []string{""}}, []string{"ExcludeKeywords"}},
+		{"无效的开始时间", AutoClaimConfig{StartTime: "明天"}, []string{"StartTime"}},
+		{"无效的结束时间", AutoClaimConfig{EndTime: "2025-13-01"}, []string{"EndTime"}},
+		{"结束早于开始", AutoClaimConfig{StartTime: "2025-01-07 12:00:00", EndTime: "2025-01-07 08:00:00"}, []string{"EndTime"}},
+		{"结束等于开始", AutoClaimConfig{StartTime: "2025-01-07 12:00:00", EndTime: "2025-01-07 12:00:00"}, nil},
+		{"只有结束时间", AutoClaimConfig{EndTime: "2025-01-07"}, nil},
+		{"有效的相对时间窗口", AutoClaimConfig{DispatchWindow: "last:30m"}, nil},
+		{"无效的相对时间窗口", AutoClaimConfig{DispatchWindow: "last:-5m"}, []string{"DispatchWindow"}},
+		{"未知的相对时间窗口", AutoClaimConfig{DispatchWindow: "yesterday"}, []string{"DispatchWindow"}},
+		{"登录态检查间隔为负数", AutoClaimConfig{AuthCheckInterval: -1}, []string{"AuthCheckInterval"}},
+		{"授权复核间隔为负数", AutoClaimConfig{LicenseCheckInterval: -1}, []string{"LicenseCheckInterval"}},
+		{"多个错误一起返回", AutoClaimConfig{TaskType: "x", ClaimLimit: -1, StartTime: "bad"}, []string{"TaskType", "ClaimLimit", "StartTime"}},
+	}
+	for _, tt := range tests {
+		errs := tt.config.Validate()
+		var fields []string
+		for _, fieldErr := range errs {
+			fields = append(fields, fieldErr.Field)
+			if fieldErr.Message == "" {
+				t.Errorf("%s: %s 缺少错误说明", tt.name, fieldErr.Field)
+			}
+		}
+		if !slices.Equal(fields, tt.fields) {
+			t.Errorf("%s: 出错字段 = %v，期望 %v", tt.name, fields, tt.fields)
+		}
+		if len(tt.fields) == 0 && errs != nil {
+			t.Errorf("%s: 有效配置应返回 nil", tt.name)
+		}
+	}
+}
+
+func TestConfigErrorsMessage(t *testing.T) {
+	errs := AutoClaimConfig{ClaimLimit: -1, MaxPages: -1}.Validate()
+	if msg := errs.Error(); !strings.HasPrefix(msg, "配置无效：") || !strings.Contains(msg, "；") {
+		t.Errorf("错误信息 = %q", msg)
+	}
+}
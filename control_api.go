@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minControlTokenLength 是控制接口访问令牌的最小长度
+const minControlTokenLength = 16
+
+// ControlServer 是可选的 HTTP 控制接口，用于在脚本或局域网内的其他机器上控制认领会话。
+// 所有请求都需要携带 "Authorization: Bearer <令牌>" 请求头
+type ControlServer struct {
+	addr      string
+	token     string
+	sessions  *SessionManager
+	serverURL string
+	ctx       context.Context // 会话的生命周期跟随服务，而不是单个请求
+}
+
+// NewControlServer 创建监听 addr 的控制接口
+func NewControlServer(addr, token string, sessions *SessionManager) *ControlServer {
+	return &ControlServer{addr: addr, token: token, sessions: sessions, serverURL: DefaultServerURL, ctx: context.Background()}
+}
+
+// ListenAndServe 启动控制接口，ctx 结束时关闭服务并返回
+func (s *ControlServer) ListenAndServe(ctx context.Context) error {
+	if len(s.token) < minControlTokenLength {
+		return fmt.Errorf("控制接口访问令牌至少需要 %d 个字符", minControlTokenLength)
+	}
+	s.ctx = ctx
+
+	server := &http.Server{
+		Addr:              s.addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("控制接口已启动: http://%s", s.addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Handler 返回控制接口的路由
+func (s *ControlServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/start", s.handleStart)
+	mux.HandleFunc("POST /api/stop", s.handleStop)
+	mux.HandleFunc("GET /api/status", s.handleStatus)
+	mux.HandleFunc("GET /api/history", s.handleHistory)
+	mux.HandleFunc("GET /api/labels", s.handleLabels)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	return s.authenticate(mux)
+}
+
+// authenticate 校验 Bearer 令牌
+func (s *ControlServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="bedu-claim"`)
+			writeJSON(w, http.StatusUnauthorized, AutoClaimResponse{Success: false, Message: "访问令牌无效"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleStart 使用请求体中的配置启动会话，未提供 Cookie 时使用保存的 Cookie
+func (s *ControlServer) handleStart(w http.ResponseWriter, r *http.Request) {
+	var config AutoClaimConfig
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&config); err != nil {
+		writeJSON(w, http.StatusBadRequest, AutoClaimResponse{Success: false, Message: fmt.Sprintf("解析配置失败: %v", err)})
+		return
+	}
+	config.ServerBaseURL = s.serverURL
+	if config.Cookie == "" {
+		config.Cookie = s.sessions.SavedCookie()
+	}
+
+	message, err := s.sessions.Start(s.ctx, config)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, ErrSessionRunning):
+			status = http.StatusConflict
+		case IsAuthDenied(err):
+			status = http.StatusForbidden
+		}
+		writeJSON(w, status, newErrorResponse(err))
+		return
+	}
+	writeJSON(w, http.StatusOK, AutoClaimResponse{Success: true, Message: message, TaskID: s.sessions.SessionID()})
+}
+
+// handleStop 停止当前会话
+func (s *ControlServer) handleStop(w http.ResponseWriter, r *http.Request) {
+	if err := s.sessions.Stop(); err != nil {
+		writeJSON(w, http.StatusConflict, AutoClaimResponse{Success: false, Message: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, AutoClaimResponse{Success: true, Message: "自动认领已停止"})
+}
+
+// handleStatus 返回当前会话状态
+func (s *ControlServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, newAutoClaimStatusResponse(s.sessions))
+}
+
+// historyResponse 是认领记录查询的响应
+type historyResponse struct {
+	Success bool          `json:"success"`
+	Message string        `json:"message,omitempty"`
+	Records []ClaimRecord `json:"records"`
+}
+
+// handleHistory 查询认领记录，支持 since、until（RFC3339 或按平台时区解析的 "2006-01-02 15:04:05"）、account、session 和 limit 参数
+func (s *ControlServer) handleHistory(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := HistoryFilter{
+		Account:   query.Get("account"),
+		SessionID: query.Get("session"),
+	}
+
+	var err error
+	if filter.Since, err = parseQueryTime(query.Get("since")); err != nil {
+		writeJSON(w, http.StatusBadRequest, historyResponse{Message: err.Error()})
+		return
+	}
+	if filter.Until, err = parseQueryTime(query.Get("until")); err != nil {
+		writeJSON(w, http.StatusBadRequest, historyResponse{Message: err.Error()})
+		return
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 0 {
+			writeJSON(w, http.StatusBadRequest, historyResponse{Message: "无效的 limit 参数"})
+			return
+		}
+	}
+
+	records, err := s.sessions.History(filter)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, historyResponse{Message: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, historyResponse{Success: true, Records: records})
+}
+
+// handleLabels 使用当前 Cookie 获取任务筛选标签
+func (s *ControlServer) handleLabels(w http.ResponseWriter, r *http.Request) {
+	taskType := r.URL.Query().Get("taskType")
+	if taskType == "" {
+		taskType = "audittask"
+	}
+	cookie := s.sessions.Cookie()
+	if cookie == "" {
+		writeJSON(w, http.StatusConflict, AutoClaimResponse{Success: false, Message: "没有可用的 Cookie"})
+		return
+	}
+
+	labels, err := GetAuditTaskLabel(taskType, s.serverURL, cookie)
+	if err != nil {
+		status := http.StatusBadGateway
+		if IsAuthFailure(err) {
+			status = http.StatusUnauthorized
+		}
+		writeJSON(w, status, AutoClaimResponse{Success: false, Message: fmt.Sprintf("获取任务标签失败: %v", err)})
+		return
+	}
+	if IsAuthFailureErrno(labels.Errno, labels.Errmsg) {
+		writeJSON(w, http.StatusUnauthorized, AutoClaimResponse{Success: false, Message: fmt.Sprintf("获取任务标签失败: %s", labels.Errmsg)})
+		return
+	}
+	writeJSON(w, http.StatusOK, labels)
+}
+
+// handleMetrics 以 Prometheus 文本格式输出统计数据
+func (s *ControlServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.sessions.Metrics().WritePrometheus(w)
+}
+
+// parseQueryTime 解析查询参数中的时间，没有时区的时间按平台时区处理
+func parseQueryTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := ParsePlatformTime(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("无效的时间: %s", value)
+	}
+	return t.Time, nil
+}
+
+// writeJSON 以 JSON 格式写入响应
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("写入响应失败: %v", err)
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testControlToken = "control-token-0123456789"
+
+// controlFixture 是连接替身平台的控制接口
+type controlFixture struct {
+	fake     *fakeEasylearn
+	auth     *stubAuthorizer
+	sessions *SessionManager
+	history  *ClaimHistory
+	server   *httptest.Server
+}
+
+func newControlFixture(t *testing.T) *controlFixture {
+	t.Helper()
+
+	f := &controlFixture{fake: newFakeEasylearn(t), auth: &stubAuthorizer{}}
+	RegisterAuthorizer(t.Name(), f.auth)
+
+	dir := t.TempDir()
+	f.history = NewClaimHistory(filepath.Join(dir, "history.jsonl"))
+	f.sessions = NewSessionManager(NewCredentialStore(filepath.Join(dir, "credentials.json")), f.history)
+	t.Cleanup(func() { f.sessions.Stop() })
+
+	control := NewControlServer("127.0.0.1:0", testControlToken, f.sessions)
+	control.serverURL = f.fake.URL
+	f.server = httptest.NewServer(control.Handler())
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// do 携带有效令牌发送请求，返回状态码和响应体
+func (f *controlFixture) do(t *testing.T, method, path, body string) (int, []byte) {
+	t.Helper()
+	return f.doWithAuth(t, method, path, body, "Bearer "+testControlToken)
+}
+
+func (f *controlFixture) doWithAuth(t *testing.T, method, path, body, authorization string) (int, []byte) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, f.server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, data
+}
+
+// startBody 返回使用替身授权的启动请求体
+func (f *controlFixture) startBody(t *testing.T) string {
+	return `{"TaskType":"audittask","Interval":60,"AuthType":"` + t.Name() + `"}`
+}
+
+func TestControlServerRejectsInvalidToken(t *testing.T) {
+	f := newControlFixture(t)
+
+	for _, authorization := range []string{"", "Bearer", "Bearer wrong-token-0123456789", "Basic " + testControlToken, "bearer " + testControlToken} {
+		for _, path := range []string{"/api/status", "/api/history", "/metrics"} {
+			req, err := http.NewRequest(http.MethodGet, f.server.URL+path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if authorization != "" {
+				req.Header.Set("Authorization", authorization)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("WWW-Authenticate") == "" {
+				t.Errorf("Authorization %q 访问 %s 的状态码 = %d", authorization, path, resp.StatusCode)
+			}
+		}
+	}
+
+	// 未认证的请求不能启动会话
+	if status, _ := f.doWithAuth(t, http.MethodPost, "/api/start", f.startBody(t), ""); status != http.StatusUnauthorized {
+		t.Errorf("未认证启动的状态码 = %d", status)
+	}
+	if _, ok := f.sessions.Status(); ok {
+		t.Error("未认证的请求启动了会话")
+	}
+}
+
+func TestControlServerSessionLifecycle(t *testing.T) {
+	f := newControlFixture(t)
+
+	if status, _ := f.do(t, http.MethodPost, "/api/stop", ""); status != http.StatusConflict {
+		t.Errorf("没有会话时停止的状态码 = %d", status)
+	}
+	status, body := f.do(t, http.MethodGet, "/api/status", "")
+	var idle AutoClaimStatusResponse
+	if err := json.Unmarshal(body, &idle); err != nil || status != http.StatusOK || idle.IsActive || idle.State != string(SessionIdle) {
+		t.Errorf("没有会话时的状态 = %d %s", status, body)
+	}
+
+	if status, _ := f.do(t, http.MethodPost, "/api/start", "{"); status != http.StatusBadRequest {
+		t.Errorf("无效 JSON 的状态码 = %d", status)
+	}
+	// 没有保存的 Cookie 时配置无效
+	status, body = f.do(t, http.MethodPost, "/api/start", f.startBody(t))
+	var invalid AutoClaimResponse
+	if err := json.Unmarshal(body, &invalid); err != nil || status != http.StatusBadRequest || invalid.Success {
+		t.Errorf("缺少 Cookie 时的响应 = %d %s", status, body)
+	}
+
+	// 请求体未提供 Cookie 时使用保存的 Cookie
+	f.sessions.saveCookie(fakeCookie)
+	status, body = f.do(t, http.MethodPost, "/api/start", f.startBody(t))
+	var started AutoClaimResponse
+	if err := json.Unmarshal(body, &started); err != nil || status != http.StatusOK || !started.Success || started.TaskID != f.sessions.SessionID() {
+		t.Fatalf("启动的响应 = %d %s", status, body)
+	}
+	if status, _ := f.do(t, http.MethodPost, "/api/start", f.startBody(t)); status != http.StatusConflict {
+		t.Errorf("会话运行中再次启动的状态码 = %d", status)
+	}
+
+	status, body = f.do(t, http.MethodGet, "/api/status", "")
+	var active AutoClaimStatusResponse
+	if err := json.Unmarshal(body, &active); err != nil || status != http.StatusOK || !active.IsActive {
+		t.Errorf("运行中的状态 = %d %s", status, body)
+	}
+
+	if status, _ := f.do(t, http.MethodPost, "/api/stop", ""); status != http.StatusOK {
+		t.Errorf("停止的状态码 = %d", status)
+	}
+	waitFor(t, "会话停止", func() bool {
+		status, ok := f.sessions.Status()
+		return ok && !status.IsActive
+	})
+
+	// 授权被拒绝
+	f.auth.fail(authDenied("授权已过期"))
+	if status, body := f.do(t, http.MethodPost, "/api/start", f.startBody(t)); status != http.StatusForbidden {
+		t.Errorf("授权被拒绝时的状态 = %d %s", status, body)
+	}
+}
+
+func TestControlServerHistory(t *testing.T) {
+	f := newControlFixture(t)
+
+	for _, record := range []ClaimRecord{
+		{TaskID: 1, Account: "alice", SessionID: "s1", ClaimedAt: mustPlatformTime(t, "2025-01-06 23:59:59").Time},
+		{TaskID: 2, Account: "alice", SessionID: "s1", ClaimedAt: mustPlatformTime(t, "2025-01-07 00:30:00").Time},
+		{TaskID: 3, Account: "bob", SessionID: "s2", ClaimedAt: mustPlatformTime(t, "2025-01-07 12:00:00").Time},
+		{TaskID: 4, Account: "alice", SessionID: "s2", ClaimedAt: mustPlatformTime(t, "2025-01-08 00:00:00").Time},
+	} {
+		if err := f.history.Append(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"", []int{1, 2, 3, 4}},
+		// 不带时区的时间按平台时区解析，与本机时区无关
+		{"?since=2025-01-07&until=2025-01-08", []int{2, 3}},
+		{"?since=2025-01-07+12:00:00", []int{3, 4}},
+		{"?until=2025-01-07T00:00:00%2B08:00", []int{1}},
+		{"?since=2025-01-06T16:30:00Z", []int{2, 3, 4}},
+		{"?account=alice", []int{1, 2, 4}},
+		{"?session=s2", []int{3, 4}},
+		{"?account=alice&limit=2", []int{2, 4}},
+	}
+	for _, tt := range tests {
+		status, body := f.do(t, http.MethodGet, "/api/history"+tt.query, "")
+		var response historyResponse
+		if err := json.Unmarshal(body, &response); err != nil || status != http.StatusOK || !response.Success {
+			t.Errorf("%q 的响应 = %d %s", tt.query, status, body)
+			continue
+		}
+		var ids []int
+		for _, record := range response.Records {
+			ids = append(ids, record.TaskID)
+		}
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("%q 返回的任务 = %v，期望 %v", tt.query, ids, tt.want)
+		}
+	}
+
+	for _, query := range []string{"?since=昨天", "?until=2025/01/07", "?limit=-1", "?limit=x"} {
+		if status, _ := f.do(t, http.MethodGet, "/api/history"+query, ""); status != http.StatusBadRequest {
+			t.Errorf("%q 的状态码 = %d", query, status)
+		}
+	}
+}
+
+func TestControlServerLabels(t *testing.T) {
+	f := newControlFixture(t)
+
+	if status, _ := f.do(t, http.MethodGet, "/api/labels", ""); status != http.StatusConflict {
+		t.Errorf("没有 Cookie 时的状态码 = %d", status)
+	}
+
+	f.sessions.saveCookie(fakeCookie)
+	status, body := f.do(t, http.MethodGet, "/api/labels?taskType=producetask", "")
+	var labels LabelResponse
+	if err := json.Unmarshal(body, &labels); err != nil || status != http.StatusOK || len(labels.Data.Filter) != 1 || labels.Data.Filter[0].ID != "step" {
+		t.Errorf("标签响应 = %d %s", status, body)
+	}
+
+	f.fake.logout()
+	if status, body := f.do(t, http.MethodGet, "/api/labels", ""); status != http.StatusUnauthorized {
+		t.Errorf("登录失效时的状态 = %d %s", status, body)
+	}
+}
+
+func TestControlServerMetrics(t *testing.T) {
+	f := newControlFixture(t)
+	f.sessions.Metrics().observeList(time.Millisecond, nil)
+
+	req, err := http.NewRequest(http.MethodGet, f.server.URL+"/metrics", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testControlToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("状态码 = %d，Content-Type = %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(string(body), "bedu_claim_list_requests_total 1\n") {
+		t.Errorf("指标输出缺少列表请求次数:\n%s", string(body))
+	}
+}
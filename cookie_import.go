@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CookieImportResult 表示从浏览器导出文件中解析出的 Cookie
+type CookieImportResult struct {
+	Format  string   `json:"format"`  // 识别出的导出格式：netscape、har 或 json
+	Cookie  string   `json:"cookie"`  // 可直接使用的 Cookie 请求头
+	Names   []string `json:"names"`   // 导入的 Cookie 名称
+	Skipped int      `json:"skipped"` // 因域名、路径不匹配或已过期而跳过的数量
+}
+
+// importedCookie 是各导出格式统一后的 Cookie 表示
+type importedCookie struct {
+	Name     string
+	Value    string
+	Domain   string
+	Path     string
+	HostOnly bool
+	Expires  time.Time // 零值表示会话 Cookie
+}
+
+// jsonExportCookie 兼容 EditThisCookie、Cookie-Editor 等扩展导出的 JSON 字段
+type jsonExportCookie struct {
+	Name           string          `json:"name"`
+	Value          string          `json:"value"`
+	Domain         string          `json:"domain"`
+	Path           string          `json:"path"`
+	HostOnly       bool            `json:"hostOnly"`
+	Session        bool            `json:"session"`
+	ExpirationDate float64         `json:"expirationDate"`
+	Expires        json.RawMessage `json:"expires"`
+}
+
+// harCookie 表示 HAR 文件中请求或响应携带的 Cookie
+type harCookie struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Domain  string `json:"domain"`
+	Path    string `json:"path"`
+	Expires string `json:"expires"`
+}
+
+// harFile 只包含解析 Cookie 需要的 HAR 字段
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				URL     string      `json:"url"`
+				Cookies []harCookie `json:"cookies"`
+			} `json:"request"`
+			Response struct {
+				Cookies []harCookie `json:"cookies"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// ParseCookieExport 解析 Netscape cookies.txt、HAR 或扩展导出的 JSON，
+// 返回对 targetURL 有效且未过期的 Cookie 请求头
+func ParseCookieExport(data []byte, targetURL string, now time.Time) (*CookieImportResult, error) {
+	target, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, fmt.Errorf("无效的目标地址: %v", err)
+	}
+
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\ufeff")))
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("导入内容为空")
+	}
+
+	var format string
+	var cookies []importedCookie
+	switch trimmed[0] {
+	case '{', '[':
+		format, cookies, err = parseJSONCookies(trimmed)
+	default:
+		format = "netscape"
+		cookies, err = parseNetscapeCookies(trimmed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CookieImportResult{Format: format}
+	values := map[string]string{}
+	for _, c := range cookies {
+		if c.Name == "" || !cookieMatchesURL(c, target) || (!c.Expires.IsZero() && c.Expires.Before(now)) {
+			result.Skipped++
+			continue
+		}
+		if _, exists := values[c.Name]; !exists {
+			result.Names = append(result.Names, c.Name)
+		}
+		// 后出现的同名 Cookie 覆盖之前的值（HAR 中响应的 Set-Cookie 晚于请求）
+		values[c.Name] = c.Value
+	}
+
+	if len(result.Names) == 0 {
+		return nil, fmt.Errorf("未找到 %s 的有效Cookie", target.Host)
+	}
+
+	pairs := make([]string, 0, len(result.Names))
+	for _, name := range result.Names {
+		pairs = append(pairs, name+"="+values[name])
+	}
+	result.Cookie = strings.Join(pairs, "; ")
+	return result, nil
+}
+
+// parseNetscapeCookies 解析 curl/wget 使用的 Netscape cookies.txt 格式
+func parseNetscapeCookies(data []byte) ([]importedCookie, error) {
+	var cookies []importedCookie
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		// "#HttpOnly_" 前缀表示 HttpOnly Cookie，其余 # 开头的行是注释
+		line = strings.TrimPrefix(line, "#HttpOnly_")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < 7 {
+			return nil, fmt.Errorf("第 %d 行不是有效的 cookies.txt 格式", lineNum)
+		}
+
+		c := importedCookie{
+			Domain:   fields[0],
+			HostOnly: !strings.EqualFold(fields[1], "TRUE"),
+			Path:     fields[2],
+			Name:     fields[5],
+			Value:    strings.Join(fields[6:], "\t"),
+		}
+		if expires, err := strconv.ParseInt(fields[4], 10, 64); err == nil && expires > 0 {
+			c.Expires = time.Unix(expires, 0)
+		}
+		cookies = append(cookies, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cookies, nil
+}
+
+// parseJSONCookies 解析 HAR 文件或扩展导出的 JSON 数组
+func parseJSONCookies(data []byte) (string, []importedCookie, error) {
+	if data[0] == '{' {
+		var probe map[string]json.RawMessage
+		if err := json.Unmarshal(data, &probe); err != nil {
+			return "", nil, fmt.Errorf("解析JSON失败: %v", err)
+		}
+		if _, ok := probe["log"]; ok {
+			cookies, err := parseHARCookies(data)
+			return "har", cookies, err
+		}
+		// 部分扩展导出 {"url": "...", "cookies": [...]}
+		raw, ok := probe["cookies"]
+		if !ok {
+			return "", nil, fmt.Errorf("无法识别的JSON格式")
+		}
+		data = raw
+	}
+
+	var exported []jsonExportCookie
+	if err := json.Unmarshal(data, &exported); err != nil {
+		return "", nil, fmt.Errorf("解析JSON失败: %v", err)
+	}
+
+	cookies := make([]importedCookie, 0, len(exported))
+	for _, e := range exported {
+		c := importedCookie{
+			Name:     e.Name,
+			Value:    e.Value,
+			Domain:   e.Domain,
+			Path:     e.Path,
+			HostOnly: e.HostOnly,
+		}
+		if !e.Session {
+			if e.ExpirationDate > 0 {
+				c.Expires = time.Unix(0, int64(e.ExpirationDate*float64(time.Second)))
+			} else {
+				c.Expires = parseExportExpires(e.Expires)
+			}
+		}
+		cookies = append(cookies, c)
+	}
+	return "json", cookies, nil
+}
+
+// parseHARCookies 提取 HAR 中请求和响应携带的 Cookie，缺少域名时使用请求的主机名
+func parseHARCookies(data []byte) ([]importedCookie, error) {
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("解析HAR失败: %v", err)
+	}
+
+	var cookies []importedCookie
+	for _, entry := range har.Log.Entries {
+		reqURL, err := url.Parse(entry.Request.URL)
+		if err != nil {
+			continue
+		}
+		for _, list := range [][]harCookie{entry.Request.Cookies, entry.Response.Cookies} {
+			for _, h := range list {
+				c := importedCookie{
+					Name:   h.Name,
+					Value:  h.Value,
+					Domain: h.Domain,
+					Path:   h.Path,
+				}
+				if c.Domain == "" {
+					c.Domain = reqURL.Hostname()
+					c.HostOnly = true
+				}
+				if h.Expires != "" {
+					if t, err := time.Parse(time.RFC3339, h.Expires); err == nil {
+						c.Expires = t
+					}
+				}
+				cookies = append(cookies, c)
+			}
+		}
+	}
+	return cookies, nil
+}
+
+// parseExportExpires 解析 expires 字段，可能是秒级时间戳或 RFC3339 字符串
+func parseExportExpires(raw json.RawMessage) time.Time {
+	if len(raw) == 0 {
+		return time.Time{}
+	}
+	var seconds float64
+	if err := json.Unmarshal(raw, &seconds); err == nil && seconds > 0 {
+		return time.Unix(0, int64(seconds*float64(time.Second)))
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		if t, err := time.Parse(time.RFC3339, text); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// cookieMatchesURL 按 RFC 6265 的域名和路径规则判断 Cookie 是否会发送给目标地址
+func cookieMatchesURL(c importedCookie, target *url.URL) bool {
+	host := strings.ToLower(target.Hostname())
+	domain := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+	if domain == "" {
+		return false
+	}
+	hostOnly := c.HostOnly && !strings.HasPrefix(c.Domain, ".")
+	if host != domain && (hostOnly || !strings.HasSuffix(host, "."+domain)) {
+		return false
+	}
+
+	cookiePath := c.Path
+	if cookiePath == "" {
+		cookiePath = "/"
+	}
+	requestPath := target.Path
+	if requestPath == "" {
+		requestPath = "/"
+	}
+	if requestPath == cookiePath {
+		return true
+	}
+	return strings.HasPrefix(requestPath, cookiePath) &&
+		(strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/')
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCookieExport(t *testing.T) {
+	// 2025-01-07 00:00:00 UTC；1767225600 是 2026-01-01，1704067200 是 2024-01-01
+	now := time.Unix(1736208000, 0)
+
+	tests := []struct {
+		name    string
+		data    string
+		format  string
+		cookie  string
+		skipped int
+	}{
+		{
+			name: "Netscape",
+			data: strings.Join([]string{
+				"# Netscape HTTP Cookie File",
+				"#HttpOnly_.baidu.com\tTRUE\t/\tTRUE\t1767225600\tBDUSS\tbduss-value",
+				"easylearn.baidu.com\tFALSE\t/\tFALSE\t0\tSTOKEN\tstoken-value",
+				"baidu.com\tFALSE\t/\tFALSE\t1767225600\tHOSTONLY\tparent-only",
+				".baidu.com\tTRUE\t/\tFALSE\t1704067200\tOLD\texpired",
+				".baidu.com\tTRUE\t/other\tFALSE\t0\tPATH\tother-path",
+			}, "\r\n"),
+			format:  "netscape",
+			cookie:  "BDUSS=bduss-value; STOKEN=stoken-value",
+			skipped: 3,
+		},
+		{
+			name: "HAR 响应覆盖请求",
+			data: `{"log":{"entries":[
+				{"request":{"url":"https://easylearn.baidu.com/edushop/info","cookies":[{"name":"BDUSS","value":"old"}]},
+				 "response":{"cookies":[
+					{"name":"BDUSS","value":"new","domain":".baidu.com","path":"/","expires":"2026-01-01T00:00:00Z"},
+					{"name":"GONE","value":"x","domain":".baidu.com","expires":"2024-01-01T00:00:00Z"}]}},
+				{"request":{"url":"https://other.example.com/","cookies":[{"name":"OTHER","value":"x"}]},
+				 "response":{"cookies":[]}}
+			]}}`,
+			format:  "har",
+			cookie:  "BDUSS=new",
+			skipped: 2,
+		},
+		{
+			name: "扩展导出数组",
+			data: `[
+				{"name":"BDUSS","value":"v","domain":".baidu.com","path":"/","hostOnly":false,"session":false,"expirationDate":1767225600.5},
+				{"name":"SESS","value":"s","domain":"easylearn.baidu.com","path":"/edushop","hostOnly":true,"session":true,"expirationDate":1704067200},
+				{"name":"HOST","value":"h","domain":"baidu.com","hostOnly":true,"session":true},
+				{"name":"OLD","value":"o","domain":".baidu.com","expirationDate":1704067200},
+				{"name":"EXP","value":"e","domain":".baidu.com","expires":"2024-01-01T00:00:00Z"}
+			]`,
+			format:  "json",
+			cookie:  "BDUSS=v; SESS=s",
+			skipped: 3,
+		},
+		{
+			name:    "扩展导出对象",
+			data:    "\ufeff" + `{"url":"https://easylearn.baidu.com","cookies":[{"name":"BDUSS","value":"v","domain":".baidu.com","expires":1767225600}]}`,
+			format:  "json",
+			cookie:  "BDUSS=v",
+			skipped: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseCookieExport([]byte(tt.data), CookieImportURL, now)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if result.Format != tt.format || result.Cookie != tt.cookie || result.Skipped != tt.skipped {
+				t.Errorf("结果 = %+v，期望格式 %s、Cookie %q、跳过 %d", result, tt.format, tt.cookie, tt.skipped)
+			}
+			var names []string
+			for _, pair := range strings.Split(tt.cookie, "; ") {
+				name, _, _ := strings.Cut(pair, "=")
+				names = append(names, name)
+			}
+			if !slices.Equal(result.Names, names) {
+				t.Errorf("Names = %q，期望 %q", result.Names, names)
+			}
+		})
+	}
+}
+
+func TestParseCookieExportErrors(t *testing.T) {
+	now := time.Unix(1736208000, 0)
+	for name, data := range map[string]string{
+		"空内容":         " \n",
+		"字段不足":        ".baidu.com\tTRUE\t/\tBDUSS",
+		"无法识别的JSON":   `{"name":"BDUSS"}`,
+		"没有匹配的Cookie": `[{"name":"BDUSS","value":"v","domain":".example.com"}]`,
+	} {
+		if _, err := ParseCookieExport([]byte(data), CookieImportURL, now); err == nil {
+			t.Errorf("%s: 应返回错误", name)
+		}
+	}
+}
+
+func TestCookieMatchesURL(t *testing.T) {
+	tests := []struct {
+		domain, path string
+		hostOnly     bool
+		url          string
+		want         bool
+	}{
+		{".baidu.com", "/", false, "https://easylearn.baidu.com/edushop/", true},
+		{"baidu.com", "/", false, "https://easylearn.baidu.com/", true},
+		{"baidu.com", "/", true, "https://easylearn.baidu.com/", false},
+		{".baidu.com", "/", true, "https://easylearn.baidu.com/", true}, // 以点开头的域名不可能是 host-only
+		{"easylearn.baidu.com", "/", true, "https://EasyLearn.Baidu.com/", true},
+		{"baidu.com", "/", false, "https://notbaidu.com/", false},
+		{"", "/", false, "https://easylearn.baidu.com/", false},
+		{".baidu.com", "", false, "https://easylearn.baidu.com", true},
+		{".baidu.com", "/edushop", false, "https://easylearn.baidu.com/edushop", true},
+		{".baidu.com", "/edushop", false, "https://easylearn.baidu.com/edushop/task", true},
+		{".baidu.com", "/edushop/", false, "https://easylearn.baidu.com/edushop/task", true},
+		{".baidu.com", "/edushop", false, "https://easylearn.baidu.com/edushopping", false},
+		{".baidu.com", "/edushop", false, "https://easylearn.baidu.com/", false},
+	}
+	for _, tt := range tests {
+		target, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := importedCookie{Name: "BDUSS", Domain: tt.domain, Path: tt.path, HostOnly: tt.hostOnly}
+		if got := cookieMatchesURL(c, target); got != tt.want {
+			t.Errorf("cookieMatchesURL(%q %q hostOnly=%v, %s) = %v，期望 %v", tt.domain, tt.path, tt.hostOnly, tt.url, got, tt.want)
+		}
+	}
+}
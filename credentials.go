@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// appConfigDir 返回保存应用数据的目录，可通过 BEDU_CLAIM_HOME 环境变量覆盖
+func appConfigDir() (string, error) {
+	if dir := os.Getenv("BEDU_CLAIM_HOME"); dir != "" {
+		return dir, nil
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("获取配置目录失败: %v", err)
+	}
+	return filepath.Join(base, "bedu-claim"), nil
+}
+
+// storedCredential 是凭据文件的内容
+type storedCredential struct {
+	Cookie    string    `json:"cookie"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CredentialStore 将百度教育 Cookie 保存在本地文件中，文件仅当前用户可读写
+type CredentialStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewCredentialStore 创建保存在指定路径的凭据存储
+func NewCredentialStore(path string) *CredentialStore {
+	return &CredentialStore{path: path}
+}
+
+// DefaultCredentialStore 返回保存在应用配置目录下的凭据存储
+func DefaultCredentialStore() (*CredentialStore, error) {
+	dir, err := appConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewCredentialStore(filepath.Join(dir, "credentials.json")), nil
+}
+
+// Load 读取保存的 Cookie，文件不存在时返回空字符串
+func (s *CredentialStore) Load() (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("读取凭据失败: %v", err)
+	}
+
+	var credential storedCredential
+	if err := json.Unmarshal(data, &credential); err != nil {
+		return "", fmt.Errorf("解析凭据失败: %v", err)
+	}
+	return credential.Cookie, nil
+}
+
+// Save 保存 Cookie，先写入临时文件再替换，避免写入中断导致凭据损坏
+func (s *CredentialStore) Save(cookie string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := json.MarshalIndent(storedCredential{Cookie: cookie, UpdatedAt: time.Now()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("保存凭据失败: %v", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("保存凭据失败: %v", err)
+	}
+	return nil
+}
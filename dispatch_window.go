@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 相对发布时间窗口，写在 AutoClaimConfig.DispatchWindow 中，每次认领时根据当前时间重新计算
+const (
+	DispatchWindowToday   = "today"   // 当天 0 点至今
+	DispatchWindowSession = "session" // 本次会话启动之后
+	dispatchWindowLast    = "last:"   // "last:30m" 表示最近 30 分钟
+)
+
+// dispatchRange 是发布时间筛选的区间，零值表示该端不限制
+type dispatchRange struct {
+	start, end time.Time
+}
+
+// active 判断是否设置了发布时间筛选
+func (r dispatchRange) active() bool {
+	return !r.start.IsZero() || !r.end.IsZero()
+}
+
+// contains 判断任务的发布时间是否在区间内，没有发布时间或格式错误的任务不匹配
+func (r dispatchRange) contains(dispatchTime PlatformTime) bool {
+	if !r.active() {
+		return true
+	}
+	if dispatchTime.IsZero() {
+		return false
+	}
+	taskTime := dispatchTime.Time
+	if !r.start.IsZero() && taskTime.Before(r.start) {
+		return false
+	}
+	if !r.end.IsZero() && taskTime.After(r.end) {
+		return false
+	}
+	return true
+}
+
+// String 以平台时区显示区间，不限制的一端显示为"不限"
+func (r dispatchRange) String() string {
+	format := func(t time.Time) string {
+		if t.IsZero() {
+			return "不限"
+		}
+		return PlatformTime{t}.String()
+	}
+	return fmt.Sprintf("%s 至 %s", format(r.start), format(r.end))
+}
+
+// validateDispatchWindow 检查相对时间窗口的写法
+func validateDispatchWindow(window string) error {
+	switch {
+	case window == "", window == DispatchWindowToday, window == DispatchWindowSession:
+		return nil
+	case strings.HasPrefix(window, dispatchWindowLast):
+		d, err := time.ParseDuration(strings.TrimPrefix(window, dispatchWindowLast))
+		if err != nil || d <= 0 {
+			return fmt.Errorf("无效的相对时间窗口 %q，例如 last:30m", window)
+		}
+		return nil
+	default:
+		return fmt.Errorf("无效的相对时间窗口 %q，可选 today、session 或 last:<时长>", window)
+	}
+}
+
+// resolveDispatchRange 计算 now 时刻的发布时间区间。设置了相对窗口时使用相对窗口，
+// 否则使用 StartTime/EndTime 绝对时间，格式错误的一端不做限制。"今天"和绝对时间都按平台时区计算
+func resolveDispatchRange(config AutoClaimConfig, now, sessionStart time.Time) dispatchRange {
+	switch window := config.DispatchWindow; {
+	case window == DispatchWindowToday:
+		now = now.In(PlatformLocation())
+		return dispatchRange{start: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())}
+	case window == DispatchWindowSession:
+		return dispatchRange{start: sessionStart}
+	case strings.HasPrefix(window, dispatchWindowLast):
+		if d, err := time.ParseDuration(strings.TrimPrefix(window, dispatchWindowLast)); err == nil && d > 0 {
+			return dispatchRange{start: now.Add(-d)}
+		}
+	}
+
+	var r dispatchRange
+	if t, err := ParsePlatformTime(config.StartTime); err == nil {
+		r.start = t.Time
+	}
+	if t, err := ParsePlatformTime(config.EndTime); err == nil {
+		r.end = t.Time
+	}
+	return r
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveDispatchRangeAcrossTicks(t *testing.T) {
+	// 会话在平台时间 23:40 启动，之后每次认领重新计算区间：+10 分钟为 23:50，再 +15 分钟跨过平台零点
+	type tick struct {
+		advance    time.Duration
+		start, end string // 按平台时区书写，空字符串表示不限
+	}
+	tests := []struct {
+		name   string
+		zone   string
+		config AutoClaimConfig
+		ticks  []tick
+	}{
+		{
+			name:   "today 在平台零点切换到新的一天",
+			config: AutoClaimConfig{DispatchWindow: DispatchWindowToday},
+			ticks: []tick{
+				{0, "2025-01-07 00:00:00", ""},
+				{10 * time.Minute, "2025-01-07 00:00:00", ""},
+				{15 * time.Minute, "2025-01-08 00:00:00", ""},
+			},
+		},
+		{
+			// 同一时刻在 UTC 是 15:40，还没有到零点
+			name:   "today 按配置的平台时区计算",
+			zone:   "UTC",
+			config: AutoClaimConfig{DispatchWindow: DispatchWindowToday},
+			ticks: []tick{
+				{0, "2025-01-07 00:00:00", ""},
+				{25 * time.Minute, "2025-01-07 00:00:00", ""},
+			},
+		},
+		{
+			name:   "session 固定为会话启动时间",
+			config: AutoClaimConfig{DispatchWindow: DispatchWindowSession, StartTime: "2025-01-01 00:00:00"},
+			ticks: []tick{
+				{0, "2025-01-07 23:40:00", ""},
+				{10 * time.Minute, "2025-01-07 23:40:00", ""},
+				{15 * time.Minute, "2025-01-07 23:40:00", ""},
+			},
+		},
+		{
+			name:   "last:30m 随时间滑动",
+			config: AutoClaimConfig{DispatchWindow: "last:30m", EndTime: "2025-01-07 23:45:00"},
+			ticks: []tick{
+				{0, "2025-01-07 23:10:00", ""},
+				{10 * time.Minute, "2025-01-07 23:20:00", ""},
+				{15 * time.Minute, "2025-01-07 23:35:00", ""},
+			},
+		},
+		{
+			name:   "没有相对窗口时使用绝对时间",
+			config: AutoClaimConfig{StartTime: "2025-01-07 08:00", EndTime: "2025-01-07 18:00:00"},
+			ticks: []tick{
+				{0, "2025-01-07 08:00:00", "2025-01-07 18:00:00"},
+				{25 * time.Minute, "2025-01-07 08:00:00", "2025-01-07 18:00:00"},
+			},
+		},
+		{
+			name:   "无效的相对窗口和格式错误的一端不做限制",
+			config: AutoClaimConfig{DispatchWindow: "last:abc", StartTime: "昨天", EndTime: "2025-01-07 18:00:00"},
+			ticks: []tick{
+				{0, "", "2025-01-07 18:00:00"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.zone != "" {
+				setPlatformTimezone(t, tt.zone)
+			}
+			clock := newFakeClock(time.Date(2025, 1, 7, 15, 40, 0, 0, time.UTC))
+			sessionStart := clock.Now()
+
+			for i, tick := range tt.ticks {
+				clock.Advance(tick.advance)
+				got := resolveDispatchRange(tt.config, clock.Now(), sessionStart)
+				want := dispatchRange{}
+				if tick.start != "" {
+					want.start = mustPlatformTime(t, tick.start).Time
+				}
+				if tick.end != "" {
+					want.end = mustPlatformTime(t, tick.end).Time
+				}
+				if !got.start.Equal(want.start) || !got.end.Equal(want.end) {
+					t.Errorf("第 %d 次（%s）区间 = %s，期望 %s", i+1, PlatformTime{clock.Now()}, got, want)
+				}
+			}
+		})
+	}
+}
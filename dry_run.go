@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// DryRunSummary 统计模拟运行期间的筛选结果
+type DryRunSummary struct {
+	Polls         int `json:"polls"`         // 成功获取任务列表的次数
+	TasksSeen     int `json:"tasksSeen"`     // 任务列表中返回的任务数，同一任务每次出现都计数
+	TasksMatched  int `json:"tasksMatched"`  // 通过筛选的任务数，同一任务每次出现都计数
+	UniqueMatched int `json:"uniqueMatched"` // 通过筛选的不同任务数，即实际运行时可能认领的任务
+}
+
+func (s DryRunSummary) String() string {
+	return fmt.Sprintf("模拟运行共获取任务列表 %d 次，返回任务 %d 个，通过筛选 %d 个（不同任务 %d 个）",
+		s.Polls, s.TasksSeen, s.TasksMatched, s.UniqueMatched)
+}
+
+// recordDryRun 记录一轮模拟运行的筛选结果，首次通过筛选的任务产生 EventWouldClaim 事件
+func (ac *AutoClaimer) recordDryRun(seen int, matched []TaskItem) {
+	ac.mutex.Lock()
+	summary := ac.status.DryRun
+	summary.Polls++
+	summary.TasksSeen += seen
+	summary.TasksMatched += len(matched)
+
+	var newTasks []TaskItem
+	for _, task := range matched {
+		id := strconv.Itoa(task.TaskID)
+		if ac.config.TaskType == "producetask" {
+			id = strconv.Itoa(task.ClueID)
+		}
+		if _, ok := ac.wouldClaim[id]; ok {
+			continue
+		}
+		ac.wouldClaim[id] = struct{}{}
+		newTasks = append(newTasks, task)
+	}
+	summary.UniqueMatched = len(ac.wouldClaim)
+	unique := summary.UniqueMatched
+	ac.mutex.Unlock()
+
+	ac.logf("模拟运行：本轮通过筛选 %d/%d 个任务，新增 %d 个，累计 %d 个（未认领）", len(matched), seen, len(newTasks), unique)
+	for i := range newTasks {
+		ac.emit(ClaimEvent{Type: EventWouldClaim, Message: fmt.Sprintf("模拟运行，将认领任务：%s", newTasks[i].Brief), Task: &newTasks[i]})
+	}
+}
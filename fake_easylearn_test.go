@@ -0,0 +1,305 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeCookie 是替身服务器认可的登录 Cookie
+const fakeCookie = "BDUSS=fake-bduss; STOKEN=fake-stoken"
+
+// fakeEasylearn 是进程内的百度教育平台替身，实现任务标签、任务列表、认领和用户信息接口。
+// 任务池、错误码、延迟和抢单失败都可以在测试中设置
+type fakeEasylearn struct {
+	*httptest.Server
+
+	mutex      sync.Mutex
+	pools      map[string][]TaskItem // 按任务类型（audittask/producetask）划分的待认领任务
+	claimed    map[string][]int      // 按任务类型记录已认领的 ID，生产任务为 ClueID
+	labels     []Filter
+	userName   string
+	bduss      string         // 当前有效的 BDUSS
+	setCookies []*http.Cookie // 下一个已登录响应通过 Set-Cookie 下发的 Cookie
+	loggedOut  bool
+	listErrno  int
+	listErrmsg string
+	claimErrno int
+	claimMsg   string
+	latency    time.Duration
+	held       chan struct{} // 非空时请求阻塞到通道关闭
+	raceLosses map[int]bool  // 认领时被他人抢先的 ID
+	listCalls  int
+	claimCalls int
+	requests   []string // 按到达顺序记录的列表和认领请求，如 "list audittask p2"、"claim audittask [1]"
+}
+
+// newFakeEasylearn 启动替身服务器，测试结束时自动关闭
+func newFakeEasylearn(t *testing.T) *fakeEasylearn {
+	t.Helper()
+
+	f := &fakeEasylearn{
+		pools:      map[string][]TaskItem{},
+		claimed:    map[string][]int{},
+		userName:   "fake-user",
+		bduss:      "fake-bduss",
+		raceLosses: map[int]bool{},
+		labels: []Filter{
+			{ID: "step", Name: "学段", Type: "select", List: []Subject{{ID: 1, Name: "小学"}, {ID: 2, Name: "初中"}}},
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /edushop/question/{taskType}/getlabel", f.handleLabel)
+	mux.HandleFunc("GET /edushop/question/{taskType}/list", f.handleList)
+	mux.HandleFunc("POST /edushop/question/{commitType}/claim", f.handleClaim)
+	mux.HandleFunc("GET /edushop/user/common/info", f.handleUserInfo)
+	f.Server = httptest.NewServer(f.delay(f.requireLogin(mux)))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// addTasks 向任务池添加任务
+func (f *fakeEasylearn) addTasks(taskType string, tasks ...TaskItem) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.pools[taskType] = append(f.pools[taskType], tasks...)
+}
+
+// failList 让任务列表接口返回指定的 errno，errno 为 0 时恢复正常
+func (f *fakeEasylearn) failList(errno int, errmsg string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.listErrno, f.listErrmsg = errno, errmsg
+}
+
+// failClaim 让认领接口返回指定的 errno，errno 为 0 时恢复正常
+func (f *fakeEasylearn) failClaim(errno int, errmsg string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.claimErrno, f.claimMsg = errno, errmsg
+}
+
+// loseRace 让这些 ID 在认领时被他人抢先：任务从池中消失，但本次认领不成功
+func (f *fakeEasylearn) loseRace(ids ...int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, id := range ids {
+		f.raceLosses[id] = true
+	}
+}
+
+// setLatency 为每个请求增加延迟
+func (f *fakeEasylearn) setLatency(d time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.latency = d
+}
+
+// hold 让之后的请求阻塞，直到调用返回的 release；测试结束时自动放行
+func (f *fakeEasylearn) hold(t *testing.T) (release func()) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	held := make(chan struct{})
+	f.held = held
+	release = sync.OnceFunc(func() {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		if f.held == held {
+			f.held = nil
+		}
+		close(held)
+	})
+	t.Cleanup(release)
+	return release
+}
+
+// setCookie 让下一个已登录的响应通过 Set-Cookie 下发 c，下发新的 BDUSS 后旧值不再有效
+func (f *fakeEasylearn) setCookie(c *http.Cookie) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.setCookies = append(f.setCookies, c)
+}
+
+// logout 让之后的请求都以未登录响应
+func (f *fakeEasylearn) logout() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.loggedOut = true
+}
+
+// claimedIDs 返回已认领的 ID
+func (f *fakeEasylearn) claimedIDs(taskType string) []int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return slices.Clone(f.claimed[taskType])
+}
+
+// requestLog 返回按到达顺序记录的列表和认领请求
+func (f *fakeEasylearn) requestLog() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return slices.Clone(f.requests)
+}
+
+// calls 返回任务列表和认领接口的请求次数
+func (f *fakeEasylearn) calls() (list, claim int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.listCalls, f.claimCalls
+}
+
+func (f *fakeEasylearn) delay(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mutex.Lock()
+		latency := f.latency
+		held := f.held
+		f.mutex.Unlock()
+		if held != nil {
+			select {
+			case <-held:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireLogin 校验 BDUSS，未登录时与平台一样返回 errno 和"未登录"提示
+func (f *fakeEasylearn) requireLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mutex.Lock()
+		if cookie, err := r.Cookie("BDUSS"); err != nil || cookie.Value != f.bduss || f.loggedOut {
+			f.mutex.Unlock()
+			writeJSON(w, http.StatusOK, map[string]any{"errno": 110000, "errmsg": "用户未登录"})
+			return
+		}
+		for _, c := range f.setCookies {
+			http.SetCookie(w, c)
+			if c.Name == "BDUSS" && c.MaxAge >= 0 {
+				f.bduss = c.Value
+			}
+		}
+		f.setCookies = nil
+		f.mutex.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (f *fakeEasylearn) handleLabel(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{"errno": 0, "errmsg": "", "data": map[string]any{"filter": f.labels}})
+}
+
+func (f *fakeEasylearn) handleList(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	query := r.URL.Query()
+	pn, _ := strconv.Atoi(query.Get("pn"))
+	rn, _ := strconv.Atoi(query.Get("rn"))
+	pn, rn = max(pn, 1), max(rn, 1)
+
+	f.listCalls++
+	f.requests = append(f.requests, fmt.Sprintf("list %s p%d", r.PathValue("taskType"), pn))
+	if f.listErrno != 0 {
+		writeJSON(w, http.StatusOK, map[string]any{"errno": f.listErrno, "errmsg": f.listErrmsg})
+		return
+	}
+
+	step, _ := strconv.Atoi(query.Get("step"))
+	subject, _ := strconv.Atoi(query.Get("subject"))
+	clueType, _ := strconv.Atoi(query.Get("clueType"))
+
+	// 筛选参数为 0 时不限制
+	var matched []TaskItem
+	for _, task := range f.pools[r.PathValue("taskType")] {
+		if (step == 0 || task.Step == step) && (subject == 0 || task.Subject == subject) && (clueType == 0 || task.ClueType == clueType) {
+			matched = append(matched, task)
+		}
+	}
+	start := min((pn-1)*rn, len(matched))
+	end := min(start+rn, len(matched))
+	writeJSON(w, http.StatusOK, map[string]any{
+		"errno":  0,
+		"errmsg": "",
+		"data":   map[string]any{"total": len(matched), "list": append([]TaskItem{}, matched[start:end]...)},
+	})
+}
+
+func (f *fakeEasylearn) handleClaim(w http.ResponseWriter, r *http.Request) {
+	var taskType string
+	switch r.PathValue("commitType") {
+	case "audittaskcommit":
+		taskType = "audittask"
+	case "producetaskcommit":
+		taskType = "producetask"
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	var body struct {
+		TaskIDs []int `json:"taskIDs"`
+		ClueIDs []int `json:"clueIDs"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusOK, map[string]any{"errno": 1, "errmsg": "参数错误"})
+		return
+	}
+	ids := body.TaskIDs
+	if taskType == "producetask" {
+		ids = body.ClueIDs
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.claimCalls++
+	f.requests = append(f.requests, fmt.Sprintf("claim %s %v", taskType, ids))
+	if f.claimErrno != 0 {
+		writeJSON(w, http.StatusOK, map[string]any{"errno": f.claimErrno, "errmsg": f.claimMsg})
+		return
+	}
+
+	success := 0
+	for _, id := range ids {
+		index := slices.IndexFunc(f.pools[taskType], func(task TaskItem) bool {
+			if taskType == "producetask" {
+				return task.ClueID == id
+			}
+			return task.TaskID == id
+		})
+		if index < 0 {
+			continue
+		}
+		f.pools[taskType] = slices.Delete(f.pools[taskType], index, index+1)
+		if f.raceLosses[id] {
+			continue
+		}
+		f.claimed[taskType] = append(f.claimed[taskType], id)
+		success++
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"errno": 0, "errmsg": "", "data": map[string]any{"success": success}})
+}
+
+func (f *fakeEasylearn) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{"errno": 0, "errmsg": "", "data": map[string]any{"userName": f.userName}})
+}
@@ -1,7 +1,7 @@
 import React, { useState, useEffect, useCallback, useRef } from 'react';
-import { StartAutoClaiming, StopAutoClaiming, GetAutoClaimStatus, GetTaskLabels, GetUserInfo } from '../wailsjs/go/main/App.js';
+import { StartAutoClaiming, StopAutoClaiming, GetAutoClaimStatus, GetTaskLabels, GetUserInfo, ImportCookies, GetSavedCookie, GetSettingsError, GetPlatformTimezone, GetAuthorizationPlan, GetCurrentAuthorizationPlan, GetNotificationSettings, SaveNotificationSettings, GetSchedules, SaveSchedule, DeleteSchedule, ValidateAutoClaimConfig, PreviewFilter, GetPoolReport, ExportClaimHistory } from '../wailsjs/go/main/App.js';
 import { main } from '../wailsjs/go/models.js';
-import { BrowserOpenURL } from '../wailsjs/runtime/runtime.js';
+import { BrowserOpenURL, EventsOn } from '../wailsjs/runtime/runtime.js';
 
 // 类型定义
 type Filter = {
@@ -17,6 +17,66 @@ type AutoClaimStatusType = {
   isActive: boolean;
   successfulClaims: number;
   lastError: string;
+  state: string;
+  stopReason: string;
+  licenseExpiresAt: string;
+  licenseRemainingSeconds: number;
+  dryRun?: { polls: number; tasksSeen: number; tasksMatched: number; uniqueMatched: number };
+  stats?: main.SessionStats;
+};
+
+type DesktopNotificationType = {
+  title: string;
+  body: string;
+  level: 'success' | 'warning' | 'error';
+  sound: boolean;
+  delivered: boolean;
+};
+
+// 会话错误类别的显示名称
+const sessionErrorLabels: Record<string, string> = {
+  auth: 'Cookie失效',
+  list_request: '列表请求失败',
+  list_errno: '列表接口报错',
+  claim_request: '认领请求失败',
+  claim_errno: '认领接口报错',
+  claim_missed: '被抢先',
+};
+
+// 通知开关的显示名称
+const notificationToggles: { key: 'batchClaimed' | 'limitReached' | 'cookieExpired' | 'sound'; label: string }[] = [
+  { key: 'batchClaimed', label: '认领成功时通知' },
+  { key: 'limitReached', label: '达到认领上限时通知' },
+  { key: 'cookieExpired', label: 'Cookie 失效时通知' },
+  { key: 'sound', label: '播放提示音' },
+];
+
+// 使用 Web Audio 播放提示音，不依赖音频文件
+const playAlertSound = (level: DesktopNotificationType['level']) => {
+  const AudioContextClass = window.AudioContext || (window as any).webkitAudioContext;
+  if (!AudioContextClass) return;
+  const context = new AudioContextClass();
+  const frequencies = level === 'error' ? [440, 330] : [660, 880];
+  frequencies.forEach((frequency, index) => {
+    const oscillator = context.createOscillator();
+    const gain = context.createGain();
+    const start = context.currentTime + index * 0.18;
+    oscillator.frequency.value = frequency;
+    gain.gain.setValueAtTime(0.2, start);
+    gain.gain.exponentialRampToValueAtTime(0.001, start + 0.16);
+    oscillator.connect(gain).connect(context.destination);
+    oscillator.start(start);
+    oscillator.stop(start + 0.16);
+  });
+  setTimeout(() => context.close(), 1000);
+};
+
+type ClaimEventType = {
+  type: string;
+  time: string;
+  state: string;
+  message: string;
+  claimed: number;
 };
 
 export default function ClueClaimingComponent() {
@@ -26,10 +86,15 @@ export default function ClueClaimingComponent() {
   const [selectedSubject, setSelectedSubject] = useState('');
   const [selectedType, setSelectedType] = useState('');
   const [claimLimit, setClaimLimit] = useState(10);
+  // 模拟运行：只筛选任务不认领，用于调整筛选条件
+  const [dryRun, setDryRun] = useState(false);
   const [refreshInterval, setRefreshInterval] = useState(1.0);
   const [timeUnit, setTimeUnit] = useState<'seconds' | 'milliseconds'>('seconds');
   const [startTime, setStartTime] = useState('');
   const [endTime, setEndTime] = useState('');
+  // 相对时间窗口：空字符串表示使用上面的绝对时间
+  const [dispatchMode, setDispatchMode] = useState<'' | 'today' | 'session' | 'last'>('');
+  const [dispatchLastMinutes, setDispatchLastMinutes] = useState(30);
   const [includeKeywords, setIncludeKeywords] = useState<string[]>([]);
   const [excludeKeywords, setExcludeKeywords] = useState<string[]>([]);
   const [newIncludeKeyword, setNewIncludeKeyword] = useState('');
@@ -38,7 +103,12 @@ export default function ClueClaimingComponent() {
   const [autoClaimingActive, setAutoClaimingActive] = useState<boolean>(false);
   const [isLoading, setIsLoading] = useState<boolean>(false);
   const [isClaimingButtonLoading, setIsClaimingButtonLoading] = useState<boolean>(false);
+  // 后端校验出的配置字段错误，存在时不允许启动
+  const [configErrors, setConfigErrors] = useState<main.FieldError[]>([]);
   const [userInfoError, setUserInfoError] = useState<string>('');
+  const [settingsError, setSettingsError] = useState<string>('');
+  // 平台时区由后端配置决定（默认 Asia/Shanghai），「今天」和导出日期按它计算
+  const [platformTimezone, setPlatformTimezone] = useState<string>('Asia/Shanghai');
   const [cookie, setCookie] = useState<string>('');
   const [claimStatus, setClaimStatus] = useState<AutoClaimStatusType | null>(null);
   const [userInfo, setUserInfo] = useState<{ username: string; avatar: string } | null>(null);
@@ -46,21 +116,91 @@ export default function ClueClaimingComponent() {
   const [showAuthModal, setShowAuthModal] = useState(false);
   const [authType, setAuthType] = useState<'official' | 'custom'>('official');
   const [authUsername, setAuthUsername] = useState('');
+  const [authPlan, setAuthPlan] = useState<main.AuthPlanResponse | null>(null);
+  const [authPlanLoading, setAuthPlanLoading] = useState(false);
+  const [showNotificationModal, setShowNotificationModal] = useState(false);
+  const [notificationSettings, setNotificationSettings] = useState<main.NotificationSettings | null>(null);
+  const [showScheduleModal, setShowScheduleModal] = useState(false);
+  // 导出认领记录：默认最近 7 天
+  const [showExportModal, setShowExportModal] = useState(false);
+  const [exportSince, setExportSince] = useState('');
+  const [exportUntil, setExportUntil] = useState('');
+  const [exportAccount, setExportAccount] = useState('');
+  const [exportFormat, setExportFormat] = useState<'csv' | 'xlsx'>('xlsx');
+  const [exporting, setExporting] = useState(false);
+  // 筛选预览：当前线索池中每个任务是否通过筛选及原因
+  const [filterPreview, setFilterPreview] = useState<main.FilterPreview | null>(null);
+  const [previewLoading, setPreviewLoading] = useState(false);
+  const [poolReport, setPoolReport] = useState<main.PoolReport | null>(null);
+  const [poolReportLoading, setPoolReportLoading] = useState(false);
+  const [schedules, setSchedules] = useState<main.ClaimSchedule[]>([]);
+  const [scheduleName, setScheduleName] = useState('');
+  const [scheduleWindows, setScheduleWindows] = useState('');
 
   const isUserInteractionRef = useRef(false);
   const statusIntervalRef = useRef<number | null>(null);
+  const cookieFileInputRef = useRef<HTMLInputElement | null>(null);
 
   // 获取今天开始和结束时间的工具函数
-  const getTodayStartTime = () => {
-    const today = new Date();
-    today.setHours(0, 0, 0, 0);
-    return today.toISOString().slice(0, 16);
+  // 发布时间按后端配置的平台时区计算，与本机时区无关
+  const getPlatformToday = () =>
+    new Intl.DateTimeFormat('sv-SE', { timeZone: platformTimezone }).format(new Date());
+
+  const getTodayStartTime = () => `${getPlatformToday()}T00:00`;
+
+  const getTodayEndTime = () => `${getPlatformToday()}T23:59`;
+
+  // 查询官方授权的套餐信息
+  const queryAuthPlan = async () => {
+    if (!authUsername.trim()) {
+      showToast('请先输入官方授权用户名', 'warning');
+      return;
+    }
+    setAuthPlanLoading(true);
+    try {
+      const plan = await GetAuthorizationPlan(authUsername.trim());
+      if (plan.success) {
+        setAuthPlan(plan);
+      } else {
+        setAuthPlan(null);
+        showToast(plan.message, 'error');
+      }
+    } finally {
+      setAuthPlanLoading(false);
+    }
   };
 
-  const getTodayEndTime = () => {
-    const today = new Date();
-    today.setHours(23, 59, 59, 999);
-    return today.toISOString().slice(0, 16);
+  // 保存桌面通知开关
+  const saveNotificationSettings = async () => {
+    if (!notificationSettings) return;
+    try {
+      await SaveNotificationSettings(notificationSettings);
+      setShowNotificationModal(false);
+      showToast('通知设置已保存', 'success');
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error);
+      showToast(errorMessage, 'error');
+    }
+  };
+
+  // 后端已显示系统通知时只播放提示音，系统通知不可用时退回到页面内提示
+  const showDesktopNotification = (notification: DesktopNotificationType) => {
+    if (notification.sound) {
+      playAlertSound(notification.level);
+    }
+    if (!notification.delivered) {
+      showToast(`${notification.title}：${notification.body}`, notification.level);
+    }
+  };
+
+  // 将剩余秒数格式化为倒计时
+  const formatCountdown = (seconds: number) => {
+    const days = Math.floor(seconds / 86400);
+    const hours = Math.floor((seconds % 86400) / 3600);
+    const minutes = Math.floor((seconds % 3600) / 60);
+    if (days > 0) return `${days}天${hours}小时`;
+    if (hours > 0) return `${hours}小时${minutes}分钟`;
+    return `${minutes}分钟${seconds % 60}秒`;
   };
 
   // 显示toast通知
@@ -130,48 +270,69 @@ export default function ClueClaimingComponent() {
     }
   }, [cookie]);
 
+  // 根据当前表单生成认领配置
+  const buildConfig = useCallback((): main.AutoClaimConfig => {
+    // 获取选择的筛选器ID
+    const stepFilter = filterData.find(f => f.id === 'step');
+    const subjectFilter = filterData.find(f => f.id === 'subject');
+    const clueTypeFilter = filterData.find(f => f.id === 'clueType');
+
+    const stepItem = stepFilter?.list.find(item => item.name === selectedGrade);
+    const subjectItem = subjectFilter?.list.find(item => item.name === selectedSubject);
+    const clueTypeItem = clueTypeFilter?.list.find(item => item.name === selectedType);
+
+    return {
+      ServerBaseURL: '', // 已在Go代码中硬编码为 DefaultServerURL
+      Cookie: cookie,
+      TaskType: selectedTaskType,
+      ClaimLimit: claimLimit,
+      Interval: timeUnit === 'seconds' ? refreshInterval : refreshInterval / 1000,
+      MaxPages: 0,
+      ConcurrentClaims: 10,
+      StepID: stepItem?.id || 0,
+      SubjectID: subjectItem?.id || 0,
+      ClueTypeID: clueTypeItem?.id || 0,
+      IncludeKeywords: includeKeywords,
+      ExcludeKeywords: excludeKeywords,
+      StartTime: startTime ? startTime.replace('T', ' ') + ':00' : '',
+      EndTime: endTime ? endTime.replace('T', ' ') + ':00' : '',
+      dispatchWindow: dispatchMode === 'last' ? `last:${dispatchLastMinutes}m` : dispatchMode,
+      dryRun: dryRun,
+      authType: authType,
+      authUsername: authUsername,
+    };
+  }, [cookie, selectedTaskType, claimLimit, refreshInterval, timeUnit, filterData, selectedGrade, selectedSubject, selectedType, includeKeywords, excludeKeywords, startTime, endTime, dispatchMode, dispatchLastMinutes, dryRun, authType, authUsername]);
+
   // 启动自动认领
+  // 表单变化时重新校验配置，在启动前提示无效的时间范围、关键词等
+  useEffect(() => {
+    let cancelled = false;
+    ValidateAutoClaimConfig(buildConfig())
+      .then((errors) => { if (!cancelled) setConfigErrors(errors || []); })
+      .catch(() => { if (!cancelled) setConfigErrors([]); });
+    return () => { cancelled = true; };
+  }, [buildConfig]);
+
   const startAutoClaiming = useCallback(async () => {
     setIsClaimingButtonLoading(true);
     setUserInfoError('');
 
     try {
-      // 获取选择的筛选器ID
-      const stepFilter = filterData.find(f => f.id === 'step');
-      const subjectFilter = filterData.find(f => f.id === 'subject');
-      const clueTypeFilter = filterData.find(f => f.id === 'clueType');
-
-      const stepItem = stepFilter?.list.find(item => item.name === selectedGrade);
-      const subjectItem = subjectFilter?.list.find(item => item.name === selectedSubject);
-      const clueTypeItem = clueTypeFilter?.list.find(item => item.name === selectedType);
-
-  
-      const config: main.AutoClaimConfig = {
-        ServerBaseURL: '', // 已在Go代码中硬编码为 DefaultServerURL
-        Cookie: cookie,
-        TaskType: selectedTaskType,
-        ClaimLimit: claimLimit,
-        Interval: timeUnit === 'seconds' ? refreshInterval : refreshInterval / 1000,
-        MaxPages: 0,
-        ConcurrentClaims: 10,
-        StepID: stepItem?.id || 0,
-        SubjectID: subjectItem?.id || 0,
-        ClueTypeID: clueTypeItem?.id || 0,
-        IncludeKeywords: includeKeywords,
-        ExcludeKeywords: excludeKeywords,
-        StartTime: startTime ? startTime.replace('T', ' ') + ':00' : '',
-        EndTime: endTime ? endTime.replace('T', ' ') + ':00' : '',
-        authType: authType,
-        authUsername: authUsername,
-      };
-
-      const response = await StartAutoClaiming(config);
+      const response = await StartAutoClaiming(buildConfig());
 
       if (response.success) {
         setAutoClaimingActive(true);
+        // 授权限制调整了认领参数时提示用户
+        if (response.message !== '自动认领已启动') {
+          showToast(response.message, 'info');
+        }
+        GetCurrentAuthorizationPlan().then((plan) => setAuthPlan(plan.success ? plan : null));
         // 开始定期检查状态
         statusIntervalRef.current = setInterval(checkAutoClaimStatus, 2000);
       } else {
+        if (response.errors && response.errors.length > 0) {
+          setConfigErrors(response.errors);
+        }
         showToast(`启动失败: ${response.message}`, 'error');
       }
     } catch (error) {
@@ -179,7 +340,111 @@ export default function ClueClaimingComponent() {
     } finally {
       setIsClaimingButtonLoading(false);
     }
-  }, [cookie, selectedTaskType, claimLimit, refreshInterval, filterData, selectedGrade, selectedSubject, selectedType, includeKeywords, excludeKeywords, startTime, endTime, authType, authUsername]);
+  }, [buildConfig]);
+
+  // 用当前筛选条件检查线索池前两页任务，不认领
+  const previewFilter = async () => {
+    setPreviewLoading(true);
+    try {
+      const response = await PreviewFilter(buildConfig(), 2);
+      if (response.success && response.preview) {
+        setFilterPreview(response.preview);
+      } else {
+        if (response.errors && response.errors.length > 0) {
+          setConfigErrors(response.errors);
+        }
+        showToast(`预览失败: ${response.message}`, 'error');
+      }
+    } catch (error) {
+      showToast(`预览失败: ${error instanceof Error ? error.message : String(error)}`, 'error');
+    } finally {
+      setPreviewLoading(false);
+    }
+  };
+
+  const openExportModal = () => {
+    const until = getPlatformToday();
+    const since = new Date(`${until}T00:00:00`);
+    since.setDate(since.getDate() - 6);
+    setExportSince(`${since.getFullYear()}-${String(since.getMonth() + 1).padStart(2, '0')}-${String(since.getDate()).padStart(2, '0')}`);
+    setExportUntil(until);
+    setShowExportModal(true);
+  };
+
+  const exportHistory = async () => {
+    setExporting(true);
+    try {
+      const path = await ExportClaimHistory(exportFormat, exportSince, exportUntil, exportAccount.trim());
+      if (path) {
+        showToast(`已导出到 ${path}`, 'success');
+        setShowExportModal(false);
+      }
+    } catch (error) {
+      showToast(`导出失败: ${error instanceof Error ? error.message : String(error)}`, 'error');
+    } finally {
+      setExporting(false);
+    }
+  };
+
+  // 根据最近 7 天的线索池快照查看任务出现时段和被认领速度
+  const loadPoolReport = async () => {
+    setPoolReportLoading(true);
+    try {
+      setPoolReport(await GetPoolReport(selectedTaskType, 7));
+    } catch (error) {
+      showToast(`获取线索池趋势失败: ${error instanceof Error ? error.message : String(error)}`, 'error');
+    } finally {
+      setPoolReportLoading(false);
+    }
+  };
+
+  const formatSeconds = (seconds: number) => {
+    if (seconds < 60) return `${Math.round(seconds)} 秒`;
+    if (seconds < 3600) return `${Math.round(seconds / 60)} 分钟`;
+    return `${(seconds / 3600).toFixed(1)} 小时`;
+  };
+
+  // 将当前表单保存为定时方案，Cookie 留空以便使用最新保存的 Cookie
+  const saveSchedule = async () => {
+    const windows = scheduleWindows.split('\n').map(line => line.trim()).filter(Boolean);
+    if (!scheduleName.trim() || windows.length === 0) {
+      showToast('请填写方案名称和至少一个时间窗口', 'warning');
+      return;
+    }
+    try {
+      await SaveSchedule(main.ClaimSchedule.createFrom({
+        name: scheduleName.trim(),
+        enabled: true,
+        windows,
+        config: { ...buildConfig(), Cookie: '' },
+      }));
+      setSchedules(await GetSchedules());
+      setScheduleName('');
+      setScheduleWindows('');
+      showToast('定时方案已保存', 'success');
+    } catch (error) {
+      showToast(`保存定时方案失败: ${error instanceof Error ? error.message : String(error)}`, 'error');
+    }
+  };
+
+  // 启用或停用定时方案
+  const toggleSchedule = async (schedule: main.ClaimSchedule) => {
+    try {
+      await SaveSchedule(main.ClaimSchedule.createFrom({ ...schedule, enabled: !schedule.enabled }));
+      setSchedules(await GetSchedules());
+    } catch (error) {
+      showToast(`保存定时方案失败: ${error instanceof Error ? error.message : String(error)}`, 'error');
+    }
+  };
+
+  const removeSchedule = async (name: string) => {
+    try {
+      await DeleteSchedule(name);
+      setSchedules(await GetSchedules());
+    } catch (error) {
+      showToast(`删除定时方案失败: ${error instanceof Error ? error.message : String(error)}`, 'error');
+    }
+  };
 
   // 停止自动认领
   const stopAutoClaiming = useCallback(async () => {
@@ -243,26 +508,58 @@ export default function ClueClaimingComponent() {
     }
   }, []);
 
+  // 从浏览器导出的文件导入Cookie
+  const importCookieFile = useCallback(async (file: File) => {
+    try {
+      const content = await file.text();
+      const result = await ImportCookies(content);
+      setCookie(result.cookie);
+      localStorage.setItem('serverCookie', result.cookie);
+      fetchUserInfo(result.cookie);
+      showToast(`已导入 ${result.names.length} 个Cookie（${result.format}）`, 'success');
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error);
+      showToast(`导入Cookie失败: ${errorMessage}`, 'error');
+    }
+  }, [fetchUserInfo]);
+
   // 组件初始化
   useEffect(() => {
     // 从localStorage加载设置
     const savedCookie = localStorage.getItem('serverCookie') || '';
     const savedStartTime = localStorage.getItem('clueStartTime') || '';
     const savedEndTime = localStorage.getItem('clueEndTime') || '';
+    const savedDispatchMode = (localStorage.getItem('clueDispatchMode') || '') as '' | 'today' | 'session' | 'last';
+    const savedDispatchLastMinutes = Number(localStorage.getItem('clueDispatchLastMinutes')) || 30;
     const savedAuthType = localStorage.getItem('authType') as 'official' | 'custom' || 'official';
     const savedAuthUsername = localStorage.getItem('authUsername') || '';
 
     setCookie(savedCookie);
     setStartTime(savedStartTime);
     setEndTime(savedEndTime);
+    setDispatchMode(savedDispatchMode);
+    setDispatchLastMinutes(savedDispatchLastMinutes);
     setAuthType(savedAuthType);
     setAuthUsername(savedAuthUsername);
 
-    // 如果已有cookie，获取用户信息
+    // 如果已有cookie，获取用户信息；本地没有时使用后端凭据存储中保存的Cookie
     if (savedCookie) {
       fetchUserInfo(savedCookie);
+    } else {
+      GetSavedCookie().then((storedCookie) => {
+        if (storedCookie) {
+          setCookie(storedCookie);
+          localStorage.setItem('serverCookie', storedCookie);
+          fetchUserInfo(storedCookie);
+        }
+      });
     }
 
+    GetSettingsError().then(setSettingsError);
+    GetPlatformTimezone().then(setPlatformTimezone);
+    GetNotificationSettings().then(setNotificationSettings);
+    GetSchedules().then(setSchedules);
+
     // 清理函数
     return () => {
       if (statusIntervalRef.current) {
@@ -272,6 +569,44 @@ export default function ClueClaimingComponent() {
   }, [fetchUserInfo]);
 
   
+  // 监听后端推送的自动认领事件
+  useEffect(() => {
+    const off = EventsOn('autoclaim:event', (event: ClaimEventType) => {
+      if (event.type === 'auth_expired') {
+        showToast(`Cookie 已失效，自动认领已停止: ${event.message}`, 'error');
+      } else if (event.type === 'cookie_expired' || event.type === 'license_expiring') {
+        showToast(event.message, 'warning');
+      } else if (event.type === 'stopped' && event.state === 'license_ended') {
+        showToast(`自动认领已停止: ${event.message}`, 'error');
+      }
+    });
+    // 服务器刷新Cookie后同步到输入框和本地存储
+    const offCookie = EventsOn('cookie:updated', (newCookie: string) => {
+      setCookie(newCookie);
+      localStorage.setItem('serverCookie', newCookie);
+    });
+    const offNotify = EventsOn('notify', showDesktopNotification);
+    return () => {
+      off();
+      offCookie();
+      offNotify();
+    };
+  }, []);
+
+  // 定时方案启动或停止会话时同步状态
+  useEffect(() => {
+    const off = EventsOn('schedule:changed', async (message: string) => {
+      showToast(message, 'info');
+      const response = await GetAutoClaimStatus();
+      setClaimStatus(response);
+      setAutoClaimingActive(response.isActive);
+      if (response.isActive && !statusIntervalRef.current) {
+        statusIntervalRef.current = setInterval(checkAutoClaimStatus, 2000);
+      }
+    });
+    return off;
+  }, [checkAutoClaimStatus]);
+
   // 当cookie或任务类型变化时加载标签数据
   useEffect(() => {
     if (cookie) {
@@ -281,6 +616,12 @@ export default function ClueClaimingComponent() {
 
   return (
     <div className="w-full mt-2">
+      {settingsError && (
+        <div className="alert alert-error text-sm mb-2">
+          配置文件无效，授权和自动认领已停用，请修正后重启：{settingsError}
+        </div>
+      )}
+
       {/* 授权设置弹窗 */}
       {showAuthModal && (
         <div className="modal modal-open">
@@ -333,7 +674,23 @@ export default function ClueClaimingComponent() {
                   <span className="label-text-alt text-info">
                     请输入您的官方授权用户名进行验证
                   </span>
+                  <button
+                    className="btn btn-outline btn-xs"
+                    onClick={queryAuthPlan}
+                    disabled={authPlanLoading}
+                  >
+                    {authPlanLoading ? <span className="loading loading-spinner loading-xs"></span> : '查询授权'}
+                  </button>
                 </label>
+                {authPlan && (
+                  <div className="p-2 bg-base-200 rounded text-xs space-y-1">
+                    <div>用户: {authPlan.userName}</div>
+                    <div>过期时间: {authPlan.expiresAt || '永久'}</div>
+                    <div>认领上限: {authPlan.limit > 0 ? `${authPlan.limit} 个` : '不限制'}</div>
+                    {authPlan.planType && <div>套餐类型: {authPlan.planType}</div>}
+                    <div>Coze: {authPlan.coze ? '已开通' : '未开通'}</div>
+                  </div>
+                )}
               </div>
             ) : (
               <div className="form-control">
@@ -380,19 +737,280 @@ export default function ClueClaimingComponent() {
       )}
 
 
+      {/* 通知设置弹窗 */}
+      {showNotificationModal && notificationSettings && (
+        <div className="modal modal-open">
+          <div className="modal-box max-w-sm">
+            <h3 className="font-bold text-lg mb-4">通知设置</h3>
+            {notificationToggles.map(({ key, label }) => (
+              <label key={key} className="label cursor-pointer">
+                <span className="label-text">{label}</span>
+                <input
+                  type="checkbox"
+                  className="toggle toggle-primary toggle-sm"
+                  checked={notificationSettings[key]}
+                  onChange={(e) => setNotificationSettings(main.NotificationSettings.createFrom({
+                    ...notificationSettings,
+                    [key]: e.target.checked,
+                  }))}
+                />
+              </label>
+            ))}
+            <div className="modal-action">
+              <button className="btn btn-primary" onClick={saveNotificationSettings}>
+                保存设置
+              </button>
+              <button className="btn btn-ghost" onClick={() => setShowNotificationModal(false)}>
+                取消
+              </button>
+            </div>
+          </div>
+          <div className="modal-backdrop">
+            <button onClick={() => setShowNotificationModal(false)}>close</button>
+          </div>
+        </div>
+      )}
+
+      {/* 导出认领记录弹窗 */}
+      {showExportModal && (
+        <div className="modal modal-open">
+          <div className="modal-box max-w-md">
+            <h3 className="font-bold text-lg mb-4">导出认领记录</h3>
+            <div className="grid grid-cols-2 gap-2">
+              <div className="form-control">
+                <label className="label"><span className="label-text">开始日期</span></label>
+                <input type="date" className="input input-bordered input-sm" value={exportSince} onChange={(e) => setExportSince(e.target.value)} />
+              </div>
+              <div className="form-control">
+                <label className="label"><span className="label-text">结束日期（含当天）</span></label>
+                <input type="date" className="input input-bordered input-sm" value={exportUntil} onChange={(e) => setExportUntil(e.target.value)} />
+              </div>
+            </div>
+            <div className="form-control mt-2">
+              <label className="label"><span className="label-text">账号（留空导出全部）</span></label>
+              <input type="text" className="input input-bordered input-sm" value={exportAccount} onChange={(e) => setExportAccount(e.target.value)} />
+            </div>
+            <div className="form-control mt-2">
+              <label className="label"><span className="label-text">格式</span></label>
+              <select className="select select-bordered select-sm" value={exportFormat} onChange={(e) => setExportFormat(e.target.value as 'csv' | 'xlsx')}>
+                <option value="xlsx">Excel（.xlsx）</option>
+                <option value="csv">CSV</option>
+              </select>
+            </div>
+            <div className="modal-action">
+              <button className="btn btn-ghost" onClick={() => setShowExportModal(false)}>
+                取消
+              </button>
+              <button className="btn btn-primary" onClick={exportHistory} disabled={exporting}>
+                {exporting && <span className="loading loading-spinner loading-xs mr-2"></span>}
+                导出
+              </button>
+            </div>
+          </div>
+          <div className="modal-backdrop">
+            <button onClick={() => setShowExportModal(false)}>close</button>
+          </div>
+        </div>
+      )}
+
+      {/* 定时方案弹窗 */}
+      {showScheduleModal && (
+        <div className="modal modal-open">
+          <div className="modal-box max-w-lg">
+            <h3 className="font-bold text-lg mb-4">定时方案</h3>
+            {schedules.length === 0 && (
+              <p className="text-sm text-base-content/60 mb-2">暂无定时方案</p>
+            )}
+            {schedules.map((schedule) => (
+              <div key={schedule.name} className="flex items-center justify-between gap-2 py-1 border-b border-base-200">
+                <div className="min-w-0">
+                  <div className="text-sm font-medium">{schedule.name}</div>
+                  <div className="text-xs text-base-content/60 truncate">{schedule.windows.join('；')}</div>
+                </div>
+                <div className="flex items-center gap-2 shrink-0">
+                  <input
+                    type="checkbox"
+                    className="toggle toggle-primary toggle-sm"
+                    checked={schedule.enabled}
+                    onChange={() => toggleSchedule(schedule)}
+                  />
+                  <button className="btn btn-ghost btn-xs text-error" onClick={() => removeSchedule(schedule.name)}>
+                    删除
+                  </button>
+                </div>
+              </div>
+            ))}
+
+            <div className="form-control mt-4">
+              <label className="label">
+                <span className="label-text">方案名称</span>
+              </label>
+              <input
+                type="text"
+                className="input input-bordered input-sm"
+                value={scheduleName}
+                onChange={(e) => setScheduleName(e.target.value)}
+                placeholder="例如：工作日上午"
+              />
+            </div>
+            <div className="form-control mt-2">
+              <label className="label">
+                <span className="label-text">时间窗口（每行一个）</span>
+              </label>
+              <textarea
+                className="textarea textarea-bordered textarea-sm font-mono"
+                rows={3}
+                value={scheduleWindows}
+                onChange={(e) => setScheduleWindows(e.target.value)}
+                placeholder={'1-5 09:00-11:30\n6,7 22:00-02:00\ncron 0 14 * * 1-5 90m'}
+              />
+              <label className="label">
+                <span className="label-text-alt text-base-content/60">星期 1-7 为周一到周日，省略表示每天；保存时使用当前的筛选和认领设置</span>
+              </label>
+            </div>
+            <div className="modal-action">
+              <button className="btn btn-primary" onClick={saveSchedule}>
+                保存为定时方案
+              </button>
+              <button className="btn btn-ghost" onClick={() => setShowScheduleModal(false)}>
+                关闭
+              </button>
+            </div>
+          </div>
+          <div className="modal-backdrop">
+            <button onClick={() => setShowScheduleModal(false)}>close</button>
+          </div>
+        </div>
+      )}
+
+      {/* 线索池趋势弹窗 */}
+      {poolReport && (
+        <div className="modal modal-open">
+          <div className="modal-box max-w-2xl">
+            <h3 className="font-bold text-lg mb-1">线索池趋势（最近 7 天）</h3>
+            {poolReport.snapshots === 0 ? (
+              <p className="text-sm text-base-content/60">
+                还没有线索池快照。在配置文件中设置 poolSnapshotInterval（秒）后运行自动认领即可开始记录。
+              </p>
+            ) : (
+              <>
+                <p className="text-sm text-base-content/70 mb-3">
+                  共 {poolReport.snapshots} 个快照
+                  {poolReport.peakHours.length > 0 && `；新任务最多的时段：${poolReport.peakHours.map(hour => `${String(hour).padStart(2, '0')}:00`).join('、')}`}
+                  {poolReport.measured > 0
+                    ? `；${poolReport.measured} 个任务的存活时间中位数 ${formatSeconds(poolReport.medianLifetimeSeconds)}，90% 在 ${formatSeconds(poolReport.p90LifetimeSeconds)} 内被认领`
+                    : '；还没有统计到任务的存活时间'}
+                </p>
+                <div className="max-h-80 overflow-y-auto">
+                  <table className="table table-xs">
+                    <thead>
+                      <tr><th>小时</th><th>快照数</th><th>新任务</th><th>平均总数</th></tr>
+                    </thead>
+                    <tbody>
+                      {poolReport.hours.filter(stat => stat.snapshots > 0).map(stat => (
+                        <tr key={stat.hour}>
+                          <td>{String(stat.hour).padStart(2, '0')}:00</td>
+                          <td>{stat.snapshots}</td>
+                          <td>{stat.newTasks}</td>
+                          <td>{stat.avgTotal.toFixed(1)}</td>
+                        </tr>
+                      ))}
+                    </tbody>
+                  </table>
+                </div>
+                {poolReport.subjects.length > 0 && (
+                  <p className="text-xs text-base-content/60 mt-2">
+                    学科（每页平均）：{poolReport.subjects.map(count => `${count.name} ${count.avg.toFixed(1)}`).join('，')}
+                  </p>
+                )}
+              </>
+            )}
+            <div className="modal-action">
+              <button className="btn btn-ghost" onClick={() => setPoolReport(null)}>
+                关闭
+              </button>
+            </div>
+          </div>
+          <div className="modal-backdrop">
+            <button onClick={() => setPoolReport(null)}>close</button>
+          </div>
+        </div>
+      )}
+
+      {/* 筛选预览弹窗 */}
+      {filterPreview && (
+        <div className="modal modal-open">
+          <div className="modal-box max-w-2xl">
+            <h3 className="font-bold text-lg mb-1">筛选预览</h3>
+            <p className="text-sm text-base-content/70 mb-3">
+              线索池共 {filterPreview.total} 个任务，预览 {filterPreview.tasks.length} 个，通过筛选 {filterPreview.matched} 个
+              {filterPreview.window && `；发布时间范围：${filterPreview.window}`}
+            </p>
+            {filterPreview.tasks.length === 0 && (
+              <p className="text-sm text-base-content/60">线索池中没有任务</p>
+            )}
+            <div className="max-h-96 overflow-y-auto">
+              {filterPreview.tasks.map((item) => (
+                <div key={`${item.page}-${item.task.taskID}-${item.task.clueID}`} className="flex items-start gap-2 py-1 border-b border-base-200">
+                  <span className={`badge badge-sm shrink-0 mt-0.5 ${item.decision.passed ? 'badge-success' : 'badge-ghost'}`}>
+                    {item.decision.passed ? '通过' : '排除'}
+                  </span>
+                  <div className="min-w-0">
+                    <div className="text-sm truncate">{item.task.brief}</div>
+                    <div className="text-xs text-base-content/60">
+                      {item.decision.reason}
+                      {item.task.dispatchTime && ` · 发布于 ${item.task.dispatchTime}`}
+                    </div>
+                  </div>
+                </div>
+              ))}
+            </div>
+            <div className="modal-action">
+              <button className="btn btn-ghost" onClick={() => setFilterPreview(null)}>
+                关闭
+              </button>
+            </div>
+          </div>
+          <div className="modal-backdrop">
+            <button onClick={() => setFilterPreview(null)}>close</button>
+          </div>
+        </div>
+      )}
+
       {/* 筛选配置区域 */}
       <div className="flex flex-col gap-4 mb-4">
         <div className="form-control">
           <div className="flex justify-between items-center mb-2">
             <span className="label-text text-sm font-medium">软件授权</span>
-            <button
-              className="btn btn-outline btn-xs btn-primary"
-              onClick={() => {
-                    setShowAuthModal(true);
-                }}
-            >
-              设置授权
-            </button>
+            <div className="flex gap-2">
+              <button
+                className="btn btn-outline btn-xs"
+                onClick={() => setShowScheduleModal(true)}
+              >
+                定时方案
+              </button>
+              <button
+                className="btn btn-outline btn-xs"
+                onClick={openExportModal}
+              >
+                导出记录
+              </button>
+              <button
+                className="btn btn-outline btn-xs"
+                onClick={() => setShowNotificationModal(true)}
+                disabled={!notificationSettings}
+              >
+                通知设置
+              </button>
+              <button
+                className="btn btn-outline btn-xs btn-primary"
+                onClick={() => {
+                      setShowAuthModal(true);
+                  }}
+              >
+                设置授权
+              </button>
+            </div>
           </div>
           <label className="label py-1 flex justify-between items-center">
             <span className="label-text text-sm font-medium">百度教育 Cookie</span>
@@ -405,6 +1023,27 @@ export default function ClueClaimingComponent() {
               {userInfoLoading && (
                 <span className="loading loading-spinner loading-xs"></span>
               )}
+              <button
+                type="button"
+                className="btn btn-ghost btn-xs"
+                onClick={() => cookieFileInputRef.current?.click()}
+                title="导入浏览器导出的 cookies.txt、HAR 或 JSON 文件"
+              >
+                导入
+              </button>
+              <input
+                ref={cookieFileInputRef}
+                type="file"
+                accept=".txt,.har,.json"
+                className="hidden"
+                onChange={(e) => {
+                  const file = e.target.files?.[0];
+                  if (file) {
+                    importCookieFile(file);
+                  }
+                  e.target.value = '';
+                }}
+              />
               <button
                 type="button"
                 className="text-info hover:text-primary btn btn-ghost btn-xs p-0 min-h-0 h-auto"
@@ -519,6 +1158,16 @@ export default function ClueClaimingComponent() {
       <div className="divider text-sm my-2">⚙️ 自动认领设置</div>
 
       <div className="flex flex-col gap-2">
+        <label className="flex items-center justify-between cursor-pointer">
+          <span className="text-sm font-medium">模拟运行（只筛选不认领）：</span>
+          <input
+            type="checkbox"
+            className="toggle toggle-sm toggle-warning"
+            checked={dryRun}
+            onChange={(e) => setDryRun(e.target.checked)}
+          />
+        </label>
+
         <div className="flex items-center justify-between">
           <span className="text-sm font-medium">认领上限：</span>
           <div className="flex items-center gap-2">
@@ -572,6 +1221,40 @@ export default function ClueClaimingComponent() {
         <>
           <div className="divider text-sm my-2">📅 发布时间过滤</div>
 
+          <div className="flex gap-2 mb-2">
+            <select
+              className="select select-bordered select-sm flex-1"
+              value={dispatchMode}
+              onChange={(e) => {
+                const mode = e.target.value as '' | 'today' | 'session' | 'last';
+                setDispatchMode(mode);
+                localStorage.setItem('clueDispatchMode', mode);
+              }}
+            >
+              <option value="">指定时间范围</option>
+              <option value="today">今天（每天自动更新）</option>
+              <option value="last">最近一段时间</option>
+              <option value="session">本次认领开始之后</option>
+            </select>
+            {dispatchMode === 'last' && (
+              <div className="join">
+                <input
+                  type="number"
+                  min={1}
+                  value={dispatchLastMinutes}
+                  onChange={(e) => {
+                    const minutes = Math.max(1, Number(e.target.value) || 1);
+                    setDispatchLastMinutes(minutes);
+                    localStorage.setItem('clueDispatchLastMinutes', String(minutes));
+                  }}
+                  className="input input-bordered input-sm join-item w-20"
+                />
+                <span className="btn btn-sm join-item no-animation">分钟</span>
+              </div>
+            )}
+          </div>
+
+          {dispatchMode === '' && (
           <div className="grid grid-cols-1 lg:grid-cols-3 gap-2">
             <div>
               <input
@@ -615,6 +1298,7 @@ export default function ClueClaimingComponent() {
               </button>
             </div>
           </div>
+          )}
         </>
       )}
 
@@ -729,7 +1413,12 @@ export default function ClueClaimingComponent() {
                 {excludeKeywords.length > 0 && `排除: ${excludeKeywords.join(', ')}`}
               </div>
             )}
-            {selectedTaskType === 'producetask' && (startTime || endTime) && (
+            {selectedTaskType === 'producetask' && dispatchMode !== '' && (
+              <div>
+                时间过滤: {dispatchMode === 'today' ? '今天' : dispatchMode === 'session' ? '本次认领开始之后' : `最近 ${dispatchLastMinutes} 分钟`}
+              </div>
+            )}
+            {selectedTaskType === 'producetask' && dispatchMode === '' && (startTime || endTime) && (
               <div>
                 时间过滤: {startTime ? `从 ${startTime.replace('T', ' ')}` : '无开始时间'} {endTime ? `到 ${endTime.replace('T', ' ')}` : '无结束时间'}
               </div>
@@ -742,13 +1431,53 @@ export default function ClueClaimingComponent() {
           <div className="mb-2 p-3 bg-base-100 rounded-lg shadow-sm">
             <div className="flex justify-between items-center">
               <span className="font-medium text-sm">📊 认领状态:</span>
-              <span className={`badge ${claimStatus.isActive ? 'badge-success' : 'badge-neutral'}`}>
-                {claimStatus.isActive ? '运行中' : '已停止'}
+              <span className={`badge ${claimStatus.isActive ? 'badge-success' : claimStatus.state === 'auth_expired' ? 'badge-error' : 'badge-neutral'}`}>
+                {claimStatus.isActive ? '运行中' : claimStatus.state === 'auth_expired' ? 'Cookie已失效' : '已停止'}
               </span>
             </div>
-            <div className="mt-2 text-sm">
-              成功认领: <span className="font-mono font-bold text-success">{claimStatus.successfulClaims}</span> 个任务
-            </div>
+            {claimStatus.dryRun ? (
+              <div className="mt-2 text-sm">
+                🧪 模拟运行: 通过筛选 <span className="font-mono font-bold text-warning">{claimStatus.dryRun.uniqueMatched}</span> 个任务
+                <div className="text-xs mt-1 text-base-content/70">
+                  已获取列表 {claimStatus.dryRun.polls} 次，返回 {claimStatus.dryRun.tasksSeen} 个，匹配 {claimStatus.dryRun.tasksMatched} 次
+                </div>
+              </div>
+            ) : (
+              <div className="mt-2 text-sm">
+                成功认领: <span className="font-mono font-bold text-success">{claimStatus.successfulClaims}</span> 个任务
+              </div>
+            )}
+            {claimStatus.stats && (
+              <div className="text-xs mt-1 text-base-content/70 space-y-0.5">
+                <div>
+                  运行 {formatCountdown(Math.floor(claimStatus.stats.uptimeSeconds))}，间隔 {claimStatus.stats.intervalSeconds} 秒，尝试 {claimStatus.stats.attempts} 次，活跃 {claimStatus.stats.activeTasks}/{claimStatus.stats.maxConcurrent}
+                </div>
+                <div>
+                  列表 {claimStatus.stats.listCalls} 次（平均 {claimStatus.stats.avgListLatencyMs.toFixed(0)} ms，p95 {claimStatus.stats.p95ListLatencyMs.toFixed(0)} ms），
+                  认领 {claimStatus.stats.claimCalls} 次（成功率 {(claimStatus.stats.successRate * 100).toFixed(0)}%，p95 {claimStatus.stats.p95ClaimLatencyMs.toFixed(0)} ms）
+                </div>
+                <div>
+                  任务：返回 {claimStatus.stats.tasksSeen}，通过筛选 {claimStatus.stats.tasksFiltered}，已认领 {claimStatus.stats.tasksClaimed}
+                  {Object.keys(claimStatus.stats.errors || {}).length > 0 &&
+                    `；错误：${Object.entries(claimStatus.stats.errors).map(([category, count]) => `${sessionErrorLabels[category] || category} ${count}`).join('，')}`}
+                </div>
+              </div>
+            )}
+            {authPlan && authPlan.limit > 0 && (
+              <div className="text-xs mt-1 text-base-content/70">
+                授权认领上限: {authPlan.limit} 个
+              </div>
+            )}
+            {claimStatus.licenseRemainingSeconds >= 0 && (
+              <div className={`text-xs mt-1 ${claimStatus.licenseRemainingSeconds < 3600 ? 'text-warning' : 'text-base-content/70'}`}>
+                授权剩余: {formatCountdown(claimStatus.licenseRemainingSeconds)}（{claimStatus.licenseExpiresAt} 过期）
+              </div>
+            )}
+            {!claimStatus.isActive && claimStatus.stopReason && (
+              <div className="text-xs mt-1 text-base-content/70">
+                停止原因: {claimStatus.stopReason}
+              </div>
+            )}
             {claimStatus.lastError && (
               <div className="text-error text-xs mt-1 bg-error/10 p-2 rounded">
                 ❌ {claimStatus.lastError}
@@ -757,6 +1486,36 @@ export default function ClueClaimingComponent() {
           </div>
         )}
 
+        {!autoClaimingActive && configErrors.length > 0 && (
+          <div className="alert alert-warning text-xs py-2 mb-2 flex-col items-start gap-1">
+            {configErrors.map((fieldError, index) => (
+              <div key={index}>⚠️ {fieldError.message}</div>
+            ))}
+          </div>
+        )}
+
+        {!autoClaimingActive && (
+          <button
+            className="btn btn-outline btn-sm w-full mb-2"
+            onClick={previewFilter}
+            disabled={previewLoading || isLoading || filterData.length === 0 || configErrors.length > 0}
+          >
+            {previewLoading ? <span className="loading loading-spinner loading-xs mr-2"></span> : '🔍 '}
+            预览筛选结果
+          </button>
+        )}
+
+        {!autoClaimingActive && (
+          <button
+            className="btn btn-ghost btn-sm w-full mb-2"
+            onClick={loadPoolReport}
+            disabled={poolReportLoading}
+          >
+            {poolReportLoading ? <span className="loading loading-spinner loading-xs mr-2"></span> : '📈 '}
+            线索池趋势
+          </button>
+        )}
+
         {/* 操作按钮 */}
         {autoClaimingActive ? (
           <button
@@ -772,7 +1531,7 @@ export default function ClueClaimingComponent() {
           <button
             className="btn btn-primary btn-sm w-full"
             onClick={() => startAutoClaiming()}
-            disabled={isClaimingButtonLoading || isLoading || filterData.length === 0}
+            disabled={isClaimingButtonLoading || isLoading || filterData.length === 0 || configErrors.length > 0 || settingsError !== ''}
           >
             {isClaimingButtonLoading ? (
               <>
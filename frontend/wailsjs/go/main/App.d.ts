@@ -2,14 +2,44 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 
+export function DeleteSchedule(arg1:string):Promise<void>;
+
+export function ExportClaimHistory(arg1:string,arg2:string,arg3:string,arg4:string):Promise<string>;
+
+export function GetAuthorizationPlan(arg1:string):Promise<main.AuthPlanResponse>;
+
 export function GetAutoClaimStatus():Promise<main.AutoClaimStatusResponse>;
 
+export function GetCurrentAuthorizationPlan():Promise<main.AuthPlanResponse>;
+
+export function GetNotificationSettings():Promise<main.NotificationSettings>;
+
+export function GetPlatformTimezone():Promise<string>;
+
+export function GetPoolReport(arg1:string,arg2:number):Promise<main.PoolReport>;
+
+export function GetSavedCookie():Promise<string>;
+
+export function GetSchedules():Promise<Array<main.ClaimSchedule>>;
+
+export function GetSettingsError():Promise<string>;
+
 export function GetTaskLabels(arg1:string,arg2:string):Promise<Record<string, any>>;
 
 export function GetUserInfo(arg1:string):Promise<Record<string, any>>;
 
 export function Greet(arg1:string):Promise<string>;
 
+export function ImportCookies(arg1:string):Promise<main.CookieImportResult>;
+
+export function PreviewFilter(arg1:main.AutoClaimConfig,arg2:number):Promise<main.FilterPreviewResponse>;
+
+export function SaveNotificationSettings(arg1:main.NotificationSettings):Promise<void>;
+
+export function SaveSchedule(arg1:main.ClaimSchedule):Promise<void>;
+
 export function StartAutoClaiming(arg1:main.AutoClaimConfig):Promise<main.AutoClaimResponse>;
 
 export function StopAutoClaiming():Promise<main.AutoClaimResponse>;
+
+export function ValidateAutoClaimConfig(arg1:main.AutoClaimConfig):Promise<Array<main.FieldError>>;
@@ -2,10 +2,50 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function DeleteSchedule(arg1) {
+  return window['go']['main']['App']['DeleteSchedule'](arg1);
+}
+
+export function ExportClaimHistory(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['ExportClaimHistory'](arg1, arg2, arg3, arg4);
+}
+
+export function GetAuthorizationPlan(arg1) {
+  return window['go']['main']['App']['GetAuthorizationPlan'](arg1);
+}
+
 export function GetAutoClaimStatus() {
   return window['go']['main']['App']['GetAutoClaimStatus']();
 }
 
+export function GetCurrentAuthorizationPlan() {
+  return window['go']['main']['App']['GetCurrentAuthorizationPlan']();
+}
+
+export function GetNotificationSettings() {
+  return window['go']['main']['App']['GetNotificationSettings']();
+}
+
+export function GetPlatformTimezone() {
+  return window['go']['main']['App']['GetPlatformTimezone']();
+}
+
+export function GetPoolReport(arg1, arg2) {
+  return window['go']['main']['App']['GetPoolReport'](arg1, arg2);
+}
+
+export function GetSavedCookie() {
+  return window['go']['main']['App']['GetSavedCookie']();
+}
+
+export function GetSchedules() {
+  return window['go']['main']['App']['GetSchedules']();
+}
+
+export function GetSettingsError() {
+  return window['go']['main']['App']['GetSettingsError']();
+}
+
 export function GetTaskLabels(arg1, arg2) {
   return window['go']['main']['App']['GetTaskLabels'](arg1, arg2);
 }
@@ -18,6 +58,22 @@ export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
 
+export function ImportCookies(arg1) {
+  return window['go']['main']['App']['ImportCookies'](arg1);
+}
+
+export function PreviewFilter(arg1, arg2) {
+  return window['go']['main']['App']['PreviewFilter'](arg1, arg2);
+}
+
+export function SaveNotificationSettings(arg1) {
+  return window['go']['main']['App']['SaveNotificationSettings'](arg1);
+}
+
+export function SaveSchedule(arg1) {
+  return window['go']['main']['App']['SaveSchedule'](arg1);
+}
+
 export function StartAutoClaiming(arg1) {
   return window['go']['main']['App']['StartAutoClaiming'](arg1);
 }
@@ -25,3 +81,7 @@ export function StartAutoClaiming(arg1) {
 export function StopAutoClaiming() {
   return window['go']['main']['App']['StopAutoClaiming']();
 }
+
+export function ValidateAutoClaimConfig(arg1) {
+  return window['go']['main']['App']['ValidateAutoClaimConfig'](arg1);
+}
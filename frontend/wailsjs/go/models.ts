@@ -1,5 +1,29 @@
 export namespace main {
 	
+	export class AuthPlanResponse {
+	    success: boolean;
+	    message: string;
+	    userName: string;
+	    expiresAt: string;
+	    limit: number;
+	    planType: string;
+	    coze: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuthPlanResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.userName = source["userName"];
+	        this.expiresAt = source["expiresAt"];
+	        this.limit = source["limit"];
+	        this.planType = source["planType"];
+	        this.coze = source["coze"];
+	    }
+	}
 	export class AutoClaimConfig {
 	    ServerBaseURL: string;
 	    Cookie: string;
@@ -15,8 +39,12 @@ export namespace main {
 	    ExcludeKeywords: string[];
 	    StartTime: string;
 	    EndTime: string;
+	    dispatchWindow?: string;
+	    dryRun?: boolean;
 	    authType: string;
 	    authUsername: string;
+	    authCheckInterval?: number;
+	    licenseCheckInterval?: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new AutoClaimConfig(source);
@@ -38,14 +66,19 @@ export namespace main {
 	        this.ExcludeKeywords = source["ExcludeKeywords"];
 	        this.StartTime = source["StartTime"];
 	        this.EndTime = source["EndTime"];
+	        this.dispatchWindow = source["dispatchWindow"];
+	        this.dryRun = source["dryRun"];
 	        this.authType = source["authType"];
 	        this.authUsername = source["authUsername"];
+	        this.authCheckInterval = source["authCheckInterval"];
+	        this.licenseCheckInterval = source["licenseCheckInterval"];
 	    }
 	}
 	export class AutoClaimResponse {
 	    success: boolean;
 	    message: string;
 	    taskId?: string;
+	    errors?: FieldError[];
 	
 	    static createFrom(source: any = {}) {
 	        return new AutoClaimResponse(source);
@@ -56,7 +89,26 @@ export namespace main {
 	        this.success = source["success"];
 	        this.message = source["message"];
 	        this.taskId = source["taskId"];
+	        this.errors = this.convertValues(source["errors"], FieldError);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class AutoClaimStatusResponse {
 	    success: boolean;
@@ -64,6 +116,14 @@ export namespace main {
 	    isActive: boolean;
 	    successfulClaims: number;
 	    lastError: string;
+	    state: string;
+	    stopReason: string;
+	    licenseExpiresAt: string;
+	    licenseRemainingSeconds: number;
+	    sessionId: string;
+	    dryRun?: DryRunSummary;
+	    lastResponse?: ClaimResponse;
+	    stats?: SessionStats;
 	
 	    static createFrom(source: any = {}) {
 	        return new AutoClaimStatusResponse(source);
@@ -76,8 +136,463 @@ export namespace main {
 	        this.isActive = source["isActive"];
 	        this.successfulClaims = source["successfulClaims"];
 	        this.lastError = source["lastError"];
+	        this.state = source["state"];
+	        this.stopReason = source["stopReason"];
+	        this.licenseExpiresAt = source["licenseExpiresAt"];
+	        this.licenseRemainingSeconds = source["licenseRemainingSeconds"];
+	        this.sessionId = source["sessionId"];
+	        this.dryRun = this.convertValues(source["dryRun"], DryRunSummary);
+	        this.lastResponse = this.convertValues(source["lastResponse"], ClaimResponse);
+	        this.stats = this.convertValues(source["stats"], SessionStats);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CategoryCount {
+	    name: string;
+	    avg: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CategoryCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.avg = source["avg"];
+	    }
+	}
+	export class ClaimResponse {
+	    errno: number;
+	    errmsg: string;
+	    data: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ClaimResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.errno = source["errno"];
+	        this.errmsg = source["errmsg"];
+	        this.data = source["data"];
+	    }
+	}
+	export class ClaimSchedule {
+	    name: string;
+	    enabled: boolean;
+	    windows: string[];
+	    config: AutoClaimConfig;
+	
+	    static createFrom(source: any = {}) {
+	        return new ClaimSchedule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.enabled = source["enabled"];
+	        this.windows = source["windows"];
+	        this.config = this.convertValues(source["config"], AutoClaimConfig);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CookieImportResult {
+	    format: string;
+	    cookie: string;
+	    names: string[];
+	    skipped: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CookieImportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.cookie = source["cookie"];
+	        this.names = source["names"];
+	        this.skipped = source["skipped"];
+	    }
+	}
+	export class DryRunSummary {
+	    polls: number;
+	    tasksSeen: number;
+	    tasksMatched: number;
+	    uniqueMatched: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DryRunSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.polls = source["polls"];
+	        this.tasksSeen = source["tasksSeen"];
+	        this.tasksMatched = source["tasksMatched"];
+	        this.uniqueMatched = source["uniqueMatched"];
+	    }
+	}
+	export class FieldError {
+	    field: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.message = source["message"];
+	    }
+	}
+	export class FilterDecision {
+	    passed: boolean;
+	    rule: string;
+	    keyword?: string;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FilterDecision(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.passed = source["passed"];
+	        this.rule = source["rule"];
+	        this.keyword = source["keyword"];
+	        this.reason = source["reason"];
 	    }
 	}
+	export class FilterPreview {
+	    total: number;
+	    matched: number;
+	    window: string;
+	    tasks: TaskPreview[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FilterPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.total = source["total"];
+	        this.matched = source["matched"];
+	        this.window = source["window"];
+	        this.tasks = this.convertValues(source["tasks"], TaskPreview);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FilterPreviewResponse {
+	    success: boolean;
+	    message: string;
+	    errors?: FieldError[];
+	    preview?: FilterPreview;
+	
+	    static createFrom(source: any = {}) {
+	        return new FilterPreviewResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.errors = this.convertValues(source["errors"], FieldError);
+	        this.preview = this.convertValues(source["preview"], FilterPreview);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class HourlyPoolStat {
+	    hour: number;
+	    snapshots: number;
+	    newTasks: number;
+	    avgTotal: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HourlyPoolStat(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hour = source["hour"];
+	        this.snapshots = source["snapshots"];
+	        this.newTasks = source["newTasks"];
+	        this.avgTotal = source["avgTotal"];
+	    }
+	}
+	export class NotificationSettings {
+	    batchClaimed: boolean;
+	    limitReached: boolean;
+	    cookieExpired: boolean;
+	    sound: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new NotificationSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.batchClaimed = source["batchClaimed"];
+	        this.limitReached = source["limitReached"];
+	        this.cookieExpired = source["cookieExpired"];
+	        this.sound = source["sound"];
+	    }
+	}
+	export class PoolReport {
+	    taskType: string;
+	    // Go type: time
+	    from: any;
+	    // Go type: time
+	    to: any;
+	    snapshots: number;
+	    hours: HourlyPoolStat[];
+	    peakHours: number[];
+	    measured: number;
+	    medianLifetimeSeconds: number;
+	    p90LifetimeSeconds: number;
+	    subjects: CategoryCount[];
+	    steps: CategoryCount[];
+	    clueTypes: CategoryCount[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PoolReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.taskType = source["taskType"];
+	        this.from = this.convertValues(source["from"], null);
+	        this.to = this.convertValues(source["to"], null);
+	        this.snapshots = source["snapshots"];
+	        this.hours = this.convertValues(source["hours"], HourlyPoolStat);
+	        this.peakHours = source["peakHours"];
+	        this.measured = source["measured"];
+	        this.medianLifetimeSeconds = source["medianLifetimeSeconds"];
+	        this.p90LifetimeSeconds = source["p90LifetimeSeconds"];
+	        this.subjects = this.convertValues(source["subjects"], CategoryCount);
+	        this.steps = this.convertValues(source["steps"], CategoryCount);
+	        this.clueTypes = this.convertValues(source["clueTypes"], CategoryCount);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SessionStats {
+	    // Go type: time
+	    startedAt: any;
+	    uptimeSeconds: number;
+	    intervalSeconds: number;
+	    attempts: number;
+	    activeTasks: number;
+	    maxConcurrent: number;
+	    listCalls: number;
+	    claimCalls: number;
+	    successRate: number;
+	    avgListLatencyMs: number;
+	    p95ListLatencyMs: number;
+	    avgClaimLatencyMs: number;
+	    p95ClaimLatencyMs: number;
+	    tasksSeen: number;
+	    tasksFiltered: number;
+	    tasksClaimed: number;
+	    errors: Record<string, number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new SessionStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.startedAt = this.convertValues(source["startedAt"], null);
+	        this.uptimeSeconds = source["uptimeSeconds"];
+	        this.intervalSeconds = source["intervalSeconds"];
+	        this.attempts = source["attempts"];
+	        this.activeTasks = source["activeTasks"];
+	        this.maxConcurrent = source["maxConcurrent"];
+	        this.listCalls = source["listCalls"];
+	        this.claimCalls = source["claimCalls"];
+	        this.successRate = source["successRate"];
+	        this.avgListLatencyMs = source["avgListLatencyMs"];
+	        this.p95ListLatencyMs = source["p95ListLatencyMs"];
+	        this.avgClaimLatencyMs = source["avgClaimLatencyMs"];
+	        this.p95ClaimLatencyMs = source["p95ClaimLatencyMs"];
+	        this.tasksSeen = source["tasksSeen"];
+	        this.tasksFiltered = source["tasksFiltered"];
+	        this.tasksClaimed = source["tasksClaimed"];
+	        this.errors = source["errors"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TaskItem {
+	    taskID: number;
+	    clueID: number;
+	    brief: string;
+	    step: number;
+	    subject: number;
+	    state: number;
+	    stepName: string;
+	    subjectName: string;
+	    clueType: number;
+	    clueTypeName: string;
+	    stateName: string;
+	    createTime: string;
+	    dispatchTime: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.taskID = source["taskID"];
+	        this.clueID = source["clueID"];
+	        this.brief = source["brief"];
+	        this.step = source["step"];
+	        this.subject = source["subject"];
+	        this.state = source["state"];
+	        this.stepName = source["stepName"];
+	        this.subjectName = source["subjectName"];
+	        this.clueType = source["clueType"];
+	        this.clueTypeName = source["clueTypeName"];
+	        this.stateName = source["stateName"];
+	        this.createTime = source["createTime"];
+	        this.dispatchTime = source["dispatchTime"];
+	    }
+	}
+	export class TaskPreview {
+	    page: number;
+	    task: TaskItem;
+	    decision: FilterDecision;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.page = source["page"];
+	        this.task = this.convertValues(source["task"], TaskItem);
+	        this.decision = this.convertValues(source["decision"], FilterDecision);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 
 }
 
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ClaimRecord 是一条认领成功的任务记录
+type ClaimRecord struct {
+	TaskID       int          `json:"taskId"`
+	ClueID       int          `json:"clueId"`
+	TaskType     string       `json:"taskType"`
+	Brief        string       `json:"brief"`
+	Step         string       `json:"step"`
+	Subject      string       `json:"subject"`
+	ClueType     string       `json:"clueType"`
+	DispatchTime PlatformTime `json:"dispatchTime"`
+	ClaimedAt    time.Time    `json:"claimedAt"`
+	Account      string       `json:"account"`   // 认领使用的百度教育账号
+	SessionID    string       `json:"sessionId"` // 所属的认领会话
+}
+
+// newClaimRecord 根据认领成功的任务生成记录
+func newClaimRecord(task TaskItem, taskType, account, sessionID string, claimedAt time.Time) ClaimRecord {
+	return ClaimRecord{
+		TaskID:       task.TaskID,
+		ClueID:       task.ClueID,
+		TaskType:     taskType,
+		Brief:        task.Brief,
+		Step:         task.StepName,
+		Subject:      task.SubjectName,
+		ClueType:     task.ClueTypeName,
+		DispatchTime: task.DispatchTime,
+		ClaimedAt:    claimedAt,
+		Account:      account,
+		SessionID:    sessionID,
+	}
+}
+
+// HistoryFilter 是查询认领记录的条件，零值表示不限制
+type HistoryFilter struct {
+	Since     time.Time
+	Until     time.Time
+	Account   string
+	SessionID string
+	Limit     int // 只返回最近的 Limit 条
+}
+
+// match 判断记录是否满足条件
+func (f HistoryFilter) match(record ClaimRecord) bool {
+	if !f.Since.IsZero() && record.ClaimedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !record.ClaimedAt.Before(f.Until) {
+		return false
+	}
+	if f.Account != "" && record.Account != f.Account {
+		return false
+	}
+	if f.SessionID != "" && record.SessionID != f.SessionID {
+		return false
+	}
+	return true
+}
+
+// ClaimHistory 将认领记录追加保存在 JSON Lines 文件中，重启后仍可查询
+type ClaimHistory struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewClaimHistory 创建保存在指定路径的认领记录
+func NewClaimHistory(path string) *ClaimHistory {
+	return &ClaimHistory{path: path}
+}
+
+// DefaultClaimHistory 返回保存在应用配置目录下的认领记录
+func DefaultClaimHistory() (*ClaimHistory, error) {
+	dir, err := appConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewClaimHistory(filepath.Join(dir, "claim-history.jsonl")), nil
+}
+
+// Append 追加一条认领记录
+func (h *ClaimHistory) Append(record ClaimRecord) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("打开认领记录失败: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("保存认领记录失败: %v", err)
+	}
+	return nil
+}
+
+// List 按认领时间顺序返回满足条件的记录，文件不存在时返回空列表
+func (h *ClaimHistory) List(filter HistoryFilter) ([]ClaimRecord, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	f, err := os.Open(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []ClaimRecord{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取认领记录失败: %v", err)
+	}
+	defer f.Close()
+
+	records := []ClaimRecord{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record ClaimRecord
+		// 跳过写入中断产生的不完整行
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		if filter.match(record) {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取认领记录失败: %v", err)
+	}
+
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[len(records)-filter.Limit:]
+	}
+	return records, nil
+}
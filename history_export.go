@@ -0,0 +1,186 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 认领记录的导出格式
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+)
+
+// historyHeader 是导出文件的表头，与 historyRow 的列一一对应
+var historyHeader = []string{"任务ID", "线索ID", "简介", "学科", "学段", "线索类型", "发布时间", "认领时间", "账号", "会话"}
+
+// historyRow 返回一条记录的各列，时间按平台时区格式化
+func historyRow(record ClaimRecord) []string {
+	return []string{
+		strconv.Itoa(record.TaskID),
+		strconv.Itoa(record.ClueID),
+		record.Brief,
+		record.Subject,
+		record.Step,
+		record.ClueType,
+		record.DispatchTime.String(),
+		record.ClaimedAt.In(PlatformLocation()).Format(platformTimeLayout),
+		record.Account,
+		record.SessionID,
+	}
+}
+
+// ParseExportFormat 检查导出格式，为空时根据文件名的扩展名判断，默认为 CSV
+func ParseExportFormat(format, filename string) (string, error) {
+	if format == "" {
+		if strings.HasSuffix(strings.ToLower(filename), ".xlsx") {
+			return ExportXLSX, nil
+		}
+		return ExportCSV, nil
+	}
+	switch format = strings.ToLower(format); format {
+	case ExportCSV, ExportXLSX:
+		return format, nil
+	}
+	return "", fmt.Errorf("不支持的导出格式: %s（可选 csv、xlsx）", format)
+}
+
+// ExportFilter 根据日期范围和账号生成查询条件。since、until 可以是 RFC3339 时间，
+// 或按平台时区解析的日期、日期时间；until 只写日期时包含当天
+func ExportFilter(since, until, account string) (HistoryFilter, error) {
+	filter := HistoryFilter{Account: account}
+	var err error
+	if filter.Since, _, err = parseExportTime(since); err != nil {
+		return filter, err
+	}
+	var dateOnly bool
+	if filter.Until, dateOnly, err = parseExportTime(until); err != nil {
+		return filter, err
+	}
+	if dateOnly {
+		filter.Until = filter.Until.AddDate(0, 0, 1)
+	}
+	return filter, nil
+}
+
+// parseExportTime 解析导出的时间范围，dateOnly 表示只写了日期，空字符串返回零值
+func parseExportTime(value string) (t time.Time, dateOnly bool, err error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, PlatformLocation()); err == nil {
+		return t, true, nil
+	}
+	parsed, err := ParsePlatformTime(value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("无效的时间: %s", value)
+	}
+	return parsed.Time, false, nil
+}
+
+// ExportHistory 将认领记录按 format 写入 w
+func ExportHistory(w io.Writer, format string, records []ClaimRecord) error {
+	switch format {
+	case ExportCSV:
+		return writeHistoryCSV(w, records)
+	case ExportXLSX:
+		return writeHistoryXLSX(w, records)
+	}
+	return fmt.Errorf("不支持的导出格式: %s", format)
+}
+
+// writeHistoryCSV 写入带 UTF-8 BOM 的 CSV，Excel 打开时中文不会乱码
+func writeHistoryCSV(w io.Writer, records []ClaimRecord) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(historyHeader); err != nil {
+		return err
+	}
+	for _, record := range records {
+		row := historyRow(record)
+		for i, value := range row {
+			row[i] = csvSafe(value)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvSafe 避免以 =、+、-、@ 开头的简介在 Excel 中被当作公式执行
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// xlsxParts 是最小 XLSX 文件中除工作表以外的固定部分
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="认领记录" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+// writeHistoryXLSX 写入只有一个工作表的 XLSX，文本使用内联字符串，任务 ID 和线索 ID 为数字
+func writeHistoryXLSX(w io.Writer, records []ClaimRecord) error {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		f, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	writeXLSXRow(&b, 1, historyHeader, false)
+	for i, record := range records {
+		writeXLSXRow(&b, i+2, historyRow(record), true)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	if _, err := io.WriteString(sheet, b.String()); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// writeXLSXRow 写入一行单元格，numericIDs 为 true 时前两列（任务 ID、线索 ID）写为数字
+func writeXLSXRow(b *strings.Builder, row int, values []string, numericIDs bool) {
+	fmt.Fprintf(b, `<row r="%d">`, row)
+	for col, value := range values {
+		ref := fmt.Sprintf("%c%d", 'A'+col, row)
+		if numericIDs && col < 2 {
+			fmt.Fprintf(b, `<c r="%s"><v>%s</v></c>`, ref, value)
+			continue
+		}
+		fmt.Fprintf(b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+		xml.EscapeText(b, []byte(value))
+		b.WriteString(`</t></is></c>`)
+	}
+	b.WriteString(`</row>`)
+}
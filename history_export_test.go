@@ -0,0 +1,203 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testClaimRecords(t *testing.T) []ClaimRecord {
+	t.Helper()
+	return []ClaimRecord{
+		{
+			TaskID:       101,
+			ClueID:       9001,
+			Brief:        "=HYPERLINK(\"http://example.com\")",
+			Subject:      "数学",
+			Step:         "初中",
+			ClueType:     "错题",
+			DispatchTime: mustPlatformTime(t, "2025-01-07 08:00:00"),
+			ClaimedAt:    mustPlatformTime(t, "2025-01-07 09:30:00").Time,
+			Account:      "alice",
+			SessionID:    "20250107-093000",
+		},
+		{
+			TaskID:    102,
+			ClueID:    9002,
+			Brief:     "<函数> & 图像",
+			ClaimedAt: mustPlatformTime(t, "2025-01-07 23:59:59").Time,
+			Account:   "bob",
+		},
+	}
+}
+
+func TestExportHistoryCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportHistory(&buf, ExportCSV, testClaimRecords(t)); err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+	data, ok := bytes.CutPrefix(buf.Bytes(), []byte("\ufeff"))
+	if !ok {
+		t.Fatal("CSV 应以 UTF-8 BOM 开头")
+	}
+
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("解析 CSV 失败: %v", err)
+	}
+	if len(rows) != 3 || !slices.Equal(rows[0], historyHeader) {
+		t.Fatalf("CSV 行 = %q", rows)
+	}
+	want := []string{"101", "9001", "'=HYPERLINK(\"http://example.com\")", "数学", "初中", "错题",
+		"2025-01-07 08:00:00", "2025-01-07 09:30:00", "alice", "20250107-093000"}
+	if !slices.Equal(rows[1], want) {
+		t.Errorf("第一条记录 = %q\n期望 %q", rows[1], want)
+	}
+	if rows[2][6] != "" {
+		t.Errorf("没有发布时间时应为空，得到 %q", rows[2][6])
+	}
+}
+
+func TestCSVSafe(t *testing.T) {
+	tests := map[string]string{
+		"":         "",
+		"数学 函数":    "数学 函数",
+		"=1+1":     "'=1+1",
+		"+86 123":  "'+86 123",
+		"-2":       "'-2",
+		"@SUM(A1)": "'@SUM(A1)",
+		"a=b":      "a=b",
+		" =不是公式开头": " =不是公式开头",
+	}
+	for value, want := range tests {
+		if got := csvSafe(value); got != want {
+			t.Errorf("csvSafe(%q) = %q，期望 %q", value, got, want)
+		}
+	}
+}
+
+// xlsxSheet 是 sheet1.xml 中测试关心的部分
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func TestExportHistoryXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportHistory(&buf, ExportXLSX, testClaimRecords(t)); err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("XLSX 不是有效的 zip: %v", err)
+	}
+
+	files := map[string]*zip.File{}
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	for _, part := range xlsxParts {
+		if files[part.name] == nil {
+			t.Errorf("缺少 %s", part.name)
+		}
+	}
+	sheetFile := files["xl/worksheets/sheet1.xml"]
+	if sheetFile == nil {
+		t.Fatal("缺少 xl/worksheets/sheet1.xml")
+	}
+	r, err := sheetFile.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sheet xlsxSheet
+	if err := xml.Unmarshal(data, &sheet); err != nil {
+		t.Fatalf("解析工作表失败: %v", err)
+	}
+	if len(sheet.Rows) != 3 {
+		t.Fatalf("工作表行数 = %d，期望 3", len(sheet.Rows))
+	}
+
+	header := sheet.Rows[0]
+	if header.R != 1 || len(header.Cells) != len(historyHeader) || header.Cells[0].Inline != "任务ID" {
+		t.Errorf("表头 = %+v", header)
+	}
+
+	row := sheet.Rows[2]
+	if row.R != 3 || row.Cells[0].Ref != "A3" || row.Cells[9].Ref != "J3" {
+		t.Errorf("单元格位置 = %+v", row)
+	}
+	if id := row.Cells[0]; id.Type != "" || id.Value != "102" {
+		t.Errorf("任务 ID 应为数字单元格，得到 %+v", id)
+	}
+	if brief := row.Cells[2]; brief.Type != "inlineStr" || brief.Inline != "<函数> & 图像" {
+		t.Errorf("简介 = %+v", brief)
+	}
+	if claimedAt := row.Cells[7].Inline; claimedAt != "2025-01-07 23:59:59" {
+		t.Errorf("认领时间 = %q", claimedAt)
+	}
+	// XLSX 单元格不会被当作公式执行，内容保持原样
+	if brief := sheet.Rows[1].Cells[2].Inline; !strings.HasPrefix(brief, "=HYPERLINK") {
+		t.Errorf("简介 = %q", brief)
+	}
+}
+
+func TestExportFilterUsesPlatformDays(t *testing.T) {
+	filter, err := ExportFilter("2025-01-07", "2025-01-07", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mustPlatformTime(t, "2025-01-07 00:00:00").Time; !filter.Since.Equal(want) {
+		t.Errorf("Since = %s，期望 %s", filter.Since, want)
+	}
+	if want := mustPlatformTime(t, "2025-01-08 00:00:00").Time; !filter.Until.Equal(want) {
+		t.Errorf("Until = %s，期望 %s", filter.Until, want)
+	}
+	for _, record := range testClaimRecords(t) {
+		record.Account = "alice"
+		if !filter.match(record) {
+			t.Errorf("%s 的记录应包含在当天内", record.ClaimedAt)
+		}
+	}
+	if filter.match(ClaimRecord{Account: "alice", ClaimedAt: mustPlatformTime(t, "2025-01-08 00:00:00").Time}) {
+		t.Error("次日零点的记录不应包含在内")
+	}
+
+	filter, err = ExportFilter("", "2025-01-07 12:00:00", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mustPlatformTime(t, "2025-01-07 12:00:00").Time; !filter.Since.IsZero() || !filter.Until.Equal(want) {
+		t.Errorf("指定时刻时不应延长到次日，得到 %s - %s", filter.Since, filter.Until)
+	}
+
+	filter, err = ExportFilter("2025-01-07T09:00:00Z", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2025, 1, 7, 9, 0, 0, 0, time.UTC); !filter.Since.Equal(want) {
+		t.Errorf("RFC3339 的 Since = %s", filter.Since)
+	}
+
+	if _, err := ExportFilter("2025/01/07", "", ""); err == nil {
+		t.Error("无效的日期应返回错误")
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// licenseWarningWindow 是授权即将过期时发出提醒的提前量
+const licenseWarningWindow = time.Hour
+
+// LicenseChecker 重新验证软件授权，通常是 Authorizer.Authorize 的闭包
+type LicenseChecker func(ctx context.Context) (*AuthResult, error)
+
+// licenseWatch 保存会话的授权状态
+type licenseWatch struct {
+	mutex     sync.Mutex
+	expiresAt time.Time       // 零值表示不过期
+	requested AutoClaimConfig // 按授权上限收紧前的配置，复核时据此重新计算上限
+	recheck   LicenseChecker
+}
+
+// expired 判断授权在 now 时是否已过期
+func (l *licenseWatch) expired(now time.Time) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return !l.expiresAt.IsZero() && !now.Before(l.expiresAt)
+}
+
+// WithLicense 让会话在运行期间定期复核授权，并在授权过期时停止。
+// requested 是按授权上限收紧前的配置，复核到方案变更时据此重新应用上限
+func WithLicense(result *AuthResult, requested AutoClaimConfig, recheck LicenseChecker) AutoClaimerOption {
+	return func(ac *AutoClaimer) {
+		if result == nil {
+			return
+		}
+		ac.license = &licenseWatch{expiresAt: result.ExpiresAt, requested: requested, recheck: recheck}
+	}
+}
+
+// licenseLoop 定期复核授权，在过期前发出提醒并在过期时停止会话
+func (ac *AutoClaimer) licenseLoop(ctx context.Context) {
+	ticker := ac.clock.NewTicker(time.Duration(ac.config.LicenseCheckInterval * float64(time.Second)))
+	defer ticker.Stop()
+
+	var expiryTimer, warnTimer Timer
+	resetTimers := func() {
+		if expiryTimer != nil {
+			expiryTimer.Stop()
+		}
+		if warnTimer != nil {
+			warnTimer.Stop()
+		}
+		expiryTimer, warnTimer = nil, nil
+
+		ac.license.mutex.Lock()
+		expiresAt := ac.license.expiresAt
+		ac.license.mutex.Unlock()
+		if expiresAt.IsZero() {
+			return
+		}
+		now := ac.clock.Now()
+		expiryTimer = ac.clock.NewTimer(expiresAt.Sub(now))
+		warnTimer = ac.clock.NewTimer(expiresAt.Add(-licenseWarningWindow).Sub(now))
+	}
+	resetTimers()
+	defer func() {
+		if expiryTimer != nil {
+			expiryTimer.Stop()
+			warnTimer.Stop()
+		}
+	}()
+
+	for {
+		// nil 通道永远不会就绪，授权不过期时只做定期复核
+		var expiryC, warnC <-chan time.Time
+		if expiryTimer != nil {
+			expiryC, warnC = expiryTimer.C(), warnTimer.C()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-warnC:
+			ac.warnLicenseExpiring()
+		case <-expiryC:
+			ac.license.mutex.Lock()
+			expiresAt := ac.license.expiresAt
+			ac.license.mutex.Unlock()
+			ac.stop(SessionLicenseEnded, fmt.Sprintf("授权已过期，过期时间: %s", expiresAt.Local().Format("2006-01-02 15:04:05")))
+			return
+		case <-ticker.C():
+			if ac.recheckLicense(ctx) {
+				resetTimers()
+			}
+		}
+	}
+}
+
+// warnLicenseExpiring 发出授权即将过期的提醒
+func (ac *AutoClaimer) warnLicenseExpiring() {
+	ac.license.mutex.Lock()
+	expiresAt := ac.license.expiresAt
+	ac.license.mutex.Unlock()
+
+	message := fmt.Sprintf("授权即将过期，过期时间: %s", expiresAt.Local().Format("2006-01-02 15:04:05"))
+	ac.logf("%s", message)
+	ac.emit(ClaimEvent{Type: EventLicenseExpiring, Message: message})
+}
+
+// recheckLicense 重新验证授权并按最新方案调整上限，返回过期时间是否发生变化。
+// 授权被明确拒绝时停止会话，网络错误则等待下次复核
+func (ac *AutoClaimer) recheckLicense(ctx context.Context) bool {
+	if ac.license.recheck == nil {
+		return false
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	result, err := ac.license.recheck(checkCtx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		if IsAuthDenied(err) {
+			ac.stop(SessionLicenseEnded, fmt.Sprintf("授权复核未通过: %v", err))
+			return false
+		}
+		ac.logf("授权复核失败，稍后重试：%v", err)
+		return false
+	}
+
+	ac.applyLicenseLimits(result)
+
+	ac.license.mutex.Lock()
+	previous := ac.license.expiresAt
+	changed := !result.ExpiresAt.Equal(previous)
+	ac.license.expiresAt = result.ExpiresAt
+	ac.license.mutex.Unlock()
+	if !changed {
+		return false
+	}
+
+	ac.mutex.Lock()
+	ac.status.LicenseExpiresAt = result.ExpiresAt
+	ac.mutex.Unlock()
+
+	var message string
+	switch {
+	case result.ExpiresAt.IsZero():
+		message = "授权已续期，不再过期"
+	case result.ExpiresAt.After(previous):
+		message = fmt.Sprintf("授权已续期，新的过期时间: %s", result.ExpiresAt.Local().Format("2006-01-02 15:04:05"))
+	default:
+		return true
+	}
+	ac.logf("%s", message)
+	ac.emit(ClaimEvent{Type: EventLicenseRenewed, Message: message})
+	return true
+}
+
+// applyLicenseLimits 按复核得到的授权重新计算认领上限和并发数，
+// 方案升级时放宽到用户配置的值，降级时立即收紧
+func (ac *AutoClaimer) applyLicenseLimits(result *AuthResult) {
+	config := ac.license.requested
+	result.ApplyLimits(&config)
+	config = withConfigDefaults(config)
+
+	ac.mutex.Lock()
+	changed := config.ClaimLimit != ac.config.ClaimLimit || config.ConcurrentClaims != ac.config.ConcurrentClaims
+	ac.config.ClaimLimit = config.ClaimLimit
+	ac.config.ConcurrentClaims = config.ConcurrentClaims
+	ac.maxConcurrent = maxConcurrentFor(config.ConcurrentClaims)
+	ac.metrics.setConcurrency(ac.activeTasks, ac.maxConcurrent)
+	ac.mutex.Unlock()
+	if !changed {
+		return
+	}
+
+	message := fmt.Sprintf("授权方案已变更，认领上限调整为 %d，并发数调整为 %d", config.ClaimLimit, config.ConcurrentClaims)
+	ac.logf("%s", message)
+	ac.emit(ClaimEvent{Type: EventLicenseRenewed, Message: message})
+}
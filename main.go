@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -12,6 +13,11 @@ import (
 var assets embed.FS
 
 func main() {
+	// 带子命令运行时以无界面模式执行
+	if code, handled := runCommand(os.Args[1:]); handled {
+		os.Exit(code)
+	}
+
 	// Create an instance of the app structure
 	app := NewApp()
 
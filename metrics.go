@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// latencyBuckets 是请求耗时直方图的分桶上限（秒）
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// labelValueEscaper 按 Prometheus 文本格式转义标签值，只转义反斜杠、双引号和换行
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// histogram 是 Prometheus 风格的累积直方图
+type histogram struct {
+	buckets []float64
+	counts  []uint64 // counts[i] 为不超过 buckets[i] 的观测数，最后一项为 +Inf
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets)+1)}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, upper := range h.buckets {
+		if v <= upper {
+			h.counts[i]++
+		}
+	}
+	h.counts[len(h.buckets)]++
+	h.sum += v
+	h.count++
+}
+
+func (h *histogram) write(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for i, upper := range h.buckets {
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", name, strconv.FormatFloat(upper, 'g', -1, 64), h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.counts[len(h.buckets)])
+	fmt.Fprintf(w, "%s_sum %s\n", name, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count %d\n", name, h.count)
+}
+
+// Metrics 统计认领过程的吞吐和耗时，以 Prometheus 文本格式输出。
+// 由 SessionManager 持有，跨会话累计；nil 的 *Metrics 可以安全调用，不做任何统计
+type Metrics struct {
+	mutex          sync.Mutex
+	listRequests   uint64
+	listErrors     uint64
+	claimAttempts  uint64
+	claimSuccesses uint64
+	claimFailures  map[string]uint64 // 按 errno 统计，请求失败记为 request_error
+	tasksSeen      uint64
+	tasksMatched   uint64
+	activeTasks    int
+	maxConcurrent  int
+	listLatency    *histogram
+	claimLatency   *histogram
+}
+
+// NewMetrics 创建空的统计
+func NewMetrics() *Metrics {
+	return &Metrics{
+		claimFailures: map[string]uint64{},
+		listLatency:   newHistogram(latencyBuckets),
+		claimLatency:  newHistogram(latencyBuckets),
+	}
+}
+
+// WithMetrics 让会话把统计数据记录到 m
+func WithMetrics(m *Metrics) AutoClaimerOption {
+	return func(ac *AutoClaimer) {
+		ac.metrics = m
+	}
+}
+
+// observeList 记录一次任务列表请求
+func (m *Metrics) observeList(elapsed time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.listRequests++
+	if err != nil {
+		m.listErrors++
+	}
+	m.listLatency.observe(elapsed.Seconds())
+}
+
+// observeTasks 记录列表中的任务数和通过筛选的任务数
+func (m *Metrics) observeTasks(seen, matched int) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.tasksSeen += uint64(seen)
+	m.tasksMatched += uint64(matched)
+}
+
+// observeClaim 记录一次认领请求，claimed 为成功认领的任务数
+func (m *Metrics) observeClaim(elapsed time.Duration, res *ClaimResponse, err error, claimed int) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.claimAttempts++
+	m.claimLatency.observe(elapsed.Seconds())
+	switch {
+	case err != nil:
+		m.claimFailures["request_error"]++
+	case claimed > 0:
+		m.claimSuccesses += uint64(claimed)
+	default:
+		// errno 为 0 但没有认领到任务时记为 "0"，通常是任务已被他人认领
+		m.claimFailures[strconv.Itoa(res.Errno)]++
+	}
+}
+
+// setConcurrency 更新当前活跃的认领协程数和上限
+func (m *Metrics) setConcurrency(active, limit int) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.activeTasks = active
+	m.maxConcurrent = limit
+}
+
+// WritePrometheus 以 Prometheus 文本格式输出所有指标
+func (m *Metrics) WritePrometheus(w io.Writer) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	counter := func(name, help string, v uint64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, v)
+	}
+	gauge := func(name, help string, v int) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", name, help, name, name, v)
+	}
+
+	counter("bedu_claim_list_requests_total", "任务列表请求次数", m.listRequests)
+	counter("bedu_claim_list_errors_total", "任务列表请求失败次数", m.listErrors)
+	counter("bedu_claim_claim_attempts_total", "认领请求次数", m.claimAttempts)
+	counter("bedu_claim_claim_successes_total", "成功认领的任务数", m.claimSuccesses)
+
+	fmt.Fprintf(w, "# HELP bedu_claim_claim_failures_total 未认领成功的请求数，按 errno 区分\n# TYPE bedu_claim_claim_failures_total counter\n")
+	errnos := make([]string, 0, len(m.claimFailures))
+	for errno := range m.claimFailures {
+		errnos = append(errnos, errno)
+	}
+	sort.Strings(errnos)
+	for _, errno := range errnos {
+		fmt.Fprintf(w, "bedu_claim_claim_failures_total{errno=\"%s\"} %d\n", labelValueEscaper.Replace(errno), m.claimFailures[errno])
+	}
+
+	counter("bedu_claim_tasks_seen_total", "任务列表中返回的任务数", m.tasksSeen)
+	counter("bedu_claim_tasks_matched_total", "通过筛选条件的任务数", m.tasksMatched)
+	gauge("bedu_claim_active_tasks", "当前活跃的认领协程数", m.activeTasks)
+	gauge("bedu_claim_max_concurrent", "活跃认领协程数上限", m.maxConcurrent)
+	m.listLatency.write(w, "bedu_claim_list_duration_seconds", "任务列表请求耗时")
+	m.claimLatency.write(w, "bedu_claim_claim_duration_seconds", "认领请求耗时")
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsWritePrometheus(t *testing.T) {
+	m := NewMetrics()
+	m.observeList(250*time.Millisecond, nil)
+	m.observeList(500*time.Millisecond, errors.New("timeout"))
+	m.observeList(20*time.Second, nil)
+	m.observeTasks(10, 4)
+	m.observeClaim(time.Second, nil, errors.New("connection reset"), 0)
+	m.observeClaim(time.Second, &ClaimResponse{Errno: 0}, nil, 0)
+	m.observeClaim(time.Second, &ClaimResponse{Errno: 1001}, nil, 0)
+	m.observeClaim(time.Second, &ClaimResponse{Errno: 1001}, nil, 0)
+	m.observeClaim(time.Second, &ClaimResponse{Errno: 0}, nil, 3)
+	m.setConcurrency(2, 8)
+	m.claimFailures["a\"b\\c\nd"] = 1 // 标签值中的特殊字符
+
+	var buf strings.Builder
+	m.WritePrometheus(&buf)
+	out := buf.String()
+
+	blocks := []string{
+		"# HELP bedu_claim_list_requests_total 任务列表请求次数\n# TYPE bedu_claim_list_requests_total counter\nbedu_claim_list_requests_total 3\n",
+		"# TYPE bedu_claim_list_errors_total counter\nbedu_claim_list_errors_total 1\n",
+		"# TYPE bedu_claim_claim_attempts_total counter\nbedu_claim_claim_attempts_total 5\n",
+		"# TYPE bedu_claim_claim_successes_total counter\nbedu_claim_claim_successes_total 3\n",
+		"# TYPE bedu_claim_tasks_seen_total counter\nbedu_claim_tasks_seen_total 10\n",
+		"# TYPE bedu_claim_tasks_matched_total counter\nbedu_claim_tasks_matched_total 4\n",
+		"# TYPE bedu_claim_active_tasks gauge\nbedu_claim_active_tasks 2\n",
+		"# TYPE bedu_claim_max_concurrent gauge\nbedu_claim_max_concurrent 8\n",
+		// errno 按字典序输出，标签值按 Prometheus 规则转义
+		"# TYPE bedu_claim_claim_failures_total counter\n" +
+			"bedu_claim_claim_failures_total{errno=\"0\"} 1\n" +
+			"bedu_claim_claim_failures_total{errno=\"1001\"} 2\n" +
+			"bedu_claim_claim_failures_total{errno=\"a\\\"b\\\\c\\nd\"} 1\n" +
+			"bedu_claim_claim_failures_total{errno=\"request_error\"} 1\n",
+		// 桶是累积的，超过最大分桶的观测只计入 +Inf
+		"# HELP bedu_claim_list_duration_seconds 任务列表请求耗时\n# TYPE bedu_claim_list_duration_seconds histogram\n" +
+			"bedu_claim_list_duration_seconds_bucket{le=\"0.005\"} 0\n" +
+			"bedu_claim_list_duration_seconds_bucket{le=\"0.01\"} 0\n" +
+			"bedu_claim_list_duration_seconds_bucket{le=\"0.025\"} 0\n" +
+			"bedu_claim_list_duration_seconds_bucket{le=\"0.05\"} 0\n" +
+			"bedu_claim_list_duration_seconds_bucket{le=\"0.1\"} 0\n" +
+			"bedu_claim_list_duration_seconds_bucket{le=\"0.25\"} 1\n" +
+			"bedu_claim_list_duration_seconds_bucket{le=\"0.5\"} 2\n" +
+			"bedu_claim_list_duration_seconds_bucket{le=\"1\"} 2\n" +
+			"bedu_claim_list_duration_seconds_bucket{le=\"2.5\"} 2\n" +
+			"bedu_claim_list_duration_seconds_bucket{le=\"5\"} 2\n" +
+			"bedu_claim_list_duration_seconds_bucket{le=\"10\"} 2\n" +
+			"bedu_claim_list_duration_seconds_bucket{le=\"+Inf\"} 3\n" +
+			"bedu_claim_list_duration_seconds_sum 20.75\n" +
+			"bedu_claim_list_duration_seconds_count 3\n",
+		"bedu_claim_claim_duration_seconds_bucket{le=\"0.5\"} 0\nbedu_claim_claim_duration_seconds_bucket{le=\"1\"} 5\n",
+		"bedu_claim_claim_duration_seconds_bucket{le=\"+Inf\"} 5\nbedu_claim_claim_duration_seconds_sum 5\nbedu_claim_claim_duration_seconds_count 5\n",
+	}
+	for _, block := range blocks {
+		if !strings.Contains(out, block) {
+			t.Errorf("输出中缺少:\n%s\n完整输出:\n%s", block, out)
+		}
+	}
+}
+
+func TestNilMetricsIgnoresObservations(t *testing.T) {
+	var m *Metrics
+	m.observeList(time.Second, nil)
+	m.observeTasks(1, 1)
+	m.observeClaim(time.Second, &ClaimResponse{}, nil, 1)
+	m.setConcurrency(1, 4)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// ErrNativeNotifyUnsupported 表示当前系统没有可用的系统通知工具
+var ErrNativeNotifyUnsupported = errors.New("当前系统不支持系统通知")
+
+// windowsToastScript 通过 WinRT 显示 Windows 通知，标题和正文从环境变量读取，避免拼接到脚本中
+const windowsToastScript = `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$texts = $template.GetElementsByTagName('text')
+$texts.Item(0).AppendChild($template.CreateTextNode($env:BEDU_NOTIFY_TITLE)) > $null
+$texts.Item(1).AppendChild($template.CreateTextNode($env:BEDU_NOTIFY_BODY)) > $null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('bedu-claim').Show([Windows.UI.Notifications.ToastNotification]::new($template))`
+
+// macNotifyScript 通过 AppleScript 显示 macOS 通知，标题和正文同样从环境变量读取
+const macNotifyScript = `display notification (system attribute "BEDU_NOTIFY_BODY") with title (system attribute "BEDU_NOTIFY_TITLE")`
+
+// nativeNotifyCommand 返回在 goos 上显示系统通知的命令和参数，标题和正文通过 BEDU_NOTIFY_TITLE、BEDU_NOTIFY_BODY 环境变量传递
+func nativeNotifyCommand(goos string) (string, []string, error) {
+	switch goos {
+	case "windows":
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", windowsToastScript}, nil
+	case "darwin":
+		return "osascript", []string{"-e", macNotifyScript}, nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		// notify-send 不读取环境变量，由 sh 展开后作为独立参数传入
+		return "sh", []string{"-c", `exec notify-send --app-name=bedu-claim -- "$BEDU_NOTIFY_TITLE" "$BEDU_NOTIFY_BODY"`}, nil
+	}
+	return "", nil, ErrNativeNotifyUnsupported
+}
+
+// runNativeNotify 执行通知命令并返回输出，测试中替换以检查构造的命令
+var runNativeNotify = func(cmd *exec.Cmd) ([]byte, error) {
+	return cmd.CombinedOutput()
+}
+
+// newNativeNotifyCmd 构造在 goos 上显示通知的命令，标题和正文通过环境变量传递
+func newNativeNotifyCmd(ctx context.Context, goos, title, body string) (*exec.Cmd, error) {
+	name, args, err := nativeNotifyCommand(goos)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), "BEDU_NOTIFY_TITLE="+title, "BEDU_NOTIFY_BODY="+body)
+	hideConsoleWindow(cmd)
+	return cmd, nil
+}
+
+// sendNativeNotification 使用系统自带的通知工具显示通知，工具不存在或执行失败时返回错误
+func sendNativeNotification(title, body string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cmd, err := newNativeNotifyCmd(ctx, runtime.GOOS, title, body)
+	if err != nil {
+		return err
+	}
+	if output, err := runNativeNotify(cmd); err != nil {
+		return fmt.Errorf("显示系统通知失败: %v %s", err, output)
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+import "os/exec"
+
+// hideConsoleWindow 只在 Windows 上需要隐藏控制台窗口
+func hideConsoleWindow(cmd *exec.Cmd) {}
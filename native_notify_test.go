@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNativeNotifyCommandDoesNotEmbedText(t *testing.T) {
+	for _, goos := range []string{"windows", "darwin", "linux"} {
+		name, args, err := nativeNotifyCommand(goos)
+		if err != nil || name == "" {
+			t.Fatalf("%s: %q %v", goos, name, err)
+		}
+		script := strings.Join(args, " ")
+		if !strings.Contains(script, "BEDU_NOTIFY_TITLE") || !strings.Contains(script, "BEDU_NOTIFY_BODY") {
+			t.Errorf("%s 的命令应从环境变量读取标题和正文: %s", goos, script)
+		}
+	}
+	if _, _, err := nativeNotifyCommand("plan9"); err != ErrNativeNotifyUnsupported {
+		t.Errorf("不支持的系统应返回 ErrNativeNotifyUnsupported，得到 %v", err)
+	}
+}
+
+func TestNewNativeNotifyCmd(t *testing.T) {
+	tests := []struct {
+		goos string
+		args []string // 除脚本外的参数
+	}{
+		{"windows", []string{"powershell", "-NoProfile", "-NonInteractive", "-Command"}},
+		{"darwin", []string{"osascript", "-e"}},
+		{"linux", []string{"sh", "-c"}},
+		{"freebsd", []string{"sh", "-c"}},
+	}
+	title, body := "认领成功", "已认领 3 个"
+	for _, tt := range tests {
+		cmd, err := newNativeNotifyCmd(context.Background(), tt.goos, title, body)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.goos, err)
+		}
+		if len(cmd.Args) != len(tt.args)+1 || !slices.Equal(cmd.Args[:len(tt.args)], tt.args) {
+			t.Errorf("%s 的命令 = %q，期望以 %q 开头", tt.goos, cmd.Args, tt.args)
+		}
+		if script := cmd.Args[len(cmd.Args)-1]; strings.Contains(script, title) || strings.Contains(script, body) {
+			t.Errorf("%s 的脚本不应包含通知文本: %s", tt.goos, script)
+		}
+		// 后出现的同名变量生效，保证不会被进程环境中的旧值覆盖
+		env := cmd.Env[len(cmd.Env)-2:]
+		if !slices.Equal(env, []string{"BEDU_NOTIFY_TITLE=" + title, "BEDU_NOTIFY_BODY=" + body}) {
+			t.Errorf("%s 的环境变量 = %q", tt.goos, env)
+		}
+		if len(cmd.Env) != len(os.Environ())+2 {
+			t.Errorf("%s 应继承进程环境变量", tt.goos)
+		}
+	}
+	if _, err := newNativeNotifyCmd(context.Background(), "plan9", title, body); err != ErrNativeNotifyUnsupported {
+		t.Errorf("不支持的系统应返回 ErrNativeNotifyUnsupported，得到 %v", err)
+	}
+}
+
+func TestSendNativeNotificationReportsOutput(t *testing.T) {
+	if _, _, err := nativeNotifyCommand(runtime.GOOS); err != nil {
+		t.Skip("当前系统不支持系统通知")
+	}
+	run := runNativeNotify
+	t.Cleanup(func() { runNativeNotify = run })
+
+	var ran *exec.Cmd
+	runNativeNotify = func(cmd *exec.Cmd) ([]byte, error) {
+		ran = cmd
+		return []byte("no notification daemon"), errors.New("exit status 1")
+	}
+	err := sendNativeNotification("标题", "正文")
+	if err == nil || !strings.Contains(err.Error(), "no notification daemon") {
+		t.Errorf("错误应包含命令输出，得到 %v", err)
+	}
+	if ran == nil || !slices.Contains(ran.Env, "BEDU_NOTIFY_TITLE=标题") {
+		t.Errorf("执行的命令 = %v", ran)
+	}
+}
+
+func TestSendNativeNotificationPassesTextLiterally(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("只在 Linux 上用替身 notify-send 测试")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "args")
+	script := "#!/bin/sh\nfor arg in \"$@\"; do printf '%s\\n' \"$arg\"; done > " + out + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "notify-send"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	title, body := `认领成功 "$(touch pwned)"`, "已认领 3 个; `id`"
+	if err := sendNativeNotification(title, body); err != nil {
+		t.Fatalf("发送通知失败: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "--app-name=bedu-claim\n--\n" + title + "\n" + body + "\n"
+	if string(data) != want {
+		t.Errorf("notify-send 参数 = %q，期望 %q", data, want)
+	}
+	if _, err := os.Stat("pwned"); err == nil {
+		t.Error("标题被当作命令执行")
+	}
+}
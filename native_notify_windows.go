@@ -0,0 +1,14 @@
+package main
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// createNoWindow 是 Win32 的 CREATE_NO_WINDOW 进程创建标志，syscall 包中没有定义
+const createNoWindow = 0x08000000
+
+// hideConsoleWindow 避免 GUI 程序启动 powershell 时闪出控制台窗口
+func hideConsoleWindow(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true, CreationFlags: createNoWindow}
+}
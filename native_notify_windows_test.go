@@ -0,0 +1,16 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewNativeNotifyCmdHidesConsoleWindow(t *testing.T) {
+	cmd, err := newNativeNotifyCmd(context.Background(), "windows", "标题", "正文")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attr := cmd.SysProcAttr; attr == nil || !attr.HideWindow || attr.CreationFlags&createNoWindow == 0 {
+		t.Errorf("SysProcAttr = %+v，应隐藏控制台窗口", attr)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import "fmt"
+
+// NotifyEventName 是推送给前端显示桌面通知的事件名
+const NotifyEventName = "notify"
+
+// NotificationSettings 是桌面通知的开关
+type NotificationSettings struct {
+	BatchClaimed  bool `json:"batchClaimed"`  // 一轮认领成功
+	LimitReached  bool `json:"limitReached"`  // 达到认领上限
+	CookieExpired bool `json:"cookieExpired"` // Cookie 失效
+	Sound         bool `json:"sound"`         // 通知时播放提示音
+}
+
+// DesktopNotification 是桌面通知，由后端通过系统通知显示后推送给前端播放提示音
+type DesktopNotification struct {
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Level     string `json:"level"` // success、warning 或 error
+	Sound     bool   `json:"sound"`
+	Delivered bool   `json:"delivered"` // 已显示为系统通知；为 false 时前端改为窗口内提示
+}
+
+// notificationFor 根据开关判断事件是否需要桌面通知
+func (n NotificationSettings) notificationFor(event ClaimEvent) (DesktopNotification, bool) {
+	var notification DesktopNotification
+	switch {
+	case event.Type == EventBatchClaimed && n.BatchClaimed:
+		notification = DesktopNotification{Title: "认领成功", Level: "success"}
+	case event.Type == EventStopped && event.State == SessionLimitReached && n.LimitReached:
+		notification = DesktopNotification{Title: "已达到认领上限", Level: "success"}
+	case (event.Type == EventAuthExpired || event.Type == EventCookieExpired) && n.CookieExpired:
+		notification = DesktopNotification{Title: "Cookie 已失效", Level: "error"}
+	default:
+		return notification, false
+	}
+	notification.Body = fmt.Sprintf("%s（已认领 %d 个）", event.Message, event.Claimed)
+	notification.Sound = n.Sound
+	return notification, true
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // Windows 等没有时区数据库的系统也能加载 Asia/Shanghai
+)
+
+// DefaultPlatformTimezone 是平台时间戳所在的时区，平台返回的时间不带时区信息
+const DefaultPlatformTimezone = "Asia/Shanghai"
+
+// platformTimeLayout 是平台任务时间和 StartTime/EndTime 的格式
+const platformTimeLayout = "2006-01-02 15:04:05"
+
+var (
+	platformLocationMutex sync.RWMutex
+	platformLocation      = mustLoadLocation(DefaultPlatformTimezone)
+)
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// SetPlatformTimezone 设置解析平台时间使用的时区，name 为空时使用默认时区
+func SetPlatformTimezone(name string) error {
+	if name == "" {
+		name = DefaultPlatformTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("无效的时区 %q: %v", name, err)
+	}
+	platformLocationMutex.Lock()
+	platformLocation = loc
+	platformLocationMutex.Unlock()
+	return nil
+}
+
+// PlatformLocation 返回当前使用的平台时区
+func PlatformLocation() *time.Location {
+	platformLocationMutex.RLock()
+	defer platformLocationMutex.RUnlock()
+	return platformLocation
+}
+
+// PlatformTime 是平台返回的不带时区的时间，按平台时区解析。
+// JSON 中仍以 "2006-01-02 15:04:05" 字符串表示，零值对应空字符串
+type PlatformTime struct {
+	time.Time
+}
+
+// ParsePlatformTime 按平台时区解析时间，空字符串返回零值
+func ParsePlatformTime(value string) (PlatformTime, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return PlatformTime{}, nil
+	}
+	loc := PlatformLocation()
+	for _, layout := range []string{platformTimeLayout, "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return PlatformTime{t}, nil
+		}
+	}
+	return PlatformTime{}, fmt.Errorf("无法解析时间 %q", value)
+}
+
+// String 以平台时区格式化时间，零值返回空字符串
+func (t PlatformTime) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(PlatformLocation()).Format(platformTimeLayout)
+}
+
+// MarshalJSON 输出平台时区的时间字符串
+func (t PlatformTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON 解析平台时间字符串。无法识别的格式按零值处理，
+// 避免单个任务的异常字段导致整个任务列表解析失败
+func (t *PlatformTime) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*t = PlatformTime{}
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := ParsePlatformTime(value)
+	if err != nil {
+		parsed = PlatformTime{}
+	}
+	*t = parsed
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// setPlatformTimezone 在测试期间使用 name 作为平台时区，结束时恢复默认时区
+func setPlatformTimezone(t *testing.T, name string) {
+	t.Helper()
+
+	if err := SetPlatformTimezone(name); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetPlatformTimezone("") })
+}
+
+func TestParsePlatformTimeUsesPlatformZone(t *testing.T) {
+	tests := []struct {
+		zone  string
+		value string
+		want  time.Time
+	}{
+		{"", "2025-01-07 09:30:15", time.Date(2025, 1, 7, 1, 30, 15, 0, time.UTC)},
+		{"", "2025-01-07 09:30", time.Date(2025, 1, 7, 1, 30, 0, 0, time.UTC)},
+		{"", "2025-01-07T09:30:15", time.Date(2025, 1, 7, 1, 30, 15, 0, time.UTC)},
+		{"", " 2025-01-07 ", time.Date(2025, 1, 6, 16, 0, 0, 0, time.UTC)},
+		{"America/New_York", "2025-01-07 09:30:15", time.Date(2025, 1, 7, 14, 30, 15, 0, time.UTC)},
+		{"America/New_York", "2025-07-07 09:30", time.Date(2025, 7, 7, 13, 30, 0, 0, time.UTC)}, // 夏令时
+		{"UTC", "2025-01-07", time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		setPlatformTimezone(t, tt.zone)
+		got, err := ParsePlatformTime(tt.value)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("时区 %q 下解析 %q = %s, %v，期望 %s", tt.zone, tt.value, got.Time, err, tt.want)
+		}
+	}
+
+	if got, err := ParsePlatformTime("  "); err != nil || !got.IsZero() {
+		t.Errorf("空字符串应返回零值，得到 %s, %v", got.Time, err)
+	}
+	if _, err := ParsePlatformTime("2025/01/07"); err == nil {
+		t.Error("无法识别的格式应返回错误")
+	}
+	if err := SetPlatformTimezone("Mars/Olympus"); err == nil {
+		t.Error("无效的时区应返回错误")
+	}
+}
+
+func TestPlatformTimeJSON(t *testing.T) {
+	setPlatformTimezone(t, "America/New_York")
+
+	type task struct {
+		CreateTime PlatformTime `json:"createTime"`
+	}
+	// 以其他时区构造的时间也按平台时区输出
+	in := task{CreateTime: PlatformTime{time.Date(2025, 1, 7, 14, 30, 15, 0, time.UTC)}}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"createTime":"2025-01-07 09:30:15"}` {
+		t.Errorf("序列化结果 = %s", data)
+	}
+	var out task
+	if err := json.Unmarshal(data, &out); err != nil || !out.CreateTime.Equal(in.CreateTime.Time) {
+		t.Errorf("往返后 = %s, %v", out.CreateTime.Time, err)
+	}
+
+	if data, _ := json.Marshal(task{}); string(data) != `{"createTime":""}` {
+		t.Errorf("零值序列化结果 = %s", data)
+	}
+}
+
+func TestPlatformTimeUnmarshalIsTolerant(t *testing.T) {
+	for _, raw := range []string{`null`, `""`, `"0000-00-00 00:00:00"`, `"昨天"`} {
+		value := PlatformTime{time.Now()}
+		if err := json.Unmarshal([]byte(raw), &value); err != nil || !value.IsZero() {
+			t.Errorf("解析 %s = %s, %v，期望零值且不报错", raw, value.Time, err)
+		}
+	}
+
+	// 整个任务列表不会因为单个字段异常而解析失败
+	var list TaskListData
+	err := json.Unmarshal([]byte(`{"total":2,"list":[{"taskID":1,"createTime":"异常"},{"taskID":2,"createTime":"2025-01-07 09:30:15"}]}`), &list)
+	if err != nil || len(list.List) != 2 || !list.List[0].CreateTime.IsZero() || list.List[1].CreateTime.IsZero() {
+		t.Errorf("任务列表 = %+v, %v", list, err)
+	}
+
+	var value PlatformTime
+	if err := json.Unmarshal([]byte(`12345`), &value); err == nil {
+		t.Error("非字符串的值应返回错误")
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// pocketBaseTimeLayouts 是 PocketBase 各版本可能返回的时间格式，日期和时间之间的 T 已统一为空格。
+// Go 在解析时会自动接受任意位数的小数秒，因此这里不需要为每种精度单独列出格式
+var pocketBaseTimeLayouts = []struct {
+	layout string
+	zoned  bool // 格式中是否带时区，不带时区时按 UTC 处理
+}{
+	{"2006-01-02 15:04:05Z07:00", true},
+	{"2006-01-02 15:04:05Z0700", true},
+	{"2006-01-02 15:04:05 Z07:00", true},
+	{"2006-01-02 15:04:05", false},
+	{"2006-01-02 15:04Z07:00", true},
+	{"2006-01-02 15:04", false},
+	{"2006-01-02", false},
+}
+
+// parsePocketBaseTime 解析 PocketBase 返回的时间，例如 "2025-01-07 11:06:37.080Z"、
+// "2025-01-07 11:06:37Z"、"2025-01-07T11:06:37.123456+08:00"。
+// PocketBase 以 UTC 保存时间，没有时区的值按 UTC 处理；返回值为 UTC 时间
+func parsePocketBaseTime(value string) (time.Time, error) {
+	s := strings.TrimSpace(value)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("时间为空")
+	}
+	if len(s) > 10 && (s[10] == 'T' || s[10] == 't') {
+		s = s[:10] + " " + s[11:]
+	}
+	if strings.HasSuffix(s, "z") {
+		s = s[:len(s)-1] + "Z"
+	}
+
+	for _, l := range pocketBaseTimeLayouts {
+		var t time.Time
+		var err error
+		if l.zoned {
+			t, err = time.Parse(l.layout, s)
+		} else {
+			t, err = time.ParseInLocation(l.layout, s, time.UTC)
+		}
+		if err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法识别的时间格式: %q", value)
+}
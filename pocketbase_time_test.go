@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePocketBaseTime(t *testing.T) {
+	want := time.Date(2025, 1, 7, 11, 6, 37, 0, time.UTC)
+
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"2025-01-07 11:06:37.080Z", want.Add(80 * time.Millisecond)},
+		{"2025-01-07 11:06:37.08Z", want.Add(80 * time.Millisecond)},
+		{"2025-01-07 11:06:37.123456Z", want.Add(123456 * time.Microsecond)},
+		{"2025-01-07 11:06:37.123456789Z", want.Add(123456789 * time.Nanosecond)},
+		{"2025-01-07 11:06:37Z", want},
+		{"2025-01-07T11:06:37Z", want},
+		{"2025-01-07T11:06:37.080Z", want.Add(80 * time.Millisecond)},
+		{"2025-01-07t11:06:37z", want},
+		{"2025-01-07T19:06:37+08:00", want},
+		{"2025-01-07 19:06:37.000+08:00", want},
+		{"2025-01-07 19:06:37+0800", want},
+		{"2025-01-07 11:06:37", want},
+		{"2025-01-07 11:06:37.5", want.Add(500 * time.Millisecond)},
+		{"  2025-01-07 11:06:37.080Z  ", want.Add(80 * time.Millisecond)},
+		{"2025-01-07 11:06Z", want.Add(-37 * time.Second)},
+		{"2025-01-07", time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parsePocketBaseTime(tt.input)
+		if err != nil {
+			t.Errorf("parsePocketBaseTime(%q) 返回错误: %v", tt.input, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parsePocketBaseTime(%q) = %v, 期望 %v", tt.input, got, tt.want)
+		}
+		if got.Location() != time.UTC {
+			t.Errorf("parsePocketBaseTime(%q) 时区为 %v, 期望 UTC", tt.input, got.Location())
+		}
+	}
+}
+
+func TestParsePocketBaseTimeInvalid(t *testing.T) {
+	for _, input := range []string{"", "   ", "not a time", "2025/01/07 11:06:37", "2025-13-07 11:06:37Z", "11:06:37"} {
+		if got, err := parsePocketBaseTime(input); err == nil {
+			t.Errorf("parsePocketBaseTime(%q) = %v, 期望返回错误", input, got)
+		}
+	}
+}
+
+// 过期判断比较的是时间点，与本地时区无关
+func TestParsePocketBaseTimeComparesAcrossZones(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	expires, err := parsePocketBaseTime("2025-01-07 11:06:37.000Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before := time.Date(2025, 1, 7, 19, 6, 36, 0, shanghai)
+	after := time.Date(2025, 1, 7, 19, 6, 38, 0, shanghai)
+	if before.After(expires) {
+		t.Errorf("北京时间 %v 应早于过期时间 %v", before, expires)
+	}
+	if !after.After(expires) {
+		t.Errorf("北京时间 %v 应晚于过期时间 %v", after, expires)
+	}
+	if got := expires.In(shanghai).Format("2006-01-02 15:04:05"); got != "2025-01-07 19:06:37" {
+		t.Errorf("转换为北京时间后为 %s，期望 2025-01-07 19:06:37", got)
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PoolSnapshot 是某一时刻线索池中一页任务列表的快照。
+// Total 是整个线索池的任务数，分类计数和 ID 只覆盖获取到的这一页
+type PoolSnapshot struct {
+	Time       time.Time      `json:"time"`
+	TaskType   string         `json:"taskType"`
+	Page       int            `json:"page"`
+	Total      int            `json:"total"`
+	IDs        []int          `json:"ids"` // 审核任务为 TaskID，生产任务为 ClueID
+	BySubject  map[string]int `json:"bySubject,omitempty"`
+	ByStep     map[string]int `json:"byStep,omitempty"`
+	ByClueType map[string]int `json:"byClueType,omitempty"`
+	New        []int          `json:"new,omitempty"`      // 与同一页的上一次快照相比新出现的 ID
+	Gone       []int          `json:"gone,omitempty"`     // 与同一页的上一次快照相比消失的 ID
+	Baseline   bool           `json:"baseline,omitempty"` // 这一页的第一次快照或中断后的第一次快照，没有可比较的上一次快照
+}
+
+// poolPruneInterval 是清理过期快照的最小间隔
+const poolPruneInterval = time.Hour
+
+// poolBaselineGaps 是同一页两次快照之间允许的最大间隔数，超过时（如两次会话之间）重新作为基准，
+// 避免把中断期间的变化计入新任务数和存活时间
+const poolBaselineGaps = 3
+
+// PoolArchive 定期将认领过程中获取的任务列表保存为快照，用于分析任务出现和被认领的规律。
+// 同一任务类型和页码每个间隔最多保存一次；nil 的 *PoolArchive 可以安全调用，不做任何记录
+type PoolArchive struct {
+	path      string
+	interval  time.Duration
+	retention time.Duration
+	mutex     sync.Mutex
+	last      map[string][]int // 按任务类型和页码保存上一次快照的 ID
+	lastAt    map[string]time.Time
+	prunedAt  time.Time
+}
+
+// NewPoolArchive 创建保存在 path 的快照存档，interval 为同一页两次快照的最小间隔，
+// 早于 retention 的快照在保存新快照时删除，retention 为 0 时永久保留
+func NewPoolArchive(path string, interval, retention time.Duration) *PoolArchive {
+	return &PoolArchive{path: path, interval: interval, retention: retention, last: map[string][]int{}, lastAt: map[string]time.Time{}}
+}
+
+// DefaultPoolArchive 返回保存在应用配置目录下的快照存档
+func DefaultPoolArchive(interval, retention time.Duration) (*PoolArchive, error) {
+	dir, err := appConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewPoolArchive(filepath.Join(dir, "pool-snapshots.jsonl"), interval, retention), nil
+}
+
+// WithPoolArchive 让会话把获取到的任务列表定期保存到 a
+func WithPoolArchive(a *PoolArchive) AutoClaimerOption {
+	return func(ac *AutoClaimer) {
+		ac.archive = a
+	}
+}
+
+// taskKeyID 返回认领时使用的任务 ID，生产任务为 ClueID
+func taskKeyID(task TaskItem, taskType string) int {
+	if taskType == "producetask" {
+		return task.ClueID
+	}
+	return task.TaskID
+}
+
+// observe 记录一次成功获取的任务列表，距上一次快照不足间隔时忽略
+func (a *PoolArchive) observe(taskType string, page int, data TaskListData, now time.Time) {
+	if a == nil {
+		return
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	key := taskType + "/" + strconv.Itoa(page)
+	if last, ok := a.lastAt[key]; ok && now.Sub(last) < a.interval {
+		return
+	}
+
+	snapshot := PoolSnapshot{
+		Time:       now,
+		TaskType:   taskType,
+		Page:       page,
+		Total:      data.Total,
+		IDs:        make([]int, 0, len(data.List)),
+		BySubject:  map[string]int{},
+		ByStep:     map[string]int{},
+		ByClueType: map[string]int{},
+	}
+	for _, task := range data.List {
+		snapshot.IDs = append(snapshot.IDs, taskKeyID(task, taskType))
+		countName(snapshot.BySubject, task.SubjectName)
+		countName(snapshot.ByStep, task.StepName)
+		countName(snapshot.ByClueType, task.ClueTypeName)
+	}
+
+	previous, ok := a.last[key]
+	if ok && a.interval > 0 && now.Sub(a.lastAt[key]) > poolBaselineGaps*a.interval {
+		ok = false
+	}
+	if ok {
+		snapshot.New = diffIDs(snapshot.IDs, previous)
+		snapshot.Gone = diffIDs(previous, snapshot.IDs)
+	} else {
+		snapshot.Baseline = true
+	}
+	a.last[key] = snapshot.IDs
+	a.lastAt[key] = now
+
+	if err := a.append(snapshot); err != nil {
+		log.Printf("%v", err)
+	}
+	if a.retention > 0 && now.Sub(a.prunedAt) >= poolPruneInterval {
+		a.prunedAt = now
+		if err := a.prune(now.Add(-a.retention)); err != nil {
+			log.Printf("%v", err)
+		}
+	}
+}
+
+// countName 按名称计数，名称为空时记为"未知"
+func countName(counts map[string]int, name string) {
+	if name == "" {
+		name = "未知"
+	}
+	counts[name]++
+}
+
+// diffIDs 返回在 ids 中但不在 other 中的 ID
+func diffIDs(ids, other []int) []int {
+	var diff []int
+	for _, id := range ids {
+		if !slices.Contains(other, id) {
+			diff = append(diff, id)
+		}
+	}
+	return diff
+}
+
+// append 追加一条快照
+func (a *PoolArchive) append(snapshot PoolSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(a.path), 0o700); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+	f, err := os.OpenFile(a.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("打开线索池快照失败: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("保存线索池快照失败: %v", err)
+	}
+	return nil
+}
+
+// prune 删除早于 before 的快照和不完整的行，通过临时文件替换，中途失败不影响原文件
+func (a *PoolArchive) prune(before time.Time) error {
+	f, err := os.Open(a.path)
+	if err != nil {
+		return fmt.Errorf("读取线索池快照失败: %v", err)
+	}
+	defer f.Close()
+
+	var kept bytes.Buffer
+	removed := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var snapshot PoolSnapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snapshot); err != nil || snapshot.Time.Before(before) {
+			removed++
+			continue
+		}
+		kept.Write(scanner.Bytes())
+		kept.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取线索池快照失败: %v", err)
+	}
+	if removed == 0 {
+		return nil
+	}
+
+	tmp := a.path + ".tmp"
+	if err := os.WriteFile(tmp, kept.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("清理线索池快照失败: %v", err)
+	}
+	if err := os.Rename(tmp, a.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("清理线索池快照失败: %v", err)
+	}
+	return nil
+}
+
+// List 按时间顺序返回 [since, until) 内指定任务类型的快照，零值表示不限制，文件不存在时返回空列表
+func (a *PoolArchive) List(taskType string, since, until time.Time) ([]PoolSnapshot, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	f, err := os.Open(a.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []PoolSnapshot{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取线索池快照失败: %v", err)
+	}
+	defer f.Close()
+
+	snapshots := []PoolSnapshot{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var snapshot PoolSnapshot
+		// 跳过写入中断产生的不完整行
+		if err := json.Unmarshal(scanner.Bytes(), &snapshot); err != nil {
+			continue
+		}
+		if taskType != "" && snapshot.TaskType != taskType {
+			continue
+		}
+		if (!since.IsZero() && snapshot.Time.Before(since)) || (!until.IsZero() && !snapshot.Time.Before(until)) {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取线索池快照失败: %v", err)
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDiffIDs(t *testing.T) {
+	tests := []struct {
+		ids, other, want []int
+	}{
+		{nil, nil, nil},
+		{[]int{1, 2, 3}, nil, []int{1, 2, 3}},
+		{nil, []int{1}, nil},
+		{[]int{1, 2, 3}, []int{2}, []int{1, 3}},
+		{[]int{3, 1, 2}, []int{1, 2, 3}, nil},
+		{[]int{5, 4}, []int{1}, []int{5, 4}},
+	}
+	for _, tt := range tests {
+		if got := diffIDs(tt.ids, tt.other); !slices.Equal(got, tt.want) {
+			t.Errorf("diffIDs(%v, %v) = %v，期望 %v", tt.ids, tt.other, got, tt.want)
+		}
+	}
+}
+
+func poolPage(total int, ids ...int) TaskListData {
+	data := TaskListData{Total: total}
+	for _, id := range ids {
+		data.List = append(data.List, TaskItem{TaskID: id, ClueID: id + 1000, SubjectName: "数学"})
+	}
+	return data
+}
+
+func TestPoolArchiveObserve(t *testing.T) {
+	archive := NewPoolArchive(filepath.Join(t.TempDir(), "pool.jsonl"), time.Minute, 0)
+	start := time.Date(2025, 1, 7, 1, 0, 0, 0, time.UTC)
+
+	archive.observe("audittask", 1, poolPage(10, 1, 2), start)
+	archive.observe("audittask", 1, poolPage(10, 9), start.Add(30*time.Second)) // 不足间隔，忽略
+	archive.observe("audittask", 1, poolPage(11, 2, 3), start.Add(time.Minute))
+	archive.observe("audittask", 2, poolPage(11, 7), start.Add(time.Minute))
+	archive.observe("producetask", 1, TaskListData{Total: 1, List: []TaskItem{{TaskID: 1, ClueID: 55}}}, start)
+
+	var nilArchive *PoolArchive
+	nilArchive.observe("audittask", 1, poolPage(1, 1), start)
+
+	snapshots, err := archive.List("audittask", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 3 {
+		t.Fatalf("快照数 = %d，期望 3: %+v", len(snapshots), snapshots)
+	}
+	first, second, otherPage := snapshots[0], snapshots[1], snapshots[2]
+	if !first.Baseline || first.New != nil || !slices.Equal(first.IDs, []int{1, 2}) || first.BySubject["数学"] != 2 {
+		t.Errorf("第一个快照 = %+v", first)
+	}
+	if second.Baseline || !slices.Equal(second.New, []int{3}) || !slices.Equal(second.Gone, []int{1}) || second.Total != 11 {
+		t.Errorf("第二个快照 = %+v", second)
+	}
+	if !otherPage.Baseline || otherPage.Page != 2 {
+		t.Errorf("第 2 页应有独立的基准快照: %+v", otherPage)
+	}
+
+	produce, err := archive.List("producetask", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(produce) != 1 || !slices.Equal(produce[0].IDs, []int{55}) || produce[0].ByStep["未知"] != 1 {
+		t.Errorf("生产任务快照应使用 ClueID: %+v", produce)
+	}
+
+	ranged, err := archive.List("", start.Add(time.Second), start.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranged) != 0 {
+		t.Errorf("[since, until) 之外的快照不应返回: %+v", ranged)
+	}
+}
+
+func TestPoolArchiveBaselineAfterGap(t *testing.T) {
+	archive := NewPoolArchive(filepath.Join(t.TempDir(), "pool.jsonl"), time.Minute, 0)
+	start := time.Date(2025, 1, 7, 1, 0, 0, 0, time.UTC)
+
+	archive.observe("audittask", 1, poolPage(10, 1, 2), start)
+	archive.observe("audittask", 1, poolPage(10, 2, 3), start.Add(3*time.Minute)) // 正好 3 个间隔，仍然比较
+	// 会话停止数小时后重新启动，不与之前的快照比较
+	archive.observe("audittask", 1, poolPage(10, 7, 8, 9), start.Add(5*time.Hour))
+	archive.observe("audittask", 1, poolPage(10, 8, 9, 10), start.Add(5*time.Hour+time.Minute))
+
+	snapshots, err := archive.List("audittask", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 4 {
+		t.Fatalf("快照数 = %d，期望 4", len(snapshots))
+	}
+	if s := snapshots[1]; s.Baseline || !slices.Equal(s.New, []int{3}) || !slices.Equal(s.Gone, []int{1}) {
+		t.Errorf("间隔内的快照 = %+v", s)
+	}
+	if s := snapshots[2]; !s.Baseline || s.New != nil || s.Gone != nil {
+		t.Errorf("中断后的快照应作为基准: %+v", s)
+	}
+	if s := snapshots[3]; s.Baseline || !slices.Equal(s.New, []int{10}) || !slices.Equal(s.Gone, []int{7}) {
+		t.Errorf("基准之后的快照 = %+v", s)
+	}
+}
+
+func TestPoolArchiveRetention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pool.jsonl")
+	archive := NewPoolArchive(path, 0, 24*time.Hour)
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	for _, at := range []time.Time{now.Add(-72 * time.Hour), now.Add(-25 * time.Hour), now.Add(-23 * time.Hour)} {
+		if err := archive.append(PoolSnapshot{Time: at, TaskType: "audittask", Page: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"time":"2025-01-10T`) // 写入中断的行
+	f.WriteString("\n")
+	f.Close()
+
+	times := func() []time.Time {
+		t.Helper()
+		snapshots, err := archive.List("", time.Time{}, time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result []time.Time
+		for _, snapshot := range snapshots {
+			result = append(result, snapshot.Time)
+		}
+		return result
+	}
+
+	archive.observe("audittask", 1, poolPage(1, 1), now)
+	if got, want := times(), []time.Time{now.Add(-23 * time.Hour), now}; !slices.EqualFunc(got, want, time.Time.Equal) {
+		t.Fatalf("清理后的快照 = %v，期望 %v", got, want)
+	}
+
+	// 一小时内不重复清理
+	if err := archive.append(PoolSnapshot{Time: now.Add(-48 * time.Hour), TaskType: "audittask", Page: 1}); err != nil {
+		t.Fatal(err)
+	}
+	archive.observe("audittask", 1, poolPage(1, 1), now.Add(30*time.Minute))
+	if got := times(); len(got) != 4 {
+		t.Errorf("一小时内不应再次清理，快照 = %v", got)
+	}
+	archive.observe("audittask", 1, poolPage(1, 1), now.Add(time.Hour))
+	if got := times(); len(got) != 4 || got[0].Before(now.Add(-23*time.Hour)) {
+		t.Errorf("再次清理后的快照 = %v", got)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("临时文件应已替换原文件: %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HourlyPoolStat 是一天中某个小时（平台时区）的线索池统计
+type HourlyPoolStat struct {
+	Hour      int     `json:"hour"`
+	Snapshots int     `json:"snapshots"`
+	NewTasks  int     `json:"newTasks"` // 这个小时内新出现的任务数
+	AvgTotal  float64 `json:"avgTotal"` // 线索池任务总数的平均值
+}
+
+// CategoryCount 是某个学科、学段或线索类型在每次快照中的平均任务数
+type CategoryCount struct {
+	Name string  `json:"name"`
+	Avg  float64 `json:"avg"`
+}
+
+// PoolReport 汇总线索池快照，说明任务通常在什么时候出现、多快被认领。
+// 存活时间从任务新出现到从同一页消失，精度为快照间隔；任务翻到其他页也会被视为消失，
+// 因此固定页码（不使用随机页码）时的结果最准确
+type PoolReport struct {
+	TaskType              string           `json:"taskType"`
+	From                  time.Time        `json:"from"`
+	To                    time.Time        `json:"to"`
+	Snapshots             int              `json:"snapshots"`
+	Hours                 []HourlyPoolStat `json:"hours"`
+	PeakHours             []int            `json:"peakHours"`             // 新任务最多的小时，最多 3 个
+	Measured              int              `json:"measured"`              // 统计到存活时间的任务数
+	MedianLifetimeSeconds float64          `json:"medianLifetimeSeconds"` // 存活时间的中位数
+	P90LifetimeSeconds    float64          `json:"p90LifetimeSeconds"`    // 90% 的任务在这个时间内被认领
+	Subjects              []CategoryCount  `json:"subjects"`
+	Steps                 []CategoryCount  `json:"steps"`
+	ClueTypes             []CategoryCount  `json:"clueTypes"`
+}
+
+// BuildPoolReport 根据按时间排序的快照生成报告
+func BuildPoolReport(taskType string, snapshots []PoolSnapshot) *PoolReport {
+	report := &PoolReport{TaskType: taskType, Snapshots: len(snapshots), Hours: make([]HourlyPoolStat, 24), PeakHours: []int{}}
+	for hour := range report.Hours {
+		report.Hours[hour].Hour = hour
+	}
+	if len(snapshots) == 0 {
+		report.Subjects, report.Steps, report.ClueTypes = []CategoryCount{}, []CategoryCount{}, []CategoryCount{}
+		return report
+	}
+	report.From = snapshots[0].Time
+	report.To = snapshots[len(snapshots)-1].Time
+
+	location := PlatformLocation()
+	totals := make([]int, 24)
+	subjects, steps, clueTypes := map[string]int{}, map[string]int{}, map[string]int{}
+	firstSeen := map[string]map[int]time.Time{} // 按任务类型和页码记录任务出现的时间
+	var lifetimes []float64
+	for _, snapshot := range snapshots {
+		hour := snapshot.Time.In(location).Hour()
+		report.Hours[hour].Snapshots++
+		report.Hours[hour].NewTasks += len(snapshot.New)
+		totals[hour] += snapshot.Total
+
+		for name, count := range snapshot.BySubject {
+			subjects[name] += count
+		}
+		for name, count := range snapshot.ByStep {
+			steps[name] += count
+		}
+		for name, count := range snapshot.ByClueType {
+			clueTypes[name] += count
+		}
+
+		// 只有看到出现时间的任务才能计算存活时间，基准快照中的任务不计入；
+		// 中断后的基准快照之前出现的任务无法确定消失时间，一并丢弃
+		key := snapshot.TaskType + "/" + strconv.Itoa(snapshot.Page)
+		if snapshot.Baseline || firstSeen[key] == nil {
+			firstSeen[key] = map[int]time.Time{}
+		}
+		for _, id := range snapshot.New {
+			if _, ok := firstSeen[key][id]; !ok {
+				firstSeen[key][id] = snapshot.Time
+			}
+		}
+		for _, id := range snapshot.Gone {
+			if seen, ok := firstSeen[key][id]; ok {
+				lifetimes = append(lifetimes, snapshot.Time.Sub(seen).Seconds())
+				delete(firstSeen[key], id)
+			}
+		}
+	}
+
+	for hour := range report.Hours {
+		if n := report.Hours[hour].Snapshots; n > 0 {
+			report.Hours[hour].AvgTotal = float64(totals[hour]) / float64(n)
+		}
+	}
+	report.PeakHours = peakHours(report.Hours, 3)
+
+	slices.Sort(lifetimes)
+	report.Measured = len(lifetimes)
+	report.MedianLifetimeSeconds = percentile(lifetimes, 0.5)
+	report.P90LifetimeSeconds = percentile(lifetimes, 0.9)
+
+	report.Subjects = averageCounts(subjects, len(snapshots))
+	report.Steps = averageCounts(steps, len(snapshots))
+	report.ClueTypes = averageCounts(clueTypes, len(snapshots))
+	return report
+}
+
+// LoadPoolReport 读取默认存档中最近 days 天的快照并生成报告，days 不大于 0 时使用全部快照
+func LoadPoolReport(taskType string, days int, now time.Time) (*PoolReport, error) {
+	if taskType == "" {
+		taskType = "audittask"
+	}
+	archive, err := DefaultPoolArchive(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	var since time.Time
+	if days > 0 {
+		since = now.AddDate(0, 0, -days)
+	}
+	snapshots, err := archive.List(taskType, since, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	return BuildPoolReport(taskType, snapshots), nil
+}
+
+// peakHours 返回新任务最多的 n 个小时，没有新任务的小时不计入
+func peakHours(hours []HourlyPoolStat, n int) []int {
+	sorted := slices.Clone(hours)
+	slices.SortStableFunc(sorted, func(a, b HourlyPoolStat) int {
+		return cmp.Compare(b.NewTasks, a.NewTasks)
+	})
+	peaks := []int{}
+	for _, stat := range sorted[:min(n, len(sorted))] {
+		if stat.NewTasks > 0 {
+			peaks = append(peaks, stat.Hour)
+		}
+	}
+	return peaks
+}
+
+// percentile 返回已排序数据的分位数（最近秩法），没有数据时返回 0
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(float64(len(sorted))*p+0.5) - 1
+	return sorted[min(max(index, 0), len(sorted)-1)]
+}
+
+// averageCounts 计算每次快照的平均数量，按数量从多到少排序
+func averageCounts(counts map[string]int, snapshots int) []CategoryCount {
+	result := make([]CategoryCount, 0, len(counts))
+	for name, count := range counts {
+		result = append(result, CategoryCount{Name: name, Avg: float64(count) / float64(snapshots)})
+	}
+	slices.SortFunc(result, func(a, b CategoryCount) int {
+		if c := cmp.Compare(b.Avg, a.Avg); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result
+}
+
+// Write 以文本格式输出报告
+func (r *PoolReport) Write(w io.Writer) {
+	if r.Snapshots == 0 {
+		fmt.Fprintf(w, "没有 %s 的线索池快照，请在设置中启用快照后运行自动认领\n", r.TaskType)
+		return
+	}
+	fmt.Fprintf(w, "任务类型: %s，快照 %d 个（%s 至 %s）\n", r.TaskType, r.Snapshots,
+		r.From.In(PlatformLocation()).Format("2006-01-02 15:04"), r.To.In(PlatformLocation()).Format("2006-01-02 15:04"))
+
+	fmt.Fprintln(w, "\n小时  快照数  新任务  平均总数")
+	for _, stat := range r.Hours {
+		if stat.Snapshots == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%02d:00 %6d  %6d  %8.1f\n", stat.Hour, stat.Snapshots, stat.NewTasks, stat.AvgTotal)
+	}
+	if len(r.PeakHours) > 0 {
+		hours := make([]string, len(r.PeakHours))
+		for i, hour := range r.PeakHours {
+			hours[i] = fmt.Sprintf("%02d:00", hour)
+		}
+		fmt.Fprintf(w, "新任务最多的时段: %s\n", strings.Join(hours, "、"))
+	}
+
+	if r.Measured > 0 {
+		fmt.Fprintf(w, "\n任务存活时间（%d 个任务）: 中位数 %s，90%% 在 %s 内被认领\n", r.Measured,
+			formatSeconds(r.MedianLifetimeSeconds), formatSeconds(r.P90LifetimeSeconds))
+	} else {
+		fmt.Fprintln(w, "\n还没有统计到任务的存活时间")
+	}
+
+	for _, group := range []struct {
+		title  string
+		counts []CategoryCount
+	}{{"学科", r.Subjects}, {"学段", r.Steps}, {"线索类型", r.ClueTypes}} {
+		if len(group.counts) == 0 {
+			continue
+		}
+		parts := make([]string, len(group.counts))
+		for i, count := range group.counts {
+			parts[i] = fmt.Sprintf("%s %.1f", count.Name, count.Avg)
+		}
+		fmt.Fprintf(w, "%s（每页平均）: %s\n", group.title, strings.Join(parts, "，"))
+	}
+}
+
+// formatSeconds 将秒数格式化为易读的时长
+func formatSeconds(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Second).String()
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPercentile(t *testing.T) {
+	ten := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		sorted []float64
+		p      float64
+		want   float64
+	}{
+		{nil, 0.5, 0},
+		{[]float64{42}, 0.5, 42},
+		{[]float64{42}, 0.9, 42},
+		{ten, 0, 1},
+		{ten, 0.5, 5},
+		{ten, 0.9, 9},
+		{ten, 0.95, 10},
+		{ten, 1, 10},
+		{[]float64{1800, 3000, 12600, 14400}, 0.5, 3000},
+	}
+	for _, tt := range tests {
+		if got := percentile(tt.sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(%v, %g) = %g，期望 %g", tt.sorted, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestPeakHours(t *testing.T) {
+	hours := func(newTasks map[int]int) []HourlyPoolStat {
+		stats := make([]HourlyPoolStat, 24)
+		for hour := range stats {
+			stats[hour] = HourlyPoolStat{Hour: hour, NewTasks: newTasks[hour]}
+		}
+		return stats
+	}
+	tests := []struct {
+		name     string
+		newTasks map[int]int
+		n        int
+		want     []int
+	}{
+		{"没有新任务", nil, 3, []int{}},
+		{"按新任务数排序", map[int]int{9: 2, 10: 5, 14: 1, 20: 3}, 3, []int{10, 20, 9}},
+		{"数量相同时按小时", map[int]int{15: 4, 8: 4, 11: 4, 3: 4}, 3, []int{3, 8, 11}},
+		{"不足 n 个时不补零", map[int]int{7: 1}, 3, []int{7}},
+		{"n 超过小时数", map[int]int{0: 1, 23: 2}, 30, []int{23, 0}},
+	}
+	for _, tt := range tests {
+		if got := peakHours(hours(tt.newTasks), tt.n); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: peakHours = %v，期望 %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuildPoolReport(t *testing.T) {
+	at := func(value string) PoolSnapshot {
+		return PoolSnapshot{Time: mustPlatformTime(t, value).Time, TaskType: "audittask", Page: 1}
+	}
+	snapshot := func(value string, total int, newIDs, goneIDs []int, subjects map[string]int) PoolSnapshot {
+		s := at(value)
+		s.Total, s.New, s.Gone, s.BySubject = total, newIDs, goneIDs, subjects
+		return s
+	}
+	baseline := snapshot("2025-01-07 09:00:00", 10, nil, nil, map[string]int{"数学": 2})
+	baseline.Baseline = true
+	snapshots := []PoolSnapshot{
+		baseline,
+		// 任务 1 在基准快照中已经存在，不计算存活时间
+		snapshot("2025-01-07 09:10:00", 11, []int{3}, []int{1}, map[string]int{"数学": 1, "英语": 1}),
+		snapshot("2025-01-07 10:00:00", 12, []int{4, 5}, []int{3}, nil),
+		snapshot("2025-01-07 10:30:00", 12, []int{6}, []int{4}, nil),
+		// 任务 7 出现后还没有消失，也不计入
+		snapshot("2025-01-07 14:00:00", 9, []int{7}, []int{5, 6, 99}, nil),
+	}
+
+	report := BuildPoolReport("audittask", snapshots)
+	if report.Snapshots != 5 || !report.From.Equal(snapshots[0].Time) || !report.To.Equal(snapshots[4].Time) {
+		t.Errorf("报告范围 = %d 个 %s - %s", report.Snapshots, report.From, report.To)
+	}
+
+	// 存活时间：3 为 50 分钟，4 为 30 分钟，5 为 4 小时，6 为 3.5 小时
+	if report.Measured != 4 {
+		t.Errorf("统计到存活时间的任务数 = %d，期望 4", report.Measured)
+	}
+	if report.MedianLifetimeSeconds != 3000 || report.P90LifetimeSeconds != 14400 {
+		t.Errorf("存活时间中位数 = %g，p90 = %g", report.MedianLifetimeSeconds, report.P90LifetimeSeconds)
+	}
+
+	nine, ten, fourteen := report.Hours[9], report.Hours[10], report.Hours[14]
+	if nine.Snapshots != 2 || nine.NewTasks != 1 || nine.AvgTotal != 10.5 {
+		t.Errorf("09 时 = %+v", nine)
+	}
+	if ten.Snapshots != 2 || ten.NewTasks != 3 || ten.AvgTotal != 12 {
+		t.Errorf("10 时 = %+v", ten)
+	}
+	if fourteen.Snapshots != 1 || fourteen.NewTasks != 1 {
+		t.Errorf("14 时 = %+v", fourteen)
+	}
+	if !slices.Equal(report.PeakHours, []int{10, 9, 14}) {
+		t.Errorf("高峰时段 = %v", report.PeakHours)
+	}
+
+	want := []CategoryCount{{"数学", 0.6}, {"英语", 0.2}}
+	if !slices.Equal(report.Subjects, want) {
+		t.Errorf("学科 = %v，期望 %v", report.Subjects, want)
+	}
+}
+
+func TestBuildPoolReportDropsTasksAcrossBaseline(t *testing.T) {
+	snapshot := func(value string, page int, baseline bool, newIDs, goneIDs []int) PoolSnapshot {
+		return PoolSnapshot{Time: mustPlatformTime(t, value).Time, TaskType: "audittask", Page: page, Baseline: baseline, New: newIDs, Gone: goneIDs}
+	}
+	report := BuildPoolReport("audittask", []PoolSnapshot{
+		snapshot("2025-01-07 09:00:00", 1, true, nil, nil),
+		snapshot("2025-01-07 09:01:00", 1, false, []int{3}, nil),
+		snapshot("2025-01-07 09:01:00", 2, true, nil, nil),
+		snapshot("2025-01-07 09:02:00", 2, false, []int{5}, nil),
+		snapshot("2025-01-07 09:30:00", 2, false, nil, []int{5}),
+		// 第 1 页在会话中断后重新作为基准，任务 3 的出现时间作废
+		snapshot("2025-01-07 15:00:00", 1, true, nil, nil),
+		snapshot("2025-01-07 15:01:00", 1, false, nil, []int{3}),
+	})
+	if report.Measured != 1 || report.MedianLifetimeSeconds != 28*60 {
+		t.Errorf("存活时间只应统计任务 5，得到 %d 个，中位数 %g", report.Measured, report.MedianLifetimeSeconds)
+	}
+}
+
+func TestBuildPoolReportEmpty(t *testing.T) {
+	report := BuildPoolReport("producetask", nil)
+	if report.Snapshots != 0 || len(report.Hours) != 24 || report.PeakHours == nil || report.Subjects == nil {
+		t.Errorf("空报告 = %+v", report)
+	}
+	if report.Measured != 0 || report.MedianLifetimeSeconds != 0 {
+		t.Errorf("没有快照时不应有存活时间: %+v", report)
+	}
+}
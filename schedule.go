@@ -0,0 +1,435 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClaimSchedule 是一个定时认领方案：在任一时间窗口内使用 Config 自动认领，窗口结束时停止
+type ClaimSchedule struct {
+	Name    string          `json:"name"`
+	Enabled bool            `json:"enabled"`
+	Windows []string        `json:"windows"` // 时间窗口，格式见 parseClaimWindow
+	Config  AutoClaimConfig `json:"config"`  // Cookie 为空时使用保存的 Cookie
+}
+
+// validate 检查方案名称和时间窗口
+func (s ClaimSchedule) validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("定时方案名称不能为空")
+	}
+	if len(s.Windows) == 0 {
+		return fmt.Errorf("定时方案 %s 没有时间窗口", s.Name)
+	}
+	for _, spec := range s.Windows {
+		if _, err := parseClaimWindow(spec); err != nil {
+			return fmt.Errorf("定时方案 %s: %v", s.Name, err)
+		}
+	}
+	if errs := s.Config.Validate(); len(errs) > 0 {
+		return fmt.Errorf("定时方案 %s: %v", s.Name, errs)
+	}
+	return nil
+}
+
+// claimWindow 是解析后的时间窗口
+type claimWindow interface {
+	// occurrence 返回包含 now 的窗口的开始和结束时间
+	occurrence(now time.Time) (start, end time.Time, ok bool)
+}
+
+// parseClaimWindow 解析时间窗口，支持两种格式：
+//
+//	"09:00-11:30"、"1-5 09:00-11:30"、"6,7 22:00-02:00"  星期（1 为周一，7 或 0 为周日）和时间范围，省略星期表示每天，结束早于开始表示跨午夜
+//	"cron 0 9 * * 1-5 90m"                             cron 表达式（分 时 日 月 周）和持续时间
+func parseClaimWindow(spec string) (claimWindow, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("时间窗口为空")
+	}
+	if fields[0] == "cron" {
+		return parseCronWindow(spec, fields[1:])
+	}
+
+	var weekdays uint64 = 1<<7 - 1
+	switch len(fields) {
+	case 1:
+	case 2:
+		var err error
+		if weekdays, err = parseCronField(fields[0], 0, 7); err != nil {
+			return nil, fmt.Errorf("无效的星期 %q: %v", fields[0], err)
+		}
+		// 7 和 0 都表示周日
+		if weekdays&(1<<7) != 0 {
+			weekdays = weekdays&^(1<<7) | 1
+		}
+	default:
+		return nil, fmt.Errorf("无效的时间窗口: %q", spec)
+	}
+
+	startText, endText, ok := strings.Cut(fields[len(fields)-1], "-")
+	if !ok {
+		return nil, fmt.Errorf("无效的时间范围: %q", fields[len(fields)-1])
+	}
+	start, err := parseClock(startText)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseClock(endText)
+	if err != nil {
+		return nil, err
+	}
+	if start == end {
+		return nil, fmt.Errorf("时间范围的开始和结束不能相同: %q", spec)
+	}
+	return rangeWindow{weekdays: weekdays, start: start, end: end}, nil
+}
+
+// parseClock 解析 "HH:MM"，返回距午夜的时长
+func parseClock(text string) (time.Duration, error) {
+	t, err := time.Parse("15:04", text)
+	if err != nil {
+		return 0, fmt.Errorf("无效的时间 %q，应为 HH:MM", text)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// rangeWindow 是按星期和每日时间范围定义的窗口
+type rangeWindow struct {
+	weekdays   uint64 // 第 n 位表示 time.Weekday(n)
+	start, end time.Duration
+}
+
+func (w rangeWindow) occurrence(now time.Time) (time.Time, time.Time, bool) {
+	// 跨午夜的窗口可能从前一天开始
+	for _, days := range []int{0, -1} {
+		day := time.Date(now.Year(), now.Month(), now.Day()+days, 0, 0, 0, 0, now.Location())
+		if w.weekdays&(1<<uint(day.Weekday())) == 0 {
+			continue
+		}
+		start := day.Add(w.start)
+		end := day.Add(w.end)
+		if w.end < w.start {
+			end = end.AddDate(0, 0, 1)
+		}
+		if !now.Before(start) && now.Before(end) {
+			return start, end, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// cronWindow 是从 cron 表达式匹配的时刻开始、持续 duration 的窗口
+type cronWindow struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+	duration                      time.Duration
+}
+
+// parseCronWindow 解析 "cron 分 时 日 月 周 持续时间"
+func parseCronWindow(spec string, fields []string) (claimWindow, error) {
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("无效的 cron 时间窗口 %q，应为 \"cron 分 时 日 月 周 持续时间\"", spec)
+	}
+	var w cronWindow
+	var err error
+	ranges := []struct {
+		field    *uint64
+		min, max int
+	}{{&w.minute, 0, 59}, {&w.hour, 0, 23}, {&w.dom, 1, 31}, {&w.month, 1, 12}, {&w.dow, 0, 7}}
+	for i, r := range ranges {
+		if *r.field, err = parseCronField(fields[i], r.min, r.max); err != nil {
+			return nil, fmt.Errorf("无效的 cron 字段 %q: %v", fields[i], err)
+		}
+	}
+	if w.dow&(1<<7) != 0 {
+		w.dow = w.dow&^(1<<7) | 1
+	}
+	w.domAny = fields[2] == "*"
+	w.dowAny = fields[4] == "*"
+
+	if w.duration, err = time.ParseDuration(fields[5]); err != nil || w.duration < time.Minute || w.duration > 24*time.Hour {
+		return nil, fmt.Errorf("无效的持续时间 %q，应在 1m 到 24h 之间", fields[5])
+	}
+	return w, nil
+}
+
+// parseCronField 解析 cron 字段，支持 *、数字、范围、列表和步长，返回位图
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangeText, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step <= 0 {
+				return 0, fmt.Errorf("无效的步长 %q", stepText)
+			}
+		}
+
+		lo, hi := min, max
+		if rangeText != "*" {
+			loText, hiText, isRange := strings.Cut(rangeText, "-")
+			var err error
+			if lo, err = strconv.Atoi(loText); err != nil {
+				return 0, fmt.Errorf("无效的数值 %q", loText)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiText); err != nil {
+					return 0, fmt.Errorf("无效的数值 %q", hiText)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q 超出范围 %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// matches 判断 t 所在的分钟是否匹配 cron 表达式，日和周同时指定时满足其一即可（与 cron 一致）
+func (w cronWindow) matches(t time.Time) bool {
+	if w.minute&(1<<uint(t.Minute())) == 0 || w.hour&(1<<uint(t.Hour())) == 0 || w.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domMatch := w.dom&(1<<uint(t.Day())) != 0
+	dowMatch := w.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case w.domAny && w.dowAny:
+		return true
+	case w.domAny:
+		return dowMatch
+	case w.dowAny:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
+
+func (w cronWindow) occurrence(now time.Time) (time.Time, time.Time, bool) {
+	// 向前查找持续时间内最近一次匹配的时刻
+	minute := now.Truncate(time.Minute)
+	for t := minute; now.Sub(t) < w.duration; t = t.Add(-time.Minute) {
+		if w.matches(t) {
+			return t, t.Add(w.duration), true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// ScheduleStore 将定时方案保存在 JSON 文件中
+type ScheduleStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewScheduleStore 创建保存在指定路径的定时方案存储
+func NewScheduleStore(path string) *ScheduleStore {
+	return &ScheduleStore{path: path}
+}
+
+// DefaultScheduleStore 返回保存在应用配置目录下的定时方案存储
+func DefaultScheduleStore() (*ScheduleStore, error) {
+	dir, err := appConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewScheduleStore(filepath.Join(dir, "schedules.json")), nil
+}
+
+// Load 读取所有定时方案，文件不存在时返回空列表
+func (s *ScheduleStore) Load() ([]ClaimSchedule, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	schedules := []ClaimSchedule{}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return schedules, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取定时方案失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &schedules); err != nil {
+		return nil, fmt.Errorf("解析定时方案失败: %v", err)
+	}
+	return schedules, nil
+}
+
+// Save 保存所有定时方案
+func (s *ScheduleStore) Save(schedules []ClaimSchedule) error {
+	names := map[string]bool{}
+	for _, schedule := range schedules {
+		if err := schedule.validate(); err != nil {
+			return err
+		}
+		if names[schedule.Name] {
+			return fmt.Errorf("定时方案名称重复: %s", schedule.Name)
+		}
+		names[schedule.Name] = true
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+	return os.WriteFile(s.path, data, 0o600)
+}
+
+// scheduleCheckInterval 是调度器检查时间窗口的间隔
+const scheduleCheckInterval = 30 * time.Second
+
+// 启动失败后重试的间隔，每次失败翻倍，不超过 scheduleRetryMax
+const (
+	scheduleRetryBase = time.Minute
+	scheduleRetryMax  = 10 * time.Minute
+)
+
+// Scheduler 在定时方案的时间窗口内自动启动会话，窗口结束时停止由它启动的会话。
+// 时间窗口按平台时区计算，与本机时区无关。
+// 手动启动的会话不受影响；同一窗口内会话因达到上限等原因停止后不会再次启动，启动失败时按退避间隔重试
+type Scheduler struct {
+	sessions  *SessionManager
+	store     *ScheduleStore
+	serverURL string
+	now       func() time.Time
+	onChange  func(message string) // 调度器启动或停止会话时调用
+
+	mutex      sync.Mutex
+	owned      string // 由调度器启动且仍在运行的会话编号，为空表示没有
+	ownedEnd   time.Time
+	occurrence string // 已成功启动过的窗口，格式为 "方案名@开始时间"
+	failed     string // 最近启动失败的窗口
+	failures   int    // failed 连续启动失败的次数
+	retryAt    time.Time
+}
+
+// NewScheduler 创建调度器
+func NewScheduler(sessions *SessionManager, store *ScheduleStore, onChange func(message string)) *Scheduler {
+	return &Scheduler{sessions: sessions, store: store, serverURL: DefaultServerURL, now: time.Now, onChange: onChange}
+}
+
+// Run 定期检查时间窗口，直到 ctx 结束
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+
+	s.check(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.check(ctx)
+		}
+	}
+}
+
+// check 根据当前时间启动或停止会话
+func (s *Scheduler) check(ctx context.Context) {
+	schedules, err := s.store.Load()
+	if err != nil {
+		log.Printf("%v", err)
+		return
+	}
+	now := s.now().In(PlatformLocation())
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	status, _ := s.sessions.Status()
+
+	// 调度器启动的会话已经停止，或已被手动启动的会话替换
+	if s.owned != "" && (!status.IsActive || s.sessions.SessionID() != s.owned) {
+		s.owned = ""
+	}
+	// 由调度器启动的会话在窗口结束时停止
+	if s.owned != "" {
+		if !now.Before(s.ownedEnd) {
+			s.owned = ""
+			s.sessions.Stop()
+			s.notify("定时认领窗口已结束，自动认领已停止")
+		}
+		return
+	}
+	if status.IsActive {
+		return
+	}
+
+	for _, schedule := range schedules {
+		if !schedule.Enabled {
+			continue
+		}
+		for _, spec := range schedule.Windows {
+			window, err := parseClaimWindow(spec)
+			if err != nil {
+				continue
+			}
+			start, end, ok := window.occurrence(now)
+			if !ok {
+				continue
+			}
+			occurrence := schedule.Name + "@" + start.Format(time.RFC3339)
+			if occurrence == s.occurrence || (occurrence == s.failed && now.Before(s.retryAt)) {
+				continue
+			}
+			s.start(ctx, schedule, occurrence, end, now)
+			return
+		}
+	}
+}
+
+// start 使用定时方案的配置启动会话，成功后记录窗口，失败时安排重试
+func (s *Scheduler) start(ctx context.Context, schedule ClaimSchedule, occurrence string, end, now time.Time) {
+	config := schedule.Config
+	config.ServerBaseURL = s.serverURL
+	if config.Cookie == "" {
+		config.Cookie = s.sessions.SavedCookie()
+	}
+
+	if _, err := s.sessions.Start(ctx, config); err != nil {
+		if occurrence != s.failed {
+			s.failed, s.failures = occurrence, 0
+		}
+		delay := min(scheduleRetryBase<<s.failures, scheduleRetryMax)
+		if delay < scheduleRetryMax {
+			s.failures++
+		}
+		s.retryAt = now.Add(delay)
+		s.notify(fmt.Sprintf("定时方案 %s 启动失败: %v，将于 %s 重试", schedule.Name, err, s.retryAt.Format("15:04")))
+		return
+	}
+	s.occurrence = occurrence
+	s.failed, s.failures = "", 0
+	s.owned = s.sessions.SessionID()
+	s.ownedEnd = end
+	s.notify(fmt.Sprintf("定时方案 %s 已启动自动认领，将于 %s 停止", schedule.Name, end.Format("15:04")))
+}
+
+func (s *Scheduler) notify(message string) {
+	log.Printf("%s", message)
+	if s.onChange != nil {
+		s.onChange(message)
+	}
+}
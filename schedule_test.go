@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseClaimWindowInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"09:00",
+		"9-11",
+		"25:00-26:00",
+		"09:00-09:00",
+		"8 09:00-11:00",
+		"1-5 daily 09:00-11:00",
+		"cron 0 9 * *",
+		"cron 0 9 * * * 0m",
+		"cron 0 9 * * * 25h",
+		"cron 60 9 * * * 1h",
+		"cron 0 9 * * 1-5 soon",
+	} {
+		if _, err := parseClaimWindow(spec); err == nil {
+			t.Errorf("parseClaimWindow(%q) 应返回错误", spec)
+		}
+	}
+}
+
+func TestClaimWindowOccurrence(t *testing.T) {
+	// 2025-01-06 是周一
+	tests := []struct {
+		spec       string
+		now        string
+		start, end string // 为空表示不在窗口内
+	}{
+		{"09:00-11:30", "2025-01-11 09:00:00", "2025-01-11 09:00:00", "2025-01-11 11:30:00"},
+		{"09:00-11:30", "2025-01-11 11:30:00", "", ""},
+		{"1-5 09:00-11:30", "2025-01-06 10:00:00", "2025-01-06 09:00:00", "2025-01-06 11:30:00"},
+		{"1-5 09:00-11:30", "2025-01-06 08:59:59", "", ""},
+		{"1-5 09:00-11:30", "2025-01-11 10:00:00", "", ""},
+		{"6,7 22:00-02:00", "2025-01-11 23:00:00", "2025-01-11 22:00:00", "2025-01-12 02:00:00"},
+		{"6,7 22:00-02:00", "2025-01-12 01:00:00", "2025-01-11 22:00:00", "2025-01-12 02:00:00"},
+		{"6,7 22:00-02:00", "2025-01-13 01:00:00", "2025-01-12 22:00:00", "2025-01-13 02:00:00"},
+		{"6,7 22:00-02:00", "2025-01-10 23:00:00", "", ""},
+		{"0 22:00-02:00", "2025-01-12 23:00:00", "2025-01-12 22:00:00", "2025-01-13 02:00:00"},
+		{"cron 0 9 * * 1-5 90m", "2025-01-06 10:29:59", "2025-01-06 09:00:00", "2025-01-06 10:30:00"},
+		{"cron 0 9 * * 1-5 90m", "2025-01-06 10:30:00", "", ""},
+		{"cron 0 9 * * 1-5 90m", "2025-01-11 09:10:00", "", ""},
+		{"cron */30 * * * * 10m", "2025-01-06 09:35:00", "2025-01-06 09:30:00", "2025-01-06 09:40:00"},
+		{"cron */30 * * * * 10m", "2025-01-06 09:45:00", "", ""},
+		{"cron 0 23 * * 7 2h", "2025-01-13 00:30:00", "2025-01-12 23:00:00", "2025-01-13 01:00:00"},
+		{"cron 0 9 15 * 1 1h", "2025-01-15 09:30:00", "2025-01-15 09:00:00", "2025-01-15 10:00:00"},
+	}
+	for _, tt := range tests {
+		window, err := parseClaimWindow(tt.spec)
+		if err != nil {
+			t.Fatalf("parseClaimWindow(%q) 失败: %v", tt.spec, err)
+		}
+		start, end, ok := window.occurrence(mustPlatformTime(t, tt.now).Time)
+		if tt.start == "" {
+			if ok {
+				t.Errorf("%q 在 %s 不应在窗口内，得到 %s - %s", tt.spec, tt.now, start, end)
+			}
+			continue
+		}
+		wantStart, wantEnd := mustPlatformTime(t, tt.start).Time, mustPlatformTime(t, tt.end).Time
+		if !ok || !start.Equal(wantStart) || !end.Equal(wantEnd) {
+			t.Errorf("%q 在 %s 的窗口 = %s - %s (%v)，期望 %s - %s", tt.spec, tt.now, start, end, ok, wantStart, wantEnd)
+		}
+	}
+}
+
+// stubAuthorizer 是可以切换成功或失败的授权方式，记录被调用的次数
+type stubAuthorizer struct {
+	mutex sync.Mutex
+	err   error
+	calls int
+}
+
+func (a *stubAuthorizer) Authorize(ctx context.Context, config AutoClaimConfig) (*AuthResult, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.calls++
+	if a.err != nil {
+		return nil, a.err
+	}
+	return &AuthResult{UserName: "test"}, nil
+}
+
+func (a *stubAuthorizer) fail(err error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.err = err
+}
+
+func (a *stubAuthorizer) callCount() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.calls
+}
+
+// schedulerFixture 是使用替身服务器和可控时间的调度器
+type schedulerFixture struct {
+	fake      *fakeEasylearn
+	sessions  *SessionManager
+	scheduler *Scheduler
+	auth      *stubAuthorizer
+	now       time.Time
+
+	mutex    sync.Mutex
+	messages []string
+}
+
+func newSchedulerFixture(t *testing.T, config AutoClaimConfig) *schedulerFixture {
+	t.Helper()
+
+	f := &schedulerFixture{fake: newFakeEasylearn(t), auth: &stubAuthorizer{}}
+	RegisterAuthorizer(t.Name(), f.auth)
+
+	config.AuthType = t.Name()
+	config.Cookie = fakeCookie
+	if config.TaskType == "" {
+		config.TaskType = "audittask"
+	}
+	if config.Interval == 0 {
+		config.Interval = 60
+	}
+	store := NewScheduleStore(filepath.Join(t.TempDir(), "schedules.json"))
+	err := store.Save([]ClaimSchedule{{Name: "早班", Enabled: true, Windows: []string{"1-5 09:00-11:00"}, Config: config}})
+	if err != nil {
+		t.Fatalf("保存定时方案失败: %v", err)
+	}
+
+	f.sessions = NewSessionManager(nil, nil)
+	t.Cleanup(func() { f.sessions.Stop() })
+	f.scheduler = NewScheduler(f.sessions, store, func(message string) {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		f.messages = append(f.messages, message)
+	})
+	f.scheduler.serverURL = f.fake.URL
+	f.scheduler.now = func() time.Time { return f.now }
+	return f
+}
+
+// checkAt 在平台时间 at 执行一次检查
+func (f *schedulerFixture) checkAt(t *testing.T, at string) {
+	t.Helper()
+	f.now = mustPlatformTime(t, at).Time
+	f.scheduler.check(context.Background())
+}
+
+func (f *schedulerFixture) active() bool {
+	status, _ := f.sessions.Status()
+	return status.IsActive
+}
+
+func (f *schedulerFixture) lastMessage() string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if len(f.messages) == 0 {
+		return ""
+	}
+	return f.messages[len(f.messages)-1]
+}
+
+func TestSchedulerStopsOwnedSessionAtWindowEnd(t *testing.T) {
+	f := newSchedulerFixture(t, AutoClaimConfig{})
+
+	f.checkAt(t, "2025-01-06 08:59:00")
+	if f.active() {
+		t.Fatal("窗口开始前不应启动会话")
+	}
+	f.checkAt(t, "2025-01-06 09:00:00")
+	if !f.active() {
+		t.Fatalf("窗口内应启动会话: %s", f.lastMessage())
+	}
+	f.checkAt(t, "2025-01-06 10:59:30")
+	if !f.active() {
+		t.Fatal("窗口结束前不应停止会话")
+	}
+	f.checkAt(t, "2025-01-06 11:00:00")
+	if f.active() {
+		t.Fatal("窗口结束时应停止调度器启动的会话")
+	}
+	if status, _ := f.sessions.Status(); status.State != SessionStopped {
+		t.Errorf("会话状态 = %s", status.State)
+	}
+}
+
+func TestSchedulerLeavesManualSessionRunning(t *testing.T) {
+	f := newSchedulerFixture(t, AutoClaimConfig{ClaimLimit: 1})
+	f.fake.addTasks("audittask", TaskItem{TaskID: 1, Brief: "数学"})
+
+	// 调度器启动的会话达到上限后自行停止，同一窗口内不再启动
+	f.checkAt(t, "2025-01-06 09:00:00")
+	waitFor(t, "会话达到上限", func() bool { return !f.active() })
+	f.checkAt(t, "2025-01-06 09:30:00")
+	if f.active() {
+		t.Fatal("同一窗口内不应再次启动会话")
+	}
+
+	// 之后手动启动的会话不能被窗口结束停止
+	_, err := f.sessions.Start(context.Background(), AutoClaimConfig{
+		TaskType:      "audittask",
+		Interval:      60,
+		Cookie:        fakeCookie,
+		ServerBaseURL: f.fake.URL,
+		AuthType:      t.Name(),
+	})
+	if err != nil {
+		t.Fatalf("手动启动失败: %v", err)
+	}
+	f.checkAt(t, "2025-01-06 10:00:00")
+	f.checkAt(t, "2025-01-06 11:00:00")
+	if !f.active() {
+		t.Fatal("调度器停止了手动启动的会话")
+	}
+}
+
+func TestSchedulerDoesNotStartOverManualSession(t *testing.T) {
+	f := newSchedulerFixture(t, AutoClaimConfig{})
+
+	_, err := f.sessions.Start(context.Background(), AutoClaimConfig{
+		TaskType:      "audittask",
+		Interval:      60,
+		Cookie:        fakeCookie,
+		ServerBaseURL: f.fake.URL,
+		AuthType:      t.Name(),
+	})
+	if err != nil {
+		t.Fatalf("手动启动失败: %v", err)
+	}
+	id := f.sessions.SessionID()
+
+	f.checkAt(t, "2025-01-06 09:00:00")
+	f.checkAt(t, "2025-01-06 11:00:00")
+	if !f.active() || f.sessions.SessionID() != id {
+		t.Fatal("调度器不应替换或停止手动启动的会话")
+	}
+}
+
+func TestSchedulerUsesPlatformTimezone(t *testing.T) {
+	f := newSchedulerFixture(t, AutoClaimConfig{})
+
+	// 2025-01-06 01:00 UTC 是北京时间周一 09:00，调度器不应按 UTC 判断窗口
+	f.now = time.Date(2025, 1, 6, 1, 0, 0, 0, time.UTC)
+	f.scheduler.check(context.Background())
+	if !f.active() {
+		t.Fatalf("北京时间 09:00 应在窗口内: %s", f.lastMessage())
+	}
+
+	// 平台时区改为 UTC 后同一时刻不在窗口内
+	f.sessions.Stop()
+	setPlatformTimezone(t, "UTC")
+	f.now = time.Date(2025, 1, 13, 1, 0, 0, 0, time.UTC)
+	f.scheduler.check(context.Background())
+	if f.active() {
+		t.Error("平台时区为 UTC 时 01:00 不应在窗口内")
+	}
+}
+
+func TestSchedulerRetriesFailedStart(t *testing.T) {
+	f := newSchedulerFixture(t, AutoClaimConfig{})
+	f.auth.fail(context.DeadlineExceeded)
+
+	f.checkAt(t, "2025-01-06 09:00:00")
+	if f.active() {
+		t.Fatal("授权失败时不应启动会话")
+	}
+	if msg := f.lastMessage(); !strings.Contains(msg, "启动失败") || !strings.Contains(msg, "09:01") {
+		t.Errorf("失败提示 = %q", msg)
+	}
+
+	// 第二次失败后退避间隔翻倍
+	f.checkAt(t, "2025-01-06 09:01:00")
+	if msg := f.lastMessage(); !strings.Contains(msg, "09:03") {
+		t.Errorf("第二次失败提示 = %q", msg)
+	}
+
+	f.auth.fail(nil)
+	f.checkAt(t, "2025-01-06 09:02:30")
+	if f.active() {
+		t.Fatal("退避期间不应重试")
+	}
+	f.checkAt(t, "2025-01-06 09:03:00")
+	if !f.active() {
+		t.Fatalf("退避结束后应重试启动: %s", f.lastMessage())
+	}
+}
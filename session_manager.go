@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoSession 表示当前没有自动认领会话
+	ErrNoSession = errors.New("没有运行的自动认领任务")
+	// ErrSessionRunning 表示已有会话正在运行
+	ErrSessionRunning = errors.New("已有正在运行的自动认领任务")
+)
+
+// SessionManager 管理当前的自动认领会话，图形界面和控制接口共用同一个实例
+type SessionManager struct {
+	mutex       sync.Mutex
+	claimer     *AutoClaimer
+	starting    bool
+	authResult  *AuthResult // 最近一次启动时的授权结果
+	sessionID   string
+	account     string
+	credentials *CredentialStore
+	history     *ClaimHistory
+	listeners   []func(ClaimEvent)
+	metrics     *Metrics
+	archive     *PoolArchive
+}
+
+// NewSessionManager 创建会话管理器，credentials 和 history 为 nil 时不保存 Cookie 和认领记录
+func NewSessionManager(credentials *CredentialStore, history *ClaimHistory) *SessionManager {
+	return &SessionManager{credentials: credentials, history: history, metrics: NewMetrics()}
+}
+
+// SetPoolArchive 让之后启动的会话把任务列表定期保存到 a，a 为 nil 时不保存
+func (m *SessionManager) SetPoolArchive(a *PoolArchive) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.archive = a
+}
+
+// OnEvent 注册事件监听器，之后启动的所有会话的事件都会转发给它
+func (m *SessionManager) OnEvent(listener func(ClaimEvent)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.listeners = append(m.listeners, listener)
+}
+
+// Start 验证授权并启动新的认领会话，返回给用户的提示信息
+func (m *SessionManager) Start(ctx context.Context, config AutoClaimConfig) (string, error) {
+	m.mutex.Lock()
+	if m.starting || (m.claimer != nil && m.claimer.GetStatus().IsActive) {
+		m.mutex.Unlock()
+		return "", ErrSessionRunning
+	}
+	m.starting = true
+	m.mutex.Unlock()
+	defer func() {
+		m.mutex.Lock()
+		m.starting = false
+		m.mutex.Unlock()
+	}()
+
+	if config.ServerBaseURL == "" {
+		config.ServerBaseURL = DefaultServerURL
+	}
+	// 在访问授权服务器之前检查配置
+	if errs := config.Validate(); len(errs) > 0 {
+		return "", errs
+	}
+
+	// 根据授权类型选择已注册的授权方式进行验证
+	authorizer, ok := LookupAuthorizer(config.AuthType)
+	if !ok {
+		return "", fmt.Errorf("未知的授权类型")
+	}
+
+	authCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	authResult, err := authorizer.Authorize(authCtx, config)
+	if err != nil {
+		log.Printf("授权验证失败 (%s): %v", config.AuthType, err)
+		return "", fmt.Errorf("授权验证失败: %w", err)
+	}
+	log.Printf("授权验证成功 (%s): %s", config.AuthType, authResult.UserName)
+
+	// 按授权记录中的上限限制认领数量和并发数
+	requested := config
+	adjustments := authResult.ApplyLimits(&config)
+	for _, adjustment := range adjustments {
+		log.Printf("%s", adjustment)
+	}
+
+	m.saveCookie(config.Cookie)
+
+	sessionID := newSessionID(time.Now())
+	account := m.lookupAccount(authCtx, config)
+
+	// 会话运行期间使用同一授权方式定期复核授权
+	recheck := func(ctx context.Context) (*AuthResult, error) {
+		return refreshAuthorize(ctx, authorizer, config)
+	}
+	taskType := config.TaskType
+	if taskType == "" {
+		taskType = "audittask"
+	}
+	m.mutex.Lock()
+	archive := m.archive
+	m.mutex.Unlock()
+	claimer, err := StartAutoClaiming(ctx, config,
+		WithEventListener(func(event ClaimEvent) {
+			m.handleEvent(event, taskType, account, sessionID)
+		}),
+		WithLicense(authResult, requested, recheck),
+		WithMetrics(m.metrics),
+		WithPoolArchive(archive),
+	)
+	if err != nil {
+		return "", fmt.Errorf("启动自动认领失败: %v", err)
+	}
+
+	m.mutex.Lock()
+	m.claimer = claimer
+	m.authResult = authResult
+	m.sessionID = sessionID
+	m.account = account
+	m.mutex.Unlock()
+
+	log.Printf("自动认领会话 %s 已启动，账号: %s", sessionID, account)
+
+	message := "自动认领已启动"
+	if config.DryRun {
+		message = "模拟运行已启动，只筛选任务不认领"
+	}
+	if len(adjustments) > 0 {
+		message = fmt.Sprintf("%s（%s）", message, strings.Join(adjustments, "，"))
+	}
+	return message, nil
+}
+
+// newSessionID 生成会话编号：启动时间加随机后缀，同一秒内启动的会话（包括重启程序后）也不会重复
+func newSessionID(now time.Time) string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		// 系统随机数不可用时退回到纳秒，仍能区分同一秒内的会话
+		return fmt.Sprintf("%s-%09d", now.Format("20060102-150405"), now.Nanosecond())
+	}
+	return now.Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// lookupAccount 获取 Cookie 对应的百度教育用户名，失败时使用授权用户名
+func (m *SessionManager) lookupAccount(ctx context.Context, config AutoClaimConfig) string {
+	if name, err := cookieUserName(ctx, config); err == nil {
+		return name
+	}
+	return config.AuthUsername
+}
+
+// handleEvent 记录认领成功的任务、保存刷新后的 Cookie，并转发给监听器
+func (m *SessionManager) handleEvent(event ClaimEvent, taskType, account, sessionID string) {
+	switch event.Type {
+	case EventTaskClaimed:
+		if m.history != nil && event.Task != nil {
+			record := newClaimRecord(*event.Task, taskType, account, sessionID, event.Time)
+			if err := m.history.Append(record); err != nil {
+				log.Printf("%v", err)
+			}
+		}
+	case EventCookieUpdated:
+		m.saveCookie(event.Cookie)
+	}
+
+	m.mutex.Lock()
+	listeners := append([]func(ClaimEvent){}, m.listeners...)
+	m.mutex.Unlock()
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+// Stop 手动停止当前会话
+func (m *SessionManager) Stop() error {
+	m.mutex.Lock()
+	claimer := m.claimer
+	m.mutex.Unlock()
+
+	if claimer == nil {
+		return ErrNoSession
+	}
+	claimer.Stop()
+	return nil
+}
+
+// Status 返回当前会话的状态，没有会话时返回 false
+func (m *SessionManager) Status() (ClaimStatus, bool) {
+	m.mutex.Lock()
+	claimer := m.claimer
+	m.mutex.Unlock()
+
+	if claimer == nil {
+		return ClaimStatus{State: SessionIdle}, false
+	}
+	return claimer.GetStatus(), true
+}
+
+// SessionID 返回当前会话的编号
+func (m *SessionManager) SessionID() string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.sessionID
+}
+
+// AuthResult 返回最近一次启动会话时的授权结果
+func (m *SessionManager) AuthResult() *AuthResult {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.authResult
+}
+
+// Cookie 返回正在使用的 Cookie，没有会话时返回保存的 Cookie
+func (m *SessionManager) Cookie() string {
+	m.mutex.Lock()
+	claimer := m.claimer
+	m.mutex.Unlock()
+
+	if claimer != nil {
+		return claimer.Cookie()
+	}
+	return m.SavedCookie()
+}
+
+// SavedCookie 返回凭据存储中保存的 Cookie
+func (m *SessionManager) SavedCookie() string {
+	if m.credentials == nil {
+		return ""
+	}
+	cookie, err := m.credentials.Load()
+	if err != nil {
+		log.Printf("读取保存的Cookie失败: %v", err)
+		return ""
+	}
+	return cookie
+}
+
+// saveCookie 将 Cookie 写入凭据存储
+func (m *SessionManager) saveCookie(cookie string) {
+	if m.credentials == nil || cookie == "" {
+		return
+	}
+	if err := m.credentials.Save(cookie); err != nil {
+		log.Printf("保存Cookie失败: %v", err)
+	}
+}
+
+// Metrics 返回所有会话累计的统计数据
+func (m *SessionManager) Metrics() *Metrics {
+	return m.metrics
+}
+
+// History 查询认领记录
+func (m *SessionManager) History(filter HistoryFilter) ([]ClaimRecord, error) {
+	if m.history == nil {
+		return []ClaimRecord{}, nil
+	}
+	return m.history.List(filter)
+}
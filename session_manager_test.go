@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewSessionIDIsUniqueWithinSecond(t *testing.T) {
+	now := time.Date(2025, 1, 7, 9, 30, 0, 0, time.Local)
+	pattern := regexp.MustCompile(`^20250107-093000-[0-9a-f]{8}$`)
+
+	seen := map[string]bool{}
+	for range 100 {
+		id := newSessionID(now)
+		if !pattern.MatchString(id) {
+			t.Fatalf("会话编号格式 = %q", id)
+		}
+		if seen[id] {
+			t.Fatalf("同一秒内生成了重复的会话编号 %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestSessionManagerRestartGetsNewSessionID(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	RegisterAuthorizer(t.Name(), &stubAuthorizer{})
+	sessions := NewSessionManager(nil, nil)
+	t.Cleanup(func() { sessions.Stop() })
+	config := AutoClaimConfig{TaskType: "audittask", Interval: 60, Cookie: fakeCookie, ServerBaseURL: fake.URL, AuthType: t.Name()}
+
+	if _, err := sessions.Start(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+	first := sessions.SessionID()
+	sessions.Stop()
+	if _, err := sessions.Start(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+	if second := sessions.SessionID(); second == first {
+		t.Errorf("立即重新启动的会话使用了相同的编号 %q", second)
+	}
+}
+
+func TestSessionManagerPersistsRotatedCookie(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	RegisterAuthorizer(t.Name(), &stubAuthorizer{})
+	// 通过 App 转发事件，检查推送给前端的 cookie:updated；先于会话注册清理，会话停止后再还原
+	var mutex sync.Mutex
+	var pushed []string
+	emit := eventsEmit
+	eventsEmit = func(ctx context.Context, name string, data ...any) {
+		if name != CookieUpdatedEventName {
+			return
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		pushed = append(pushed, data[0].(string))
+	}
+	t.Cleanup(func() { eventsEmit = emit })
+	credentials := NewCredentialStore(filepath.Join(t.TempDir(), "credentials.json"))
+	sessions := NewSessionManager(credentials, nil)
+	t.Cleanup(func() { sessions.Stop() })
+
+	app := &App{ctx: context.Background(), sessions: sessions}
+	sessions.OnEvent(app.forwardClaimEvent)
+
+	config := AutoClaimConfig{TaskType: "audittask", Interval: 0.05, Cookie: fakeCookie, ServerBaseURL: fake.URL, AuthType: t.Name()}
+	if _, err := sessions.Start(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+	if saved := sessions.SavedCookie(); saved != fakeCookie {
+		t.Fatalf("启动时保存的 Cookie = %q", saved)
+	}
+	waitFor(t, "首次列表请求", func() bool {
+		list, _ := fake.calls()
+		return list > 0
+	})
+
+	fake.setCookie(&http.Cookie{Name: "BDUSS", Value: "rotated-bduss", Path: "/"})
+	waitFor(t, "保存轮换后的 Cookie", func() bool {
+		return strings.Contains(sessions.SavedCookie(), "BDUSS=rotated-bduss")
+	})
+	mutex.Lock()
+	if len(pushed) != 1 || !strings.Contains(pushed[0], "BDUSS=rotated-bduss") {
+		t.Errorf("推送给前端的 Cookie = %q", pushed)
+	}
+	mutex.Unlock()
+
+	// 旧的 BDUSS 已失效，会话继续轮询说明后续请求使用了新值
+	list, _ := fake.calls()
+	waitFor(t, "继续轮询", func() bool {
+		now, _ := fake.calls()
+		return now >= list+2
+	})
+	if status, _ := sessions.Status(); status.State != SessionRunning || strings.Contains(status.LastError, "Cookie") {
+		t.Errorf("轮换 Cookie 后会话状态 = %+v", status)
+	}
+}
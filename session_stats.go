@@ -0,0 +1,197 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxLatencySamples 是计算 p95 耗时时保留的最近样本数
+const maxLatencySamples = 1000
+
+// 会话错误的类别
+const (
+	ErrorAuth         = "auth"          // Cookie 失效
+	ErrorListRequest  = "list_request"  // 获取任务列表的网络错误
+	ErrorListErrno    = "list_errno"    // 任务列表接口返回错误码
+	ErrorClaimRequest = "claim_request" // 认领请求的网络错误
+	ErrorClaimErrno   = "claim_errno"   // 认领接口返回错误码
+	ErrorClaimMissed  = "claim_missed"  // 认领接口成功返回但没有认领到任务，通常是被他人抢先
+)
+
+// SessionStats 是单个认领会话的统计，会话重新启动时清零
+type SessionStats struct {
+	StartedAt       time.Time `json:"startedAt"`
+	UptimeSeconds   float64   `json:"uptimeSeconds"`   // 运行时长，会话停止后不再增长
+	IntervalSeconds float64   `json:"intervalSeconds"` // 当前生效的认领间隔
+	Attempts        int       `json:"attempts"`        // 认领尝试次数
+	ActiveTasks     int       `json:"activeTasks"`
+	MaxConcurrent   int       `json:"maxConcurrent"`
+
+	ListCalls         int     `json:"listCalls"`
+	ClaimCalls        int     `json:"claimCalls"`
+	SuccessRate       float64 `json:"successRate"` // 认领到任务的认领请求比例，0-1
+	AvgListLatencyMs  float64 `json:"avgListLatencyMs"`
+	P95ListLatencyMs  float64 `json:"p95ListLatencyMs"`
+	AvgClaimLatencyMs float64 `json:"avgClaimLatencyMs"`
+	P95ClaimLatencyMs float64 `json:"p95ClaimLatencyMs"`
+
+	TasksSeen     int            `json:"tasksSeen"`     // 任务列表返回的任务数，同一任务每次出现都计数
+	TasksFiltered int            `json:"tasksFiltered"` // 通过筛选的任务数
+	TasksClaimed  int            `json:"tasksClaimed"`
+	Errors        map[string]int `json:"errors"` // 按类别统计的错误次数
+}
+
+// latencySamples 记录请求耗时，平均值覆盖所有样本，p95 只计算最近的样本
+type latencySamples struct {
+	recent []float64
+	next   int
+	sum    float64
+	count  int
+}
+
+func (l *latencySamples) observe(elapsed time.Duration) {
+	ms := float64(elapsed) / float64(time.Millisecond)
+	if len(l.recent) < maxLatencySamples {
+		l.recent = append(l.recent, ms)
+	} else {
+		l.recent[l.next] = ms
+		l.next = (l.next + 1) % maxLatencySamples
+	}
+	l.sum += ms
+	l.count++
+}
+
+func (l *latencySamples) avg() float64 {
+	if l.count == 0 {
+		return 0
+	}
+	return l.sum / float64(l.count)
+}
+
+func (l *latencySamples) p95() float64 {
+	sorted := slices.Clone(l.recent)
+	slices.Sort(sorted)
+	return percentile(sorted, 0.95)
+}
+
+// sessionStats 累计会话的请求和任务统计，认领协程会并发调用
+type sessionStats struct {
+	mutex         sync.Mutex
+	startedAt     time.Time
+	stoppedAt     time.Time
+	listCalls     int
+	claimCalls    int
+	claimSuccess  int // 认领到任务的认领请求数
+	listLatency   latencySamples
+	claimLatency  latencySamples
+	tasksSeen     int
+	tasksFiltered int
+	errors        map[string]int
+}
+
+func newSessionStats() *sessionStats {
+	return &sessionStats{errors: map[string]int{}}
+}
+
+// reset 在会话启动时清空统计
+func (s *sessionStats) reset(startedAt time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.startedAt, s.stoppedAt = startedAt, time.Time{}
+	s.listCalls, s.claimCalls, s.claimSuccess = 0, 0, 0
+	s.listLatency, s.claimLatency = latencySamples{}, latencySamples{}
+	s.tasksSeen, s.tasksFiltered = 0, 0
+	s.errors = map[string]int{}
+}
+
+// finish 记录会话停止时间
+func (s *sessionStats) finish(stoppedAt time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.stoppedAt = stoppedAt
+}
+
+// observeList 记录一次任务列表请求
+func (s *sessionStats) observeList(elapsed time.Duration, res *TaskListResponse, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.listCalls++
+	s.listLatency.observe(elapsed)
+	switch {
+	case err != nil && IsAuthFailure(err):
+		s.errors[ErrorAuth]++
+	case err != nil:
+		s.errors[ErrorListRequest]++
+	case IsAuthFailureErrno(res.Errno, res.Errmsg):
+		s.errors[ErrorAuth]++
+	case res.Errno != 0:
+		s.errors[ErrorListErrno]++
+	}
+}
+
+// observeTasks 记录一次筛选中返回和通过筛选的任务数
+func (s *sessionStats) observeTasks(seen, filtered int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.tasksSeen += seen
+	s.tasksFiltered += filtered
+}
+
+// observeClaim 记录一次认领请求，claimed 为成功认领的任务数
+func (s *sessionStats) observeClaim(elapsed time.Duration, res *ClaimResponse, err error, claimed int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.claimCalls++
+	s.claimLatency.observe(elapsed)
+	switch {
+	case err != nil && IsAuthFailure(err):
+		s.errors[ErrorAuth]++
+	case err != nil:
+		s.errors[ErrorClaimRequest]++
+	case claimed > 0:
+		s.claimSuccess++
+	case IsAuthFailureErrno(res.Errno, res.Errmsg):
+		s.errors[ErrorAuth]++
+	case res.Errno != 0:
+		s.errors[ErrorClaimErrno]++
+	default:
+		s.errors[ErrorClaimMissed]++
+	}
+}
+
+// snapshot 返回 now 时的统计，会话相关的计数由调用方填入
+func (s *sessionStats) snapshot(now time.Time) SessionStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := SessionStats{
+		StartedAt:         s.startedAt,
+		ListCalls:         s.listCalls,
+		ClaimCalls:        s.claimCalls,
+		AvgListLatencyMs:  s.listLatency.avg(),
+		P95ListLatencyMs:  s.listLatency.p95(),
+		AvgClaimLatencyMs: s.claimLatency.avg(),
+		P95ClaimLatencyMs: s.claimLatency.p95(),
+		TasksSeen:         s.tasksSeen,
+		TasksFiltered:     s.tasksFiltered,
+		Errors:            maps.Clone(s.errors),
+	}
+	if !s.startedAt.IsZero() {
+		end := now
+		if !s.stoppedAt.IsZero() {
+			end = s.stoppedAt
+		}
+		stats.UptimeSeconds = end.Sub(s.startedAt).Seconds()
+	}
+	if s.claimCalls > 0 {
+		stats.SuccessRate = float64(s.claimSuccess) / float64(s.claimCalls)
+	}
+	return stats
+}
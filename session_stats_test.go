@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"testing"
+	"time"
+)
+
+func TestSessionStatsErrorCategories(t *testing.T) {
+	tests := []struct {
+		name    string
+		observe func(s *sessionStats)
+		want    map[string]int
+	}{
+		{"列表成功", func(s *sessionStats) { s.observeList(time.Millisecond, &TaskListResponse{}, nil) }, map[string]int{}},
+		{"列表被重定向到登录页", func(s *sessionStats) {
+			s.observeList(time.Millisecond, nil, fmt.Errorf("获取任务列表: %w", ErrNotLoggedIn))
+		}, map[string]int{ErrorAuth: 1}},
+		{"列表网络错误", func(s *sessionStats) {
+			s.observeList(time.Millisecond, nil, errors.New("connection reset"))
+		}, map[string]int{ErrorListRequest: 1}},
+		{"列表返回未登录", func(s *sessionStats) {
+			s.observeList(time.Millisecond, &TaskListResponse{Errno: 110000, Errmsg: "用户未登录"}, nil)
+		}, map[string]int{ErrorAuth: 1}},
+		{"列表返回错误码", func(s *sessionStats) {
+			s.observeList(time.Millisecond, &TaskListResponse{Errno: 500001, Errmsg: "系统繁忙"}, nil)
+		}, map[string]int{ErrorListErrno: 1}},
+		{"认领成功", func(s *sessionStats) { s.observeClaim(time.Millisecond, &ClaimResponse{}, nil, 2) }, map[string]int{}},
+		{"认领被重定向到登录页", func(s *sessionStats) {
+			s.observeClaim(time.Millisecond, nil, ErrNotLoggedIn, 0)
+		}, map[string]int{ErrorAuth: 1}},
+		{"认领网络错误", func(s *sessionStats) {
+			s.observeClaim(time.Millisecond, nil, errors.New("timeout"), 0)
+		}, map[string]int{ErrorClaimRequest: 1}},
+		{"认领返回未登录", func(s *sessionStats) {
+			s.observeClaim(time.Millisecond, &ClaimResponse{Errno: 110000, Errmsg: "用户未登录"}, nil, 0)
+		}, map[string]int{ErrorAuth: 1}},
+		{"认领返回错误码", func(s *sessionStats) {
+			s.observeClaim(time.Millisecond, &ClaimResponse{Errno: 20001, Errmsg: "任务已下线"}, nil, 0)
+		}, map[string]int{ErrorClaimErrno: 1}},
+		{"认领被抢先", func(s *sessionStats) { s.observeClaim(time.Millisecond, &ClaimResponse{}, nil, 0) }, map[string]int{ErrorClaimMissed: 1}},
+	}
+	for _, tt := range tests {
+		stats := newSessionStats()
+		tt.observe(stats)
+		if got := stats.snapshot(time.Now()).Errors; !maps.Equal(got, tt.want) {
+			t.Errorf("%s: 错误统计 = %v，期望 %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSessionStatsSuccessRate(t *testing.T) {
+	stats := newSessionStats()
+	stats.observeClaim(10*time.Millisecond, &ClaimResponse{}, nil, 1)
+	stats.observeClaim(20*time.Millisecond, &ClaimResponse{}, nil, 0)
+	stats.observeClaim(30*time.Millisecond, &ClaimResponse{}, nil, 3)
+	stats.observeClaim(40*time.Millisecond, nil, errors.New("timeout"), 0)
+
+	snapshot := stats.snapshot(time.Now())
+	if snapshot.ClaimCalls != 4 || snapshot.SuccessRate != 0.5 {
+		t.Errorf("认领请求 = %d，成功率 = %g", snapshot.ClaimCalls, snapshot.SuccessRate)
+	}
+	if snapshot.AvgClaimLatencyMs != 25 || snapshot.P95ClaimLatencyMs != 40 {
+		t.Errorf("平均耗时 = %g，p95 = %g", snapshot.AvgClaimLatencyMs, snapshot.P95ClaimLatencyMs)
+	}
+}
+
+func TestLatencySamplesWrapAround(t *testing.T) {
+	var samples latencySamples
+	// 先写入 maxLatencySamples 个 1000ms 的慢请求，再写入同样多的 1ms 快请求
+	for range maxLatencySamples {
+		samples.observe(time.Second)
+	}
+	if got := samples.p95(); got != 1000 {
+		t.Fatalf("p95 = %g，期望 1000", got)
+	}
+	for i := range maxLatencySamples {
+		samples.observe(time.Millisecond)
+		// 慢请求还占最近样本的 5% 以上时 p95 仍为 1000
+		if i == maxLatencySamples*94/100 && samples.p95() != 1000 {
+			t.Fatalf("替换 %d 个样本后 p95 = %g，期望 1000", i+1, samples.p95())
+		}
+	}
+
+	if len(samples.recent) != maxLatencySamples || samples.next != 0 {
+		t.Errorf("环形缓冲区长度 = %d，下一个位置 = %d", len(samples.recent), samples.next)
+	}
+	if got := samples.p95(); got != 1 {
+		t.Errorf("旧样本被全部替换后 p95 = %g，期望 1", got)
+	}
+	// 平均值覆盖所有样本
+	if got, want := samples.avg(), 500.5; got != want {
+		t.Errorf("平均值 = %g，期望 %g", got, want)
+	}
+
+	samples.observe(2 * time.Millisecond)
+	if samples.next != 1 || samples.recent[0] != 2 {
+		t.Errorf("应覆盖最旧的样本，next = %d，recent[0] = %g", samples.next, samples.recent[0])
+	}
+}
+
+func TestSessionStatsUptimeFreezesAfterFinish(t *testing.T) {
+	start := time.Date(2025, 1, 7, 9, 0, 0, 0, time.UTC)
+	stats := newSessionStats()
+	if got := stats.snapshot(start).UptimeSeconds; got != 0 {
+		t.Errorf("未启动时运行时长 = %g", got)
+	}
+
+	stats.reset(start)
+	if got := stats.snapshot(start.Add(90 * time.Second)).UptimeSeconds; got != 90 {
+		t.Errorf("运行中的时长 = %g，期望 90", got)
+	}
+	stats.finish(start.Add(2 * time.Minute))
+	if got := stats.snapshot(start.Add(time.Hour)).UptimeSeconds; got != 120 {
+		t.Errorf("停止后的时长 = %g，期望 120", got)
+	}
+
+	// 重新启动时清零并重新计时
+	stats.observeList(time.Millisecond, nil, errors.New("timeout"))
+	stats.reset(start.Add(2 * time.Hour))
+	snapshot := stats.snapshot(start.Add(2*time.Hour + 5*time.Second))
+	if snapshot.UptimeSeconds != 5 || snapshot.ListCalls != 0 || len(snapshot.Errors) != 0 {
+		t.Errorf("重新启动后的统计 = %+v", snapshot)
+	}
+}
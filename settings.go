@@ -0,0 +1,351 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Settings 保存应用设置，优先级从低到高为：默认值、配置文件、环境变量、命令行参数
+type Settings struct {
+	UserAuthEndpoint string   `json:"userAuthEndpoint"`        // 定制授权使用的 LLM 测试端点
+	PocketBaseURLs   []string `json:"pocketBaseURLs"`          // 官方授权的 PocketBase 服务器，按顺序尝试
+	AllowlistPath    string   `json:"allowlistPath,omitempty"` // 白名单授权使用的文件
+	AuthCacheTTL     int      `json:"authCacheTTL"`            // 授权结果缓存时间（秒），0 表示不缓存
+	ControlAddr      string   `json:"controlAddr,omitempty"`   // HTTP 控制接口监听地址，为空时不启用
+	ControlToken     string   `json:"controlToken,omitempty"`  // HTTP 控制接口的访问令牌
+	Timezone         string   `json:"timezone,omitempty"`      // 平台时间所在时区，默认 Asia/Shanghai
+	RecordTraffic    string   `json:"recordTraffic,omitempty"` // 录制平台接口请求的文件，为空时不录制
+
+	PoolSnapshotInterval      int `json:"poolSnapshotInterval,omitempty"` // 保存线索池快照的间隔（秒），0 表示不保存
+	PoolSnapshotRetentionDays int `json:"poolSnapshotRetentionDays"`      // 线索池快照保留的天数，0 表示永久保留
+
+	Webhooks      []WebhookConfig      `json:"webhooks,omitempty"` // 认领事件的外发通知
+	Notifications NotificationSettings `json:"notifications"`      // 桌面通知开关
+
+	envErrors []error // applyEnv 无法解析的环境变量，由 validate 报告
+}
+
+// DefaultSettings 返回内置的默认设置
+func DefaultSettings() Settings {
+	return Settings{
+		UserAuthEndpoint: DefaultUserAuthEndpoint,
+		PocketBaseURLs:   []string{DefaultPocketBaseURL, DefaultBackupPocketBaseURL},
+		AuthCacheTTL:     600,
+
+		PoolSnapshotRetentionDays: 30,
+
+		Notifications: NotificationSettings{
+			BatchClaimed:  true,
+			LimitReached:  true,
+			CookieExpired: true,
+			Sound:         true,
+		},
+	}
+}
+
+// settingsPath 返回配置文件路径，可通过 BEDU_CLAIM_SETTINGS 环境变量指定
+func settingsPath() (string, error) {
+	if path := os.Getenv("BEDU_CLAIM_SETTINGS"); path != "" {
+		return path, nil
+	}
+	dir, err := appConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "settings.json"), nil
+}
+
+// LoadSettings 读取配置文件并应用环境变量，path 为空时使用默认配置文件，文件不存在时使用默认值
+func LoadSettings(path string) (Settings, error) {
+	settings, err := loadSettingsFile(path)
+	if err != nil {
+		return settings, err
+	}
+	settings.applyEnv()
+	return settings, settings.validate()
+}
+
+// loadSettingsFile 只读取配置文件，不应用环境变量，用于修改后写回
+func loadSettingsFile(path string) (Settings, error) {
+	settings := DefaultSettings()
+
+	if path == "" {
+		var err error
+		if path, err = settingsPath(); err != nil {
+			return settings, err
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return settings, fmt.Errorf("读取配置文件失败: %v", err)
+	default:
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return settings, fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+		}
+	}
+	return settings, nil
+}
+
+// saveSettingsKey 只把配置文件中的 key 替换为 value，其他字段原样保留，path 为空时使用默认配置文件
+func saveSettingsKey(path, key string, value any) error {
+	if path == "" {
+		var err error
+		if path, err = settingsPath(); err != nil {
+			return err
+		}
+	}
+
+	fields := map[string]json.RawMessage{}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("读取配置文件失败: %v", err)
+	default:
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+		}
+	}
+
+	if fields[key], err = json.Marshal(value); err != nil {
+		return err
+	}
+	if data, err = json.MarshalIndent(fields, "", "  "); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// applyEnv 使用环境变量覆盖设置，无法解析的值保留原设置并记录到 envErrors
+func (s *Settings) applyEnv() {
+	if endpoint := os.Getenv("BEDU_USER_AUTH_ENDPOINT"); endpoint != "" {
+		s.UserAuthEndpoint = endpoint
+	}
+	if urls := splitList(os.Getenv("BEDU_POCKETBASE_URLS")); len(urls) > 0 {
+		s.PocketBaseURLs = urls
+	}
+	if path := os.Getenv("BEDU_AUTH_ALLOWLIST"); path != "" {
+		s.AllowlistPath = path
+	}
+	if addr := os.Getenv("BEDU_CONTROL_ADDR"); addr != "" {
+		s.ControlAddr = addr
+	}
+	if token := os.Getenv("BEDU_CONTROL_TOKEN"); token != "" {
+		s.ControlToken = token
+	}
+	if tz := os.Getenv("BEDU_TIMEZONE"); tz != "" {
+		s.Timezone = tz
+	}
+	if path := os.Getenv("BEDU_RECORD_TRAFFIC"); path != "" {
+		s.RecordTraffic = path
+	}
+	if interval := os.Getenv("BEDU_POOL_SNAPSHOT_INTERVAL"); interval != "" {
+		if seconds, err := parseSeconds(interval); err == nil {
+			s.PoolSnapshotInterval = seconds
+		} else {
+			s.envErrors = append(s.envErrors, fmt.Errorf("环境变量 BEDU_POOL_SNAPSHOT_INTERVAL 无效: %v", err))
+		}
+	}
+	if days := os.Getenv("BEDU_POOL_SNAPSHOT_RETENTION_DAYS"); days != "" {
+		if n, err := strconv.Atoi(days); err == nil {
+			s.PoolSnapshotRetentionDays = n
+		} else {
+			s.envErrors = append(s.envErrors, fmt.Errorf("环境变量 BEDU_POOL_SNAPSHOT_RETENTION_DAYS 无效: %s", days))
+		}
+	}
+	if ttl := os.Getenv("BEDU_AUTH_CACHE_TTL"); ttl != "" {
+		if seconds, err := parseSeconds(ttl); err == nil {
+			s.AuthCacheTTL = seconds
+		} else {
+			s.envErrors = append(s.envErrors, fmt.Errorf("环境变量 BEDU_AUTH_CACHE_TTL 无效: %v", err))
+		}
+	}
+}
+
+// parseSeconds 解析秒数，同时接受 "10m" 这样的时长写法
+func parseSeconds(value string) (int, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return seconds, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("无效的时长: %s", value)
+	}
+	return int(d.Seconds()), nil
+}
+
+// validate 检查设置是否可用
+func (s Settings) validate() error {
+	if len(s.envErrors) > 0 {
+		return errors.Join(s.envErrors...)
+	}
+	if len(s.PocketBaseURLs) == 0 {
+		return fmt.Errorf("至少需要配置一个 PocketBase 服务器")
+	}
+	if s.AuthCacheTTL < 0 {
+		return fmt.Errorf("授权缓存时间不能为负数")
+	}
+	if s.PoolSnapshotInterval < 0 {
+		return fmt.Errorf("线索池快照间隔不能为负数")
+	}
+	if s.PoolSnapshotRetentionDays < 0 {
+		return fmt.Errorf("线索池快照保留天数不能为负数")
+	}
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return fmt.Errorf("无效的时区: %s", s.Timezone)
+		}
+	}
+	if s.ControlAddr != "" && len(s.ControlToken) < minControlTokenLength {
+		return fmt.Errorf("启用控制接口时访问令牌至少需要 %d 个字符", minControlTokenLength)
+	}
+	for _, u := range s.PocketBaseURLs {
+		if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+			return fmt.Errorf("无效的 PocketBase 服务器地址: %s", u)
+		}
+	}
+	for _, hook := range s.Webhooks {
+		if err := hook.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplySettings 设置平台时区，并使用设置中的地址重新注册内置的授权方式，启用缓存时包装为 CachedAuthorizer
+func ApplySettings(s Settings) {
+	if err := SetPlatformTimezone(s.Timezone); err != nil {
+		log.Printf("%v，继续使用 %s", err, PlatformLocation())
+	}
+	if s.RecordTraffic != "" {
+		log.Printf("正在录制平台接口请求到 %s（Cookie 已脱敏）", s.RecordTraffic)
+		SetTrafficRecorder(NewTrafficRecorder(s.RecordTraffic))
+	} else {
+		SetTrafficRecorder(nil)
+	}
+
+	servers := make([]string, 0, len(s.PocketBaseURLs))
+	for _, u := range s.PocketBaseURLs {
+		servers = append(servers, strings.TrimRight(u, "/"))
+	}
+	builtins := map[string]Authorizer{
+		"official":  &PocketBaseAuthorizer{Servers: servers},
+		"custom":    &LLMEndpointAuthorizer{Endpoint: s.UserAuthEndpoint},
+		"allowlist": &AllowlistAuthorizer{Path: s.AllowlistPath},
+	}
+	scopes := map[string]string{
+		"official":  strings.Join(servers, "\n"),
+		"custom":    s.UserAuthEndpoint,
+		"allowlist": s.AllowlistPath,
+	}
+
+	var cache *AuthCache
+	if s.AuthCacheTTL > 0 {
+		if dir, err := appConfigDir(); err == nil {
+			cache = NewAuthCache(dir, time.Duration(s.AuthCacheTTL)*time.Second)
+		}
+	}
+	for name, authorizer := range builtins {
+		if cache != nil {
+			authorizer = &CachedAuthorizer{Name: name, Scope: scopes[name], Inner: authorizer, Cache: cache}
+		}
+		RegisterAuthorizer(name, authorizer)
+	}
+}
+
+// settingsFlags 是无界面子命令共用的设置参数
+type settingsFlags struct {
+	path             string
+	userAuthEndpoint string
+	pocketBaseURLs   stringList
+	authCacheTTL     time.Duration
+	timezone         string
+	recordTraffic    string
+	poolSnapshots    time.Duration
+	poolRetention    int
+}
+
+// addSettingsFlags 为子命令注册设置相关参数
+func addSettingsFlags(fs *flag.FlagSet) *settingsFlags {
+	f := &settingsFlags{}
+	fs.StringVar(&f.path, "settings", "", "配置文件路径")
+	fs.StringVar(&f.userAuthEndpoint, "user-auth-endpoint", "", "定制授权使用的 LLM 测试端点")
+	fs.Var(&f.pocketBaseURLs, "pocketbase", "PocketBase 服务器地址，可重复指定，按顺序尝试")
+	fs.DurationVar(&f.authCacheTTL, "auth-cache-ttl", -1, "授权结果缓存时间，0 表示不缓存（默认使用配置文件）")
+	fs.StringVar(&f.timezone, "timezone", "", "平台时间所在时区（默认 Asia/Shanghai）")
+	fs.StringVar(&f.recordTraffic, "record-traffic", "", "将平台接口请求录制到指定文件（Cookie 会被脱敏）")
+	fs.DurationVar(&f.poolSnapshots, "pool-snapshot-interval", -1, "保存线索池快照的间隔，0 表示不保存（默认使用配置文件）")
+	fs.IntVar(&f.poolRetention, "pool-snapshot-retention-days", -1, "线索池快照保留的天数，0 表示永久保留（默认使用配置文件，未配置时为 30）")
+	return f
+}
+
+// load 读取配置文件和环境变量，再用命令行参数覆盖，并应用到授权方式
+func (f *settingsFlags) load() (Settings, error) {
+	settings, err := LoadSettings(f.path)
+	if err != nil {
+		return settings, err
+	}
+	if f.userAuthEndpoint != "" {
+		settings.UserAuthEndpoint = f.userAuthEndpoint
+	}
+	if len(f.pocketBaseURLs) > 0 {
+		settings.PocketBaseURLs = f.pocketBaseURLs
+	}
+	if f.authCacheTTL >= 0 {
+		settings.AuthCacheTTL = int(f.authCacheTTL.Seconds())
+	}
+	if f.timezone != "" {
+		settings.Timezone = f.timezone
+	}
+	if f.recordTraffic != "" {
+		settings.RecordTraffic = f.recordTraffic
+	}
+	if f.poolSnapshots >= 0 {
+		settings.PoolSnapshotInterval = int(f.poolSnapshots.Seconds())
+	}
+	if f.poolRetention >= 0 {
+		settings.PoolSnapshotRetentionDays = f.poolRetention
+	}
+	if err := settings.validate(); err != nil {
+		return settings, err
+	}
+	ApplySettings(settings)
+	return settings, nil
+}
+
+// stringList 是可重复指定的字符串参数，也接受逗号分隔的列表
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, splitList(value)...)
+	return nil
+}
+
+// splitList 按逗号拆分列表并去除空白项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
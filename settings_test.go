@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSaveSettingsKeyKeepsOtherFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	original := `{
+  "pocketBaseURLs": ["https://pb.example.com"],
+  "controlAddr": "127.0.0.1:9000",
+  "futureOption": {"enabled": true},
+  "notifications": {"batchClaimed": true, "sound": true}
+}`
+	if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := saveSettingsKey(path, "notifications", NotificationSettings{LimitReached: true}); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 4 {
+		t.Errorf("配置文件字段 = %s", data)
+	}
+	// 未写入文件的默认值（如 userAuthEndpoint、authCacheTTL）不应被补上
+	for _, key := range []string{"userAuthEndpoint", "authCacheTTL"} {
+		if _, ok := fields[key]; ok {
+			t.Errorf("不应写入 %s", key)
+		}
+	}
+	var future map[string]bool
+	if err := json.Unmarshal(fields["futureOption"], &future); err != nil || !future["enabled"] {
+		t.Errorf("未知字段 = %s", fields["futureOption"])
+	}
+
+	settings, err := loadSettingsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NotificationSettings{LimitReached: true}
+	if settings.Notifications != want || settings.ControlAddr != "127.0.0.1:9000" {
+		t.Errorf("重新读取的设置 = %+v", settings)
+	}
+}
+
+func TestSaveSettingsKeyCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bedu-claim", "settings.json")
+	if err := saveSettingsKey(path, "notifications", NotificationSettings{Sound: true}); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	settings, err := loadSettingsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.Notifications != (NotificationSettings{Sound: true}) || settings.UserAuthEndpoint != DefaultUserAuthEndpoint {
+		t.Errorf("设置 = %+v", settings)
+	}
+}
+
+func TestSaveSettingsKeyRefusesInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveSettingsKey(path, "notifications", NotificationSettings{}); err == nil {
+		t.Fatal("配置文件无法解析时应返回错误")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "{not json" {
+		t.Errorf("无法解析的配置文件被覆盖: %s", data)
+	}
+}
+
+// isolateSettings 清除影响设置的环境变量，并在测试结束时恢复默认的时区、录制设置和内置授权方式
+func isolateSettings(t *testing.T) {
+	t.Helper()
+
+	for _, name := range []string{
+		"BEDU_CLAIM_SETTINGS", "BEDU_USER_AUTH_ENDPOINT", "BEDU_POCKETBASE_URLS", "BEDU_AUTH_ALLOWLIST",
+		"BEDU_CONTROL_ADDR", "BEDU_CONTROL_TOKEN", "BEDU_TIMEZONE", "BEDU_RECORD_TRAFFIC",
+		"BEDU_POOL_SNAPSHOT_INTERVAL", "BEDU_POOL_SNAPSHOT_RETENTION_DAYS", "BEDU_AUTH_CACHE_TTL",
+	} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("BEDU_CLAIM_HOME", t.TempDir())
+	t.Cleanup(func() { ApplySettings(DefaultSettings()) })
+}
+
+func TestSettingsPrecedence(t *testing.T) {
+	isolateSettings(t)
+	path := filepath.Join(t.TempDir(), "settings.json")
+	file := `{
+  "userAuthEndpoint": "http://file/llm",
+  "pocketBaseURLs": ["http://pb-file"],
+  "allowlistPath": "file-allowlist.txt",
+  "authCacheTTL": 60,
+  "timezone": "UTC",
+  "poolSnapshotInterval": 30
+}`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BEDU_USER_AUTH_ENDPOINT", "http://env/llm")
+	t.Setenv("BEDU_AUTH_ALLOWLIST", "env-allowlist.txt")
+	t.Setenv("BEDU_AUTH_CACHE_TTL", "10m")
+	t.Setenv("BEDU_TIMEZONE", "America/New_York")
+	t.Setenv("BEDU_POOL_SNAPSHOT_INTERVAL", "5m")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := addSettingsFlags(fs)
+	err := fs.Parse([]string{"-settings", path, "-user-auth-endpoint", "http://flag/llm", "-timezone", "Asia/Tokyo", "-pool-snapshot-interval", "1m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, err := flags.load()
+	if err != nil {
+		t.Fatalf("加载设置失败: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		got, want any
+	}{
+		{"命令行覆盖环境变量和配置文件", settings.UserAuthEndpoint, "http://flag/llm"},
+		{"命令行覆盖时区", settings.Timezone, "Asia/Tokyo"},
+		{"命令行覆盖快照间隔", settings.PoolSnapshotInterval, 60},
+		{"环境变量覆盖配置文件", settings.AllowlistPath, "env-allowlist.txt"},
+		{"环境变量中的时长", settings.AuthCacheTTL, 600},
+		{"只在配置文件中设置", settings.PocketBaseURLs[0], "http://pb-file"},
+		{"未设置时使用默认值", settings.PoolSnapshotRetentionDays, 30},
+		{"未设置时使用默认通知开关", settings.Notifications.BatchClaimed, true},
+		{"应用到平台时区", PlatformLocation().String(), "Asia/Tokyo"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: %v，期望 %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadSettingsReportsInvalidEnv(t *testing.T) {
+	isolateSettings(t)
+	path := filepath.Join(t.TempDir(), "settings.json")
+	t.Setenv("BEDU_AUTH_CACHE_TTL", "ten minutes")
+	t.Setenv("BEDU_POOL_SNAPSHOT_INTERVAL", "5x")
+	t.Setenv("BEDU_POOL_SNAPSHOT_RETENTION_DAYS", "30d")
+
+	_, err := LoadSettings(path)
+	if err == nil {
+		t.Fatal("环境变量无效时应返回错误")
+	}
+	for _, name := range []string{"BEDU_AUTH_CACHE_TTL", "BEDU_POOL_SNAPSHOT_INTERVAL", "BEDU_POOL_SNAPSHOT_RETENTION_DAYS"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("错误中缺少 %s: %v", name, err)
+		}
+	}
+
+	t.Setenv("BEDU_AUTH_CACHE_TTL", "0")
+	t.Setenv("BEDU_POOL_SNAPSHOT_INTERVAL", "")
+	t.Setenv("BEDU_POOL_SNAPSHOT_RETENTION_DAYS", "")
+	if settings, err := LoadSettings(path); err != nil || settings.AuthCacheTTL != 0 {
+		t.Errorf("有效的环境变量 = %+v, %v", settings, err)
+	}
+}
+
+func TestApplySettingsServerFallback(t *testing.T) {
+	isolateSettings(t)
+
+	var mutex sync.Mutex
+	var hits []string
+	server := func(name string, status int) *httptest.Server {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			hits = append(hits, name)
+			mutex.Unlock()
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"name":"%s-user","exp_time":"2099-01-01 00:00:00.000Z","limit":5}`, name)
+		}))
+		t.Cleanup(s.Close)
+		return s
+	}
+	down := server("down", http.StatusOK)
+	down.Close()
+	failing := server("failing", http.StatusBadGateway)
+	primary := server("primary", http.StatusOK)
+	unused := server("unused", http.StatusOK)
+
+	settings := DefaultSettings()
+	settings.AuthCacheTTL = 0
+	settings.PocketBaseURLs = []string{down.URL, failing.URL + "/", primary.URL, unused.URL}
+	ApplySettings(settings)
+
+	authorizer, ok := LookupAuthorizer("official")
+	if !ok {
+		t.Fatal("官方授权未注册")
+	}
+	result, err := authorizer.Authorize(context.Background(), AutoClaimConfig{AuthUsername: "alice"})
+	if err != nil || result.UserName != "primary-user" || result.Limit != 5 {
+		t.Fatalf("授权结果 = %+v, %v", result, err)
+	}
+	if !slices.Equal(hits, []string{"failing", "primary"}) {
+		t.Errorf("按顺序访问的服务器 = %v，应跳过不可用的服务器并在第一个成功的服务器停止", hits)
+	}
+
+	// 服务器明确返回用户不存在时不再尝试后面的服务器
+	hits = nil
+	missing := server("missing", http.StatusNotFound)
+	settings.PocketBaseURLs = []string{missing.URL, primary.URL}
+	ApplySettings(settings)
+	authorizer, _ = LookupAuthorizer("official")
+	if _, err := authorizer.Authorize(context.Background(), AutoClaimConfig{AuthUsername: "alice"}); !IsAuthDenied(err) {
+		t.Errorf("用户不存在时应拒绝授权，得到 %v", err)
+	}
+	if !slices.Equal(hits, []string{"missing"}) {
+		t.Errorf("访问的服务器 = %v", hits)
+	}
+}
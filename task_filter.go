@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FilterRule 表示决定任务是否通过筛选的规则
+type FilterRule string
+
+const (
+	RuleNoFilter          FilterRule = "no_filter"           // 没有设置关键词，直接通过
+	RuleIncludeKeyword    FilterRule = "include_keyword"     // 命中包含关键词
+	RuleExcludeKeyword    FilterRule = "exclude_keyword"     // 命中排除关键词
+	RuleNoIncludeMatch    FilterRule = "no_include_match"    // 没有命中任何包含关键词
+	RuleMissingDispatch   FilterRule = "missing_dispatch"    // 设置了时间筛选，但任务没有发布时间
+	RuleOutsideTimeWindow FilterRule = "outside_time_window" // 发布时间不在筛选范围内
+)
+
+// FilterDecision 说明任务是否通过筛选以及由哪条规则决定
+type FilterDecision struct {
+	Passed  bool       `json:"passed"`
+	Rule    FilterRule `json:"rule"`
+	Keyword string     `json:"keyword,omitempty"` // 命中的关键词
+	Reason  string     `json:"reason"`
+}
+
+// evaluateKeywords 根据包含和排除关键词判断任务简介，排除关键词优先
+func evaluateKeywords(text string, includeKeywords, excludeKeywords []string) FilterDecision {
+	text = strings.ToLower(text)
+
+	for _, keyword := range excludeKeywords {
+		if keyword != "" && strings.Contains(text, strings.ToLower(keyword)) {
+			return FilterDecision{Rule: RuleExcludeKeyword, Keyword: keyword, Reason: fmt.Sprintf("包含排除关键词「%s」", keyword)}
+		}
+	}
+
+	if len(includeKeywords) == 0 {
+		return FilterDecision{Passed: true, Rule: RuleNoFilter, Reason: "未设置包含关键词"}
+	}
+
+	for _, keyword := range includeKeywords {
+		if keyword != "" && strings.Contains(text, strings.ToLower(keyword)) {
+			return FilterDecision{Passed: true, Rule: RuleIncludeKeyword, Keyword: keyword, Reason: fmt.Sprintf("命中包含关键词「%s」", keyword)}
+		}
+	}
+
+	return FilterDecision{Rule: RuleNoIncludeMatch, Reason: "没有命中任何包含关键词"}
+}
+
+// evaluateTask 按关键词和发布时间判断任务是否通过筛选，只有生产任务检查发布时间
+func evaluateTask(task TaskItem, config AutoClaimConfig, dispatch dispatchRange) FilterDecision {
+	decision := evaluateKeywords(task.Brief, config.IncludeKeywords, config.ExcludeKeywords)
+	if !decision.Passed || config.TaskType != "producetask" || !dispatch.active() {
+		return decision
+	}
+
+	if task.DispatchTime.IsZero() {
+		return FilterDecision{Rule: RuleMissingDispatch, Reason: "任务没有发布时间"}
+	}
+	if !dispatch.contains(task.DispatchTime) {
+		return FilterDecision{Rule: RuleOutsideTimeWindow, Reason: fmt.Sprintf("发布时间 %s 不在 %s 内", task.DispatchTime, dispatch)}
+	}
+	return decision
+}
+
+// maxPreviewPages 是筛选预览最多获取的页数
+const maxPreviewPages = 5
+
+// TaskPreview 是筛选预览中的一个任务及其筛选结果
+type TaskPreview struct {
+	Page     int            `json:"page"`
+	Task     TaskItem       `json:"task"`
+	Decision FilterDecision `json:"decision"`
+}
+
+// FilterPreview 是对当前线索池的筛选预览
+type FilterPreview struct {
+	Total   int           `json:"total"`   // 线索池中的任务总数
+	Matched int           `json:"matched"` // 预览的任务中通过筛选的数量
+	Window  string        `json:"window"`  // 生效的发布时间范围，未设置时为空
+	Tasks   []TaskPreview `json:"tasks"`
+}
+
+// previewFilter 获取前 pages 页任务并逐个给出筛选结果，不认领任何任务。
+// session 时间窗口按 now 作为会话开始时间计算
+func previewFilter(client *Client, config AutoClaimConfig, pages int, now time.Time) (*FilterPreview, error) {
+	if config.TaskType == "" {
+		config.TaskType = "audittask"
+	}
+	pages = min(max(pages, 1), maxPreviewPages)
+
+	dispatch := resolveDispatchRange(config, now, now)
+	preview := &FilterPreview{Tasks: []TaskPreview{}}
+	if config.TaskType == "producetask" && dispatch.active() {
+		preview.Window = dispatch.String()
+	}
+
+	for page := 1; page <= pages; page++ {
+		res, err := client.GetAuditTaskList(taskListOptions(config, page))
+		if err != nil {
+			return nil, fmt.Errorf("获取第 %d 页任务列表失败: %w", page, err)
+		}
+		if IsAuthFailureErrno(res.Errno, res.Errmsg) {
+			return nil, fmt.Errorf("%w: %s", ErrNotLoggedIn, res.Errmsg)
+		}
+		if res.Errno != 0 {
+			return nil, fmt.Errorf("获取第 %d 页任务列表失败: %s", page, res.Errmsg)
+		}
+
+		preview.Total = res.Data.Total
+		for _, task := range res.Data.List {
+			decision := evaluateTask(task, config, dispatch)
+			if decision.Passed {
+				preview.Matched++
+			}
+			preview.Tasks = append(preview.Tasks, TaskPreview{Page: page, Task: task, Decision: decision})
+		}
+		// 最后一页不足一页时不再继续请求
+		if len(res.Data.List) < 20 {
+			break
+		}
+	}
+	return preview, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import "testing"
+
+func TestEvaluateKeywords(t *testing.T) {
+	tests := []struct {
+		name             string
+		text             string
+		include, exclude []string
+		passed           bool
+		rule             FilterRule
+		keyword          string
+	}{
+		{"没有关键词", "数学 函数", nil, nil, true, RuleNoFilter, ""},
+		{"只有排除关键词且未命中", "数学 函数", nil, []string{"英语"}, true, RuleNoFilter, ""},
+		{"命中包含关键词", "数学 函数", []string{"物理", "函数"}, nil, true, RuleIncludeKeyword, "函数"},
+		{"没有命中包含关键词", "数学 函数", []string{"物理"}, nil, false, RuleNoIncludeMatch, ""},
+		{"排除优先于包含", "数学 函数 作文", []string{"函数"}, []string{"作文"}, false, RuleExcludeKeyword, "作文"},
+		{"包含关键词不区分大小写", "English Grammar", []string{"grammar"}, nil, true, RuleIncludeKeyword, "grammar"},
+		{"排除关键词不区分大小写", "english grammar", []string{"english"}, []string{"GRAMMAR"}, false, RuleExcludeKeyword, "GRAMMAR"},
+		{"忽略空关键词", "数学", []string{"", "数学"}, []string{""}, true, RuleIncludeKeyword, "数学"},
+		{"只有空包含关键词", "数学", []string{""}, nil, false, RuleNoIncludeMatch, ""},
+	}
+	for _, tt := range tests {
+		decision := evaluateKeywords(tt.text, tt.include, tt.exclude)
+		if decision.Passed != tt.passed || decision.Rule != tt.rule || decision.Keyword != tt.keyword {
+			t.Errorf("%s: 得到 %+v，期望 passed=%v rule=%s keyword=%q", tt.name, decision, tt.passed, tt.rule, tt.keyword)
+		}
+		if decision.Reason == "" {
+			t.Errorf("%s: 缺少原因说明", tt.name)
+		}
+	}
+}
+
+func TestEvaluateTask(t *testing.T) {
+	window := dispatchRange{
+		start: mustPlatformTime(t, "2025-01-07 08:00:00").Time,
+		end:   mustPlatformTime(t, "2025-01-07 12:00:00").Time,
+	}
+	inside := mustPlatformTime(t, "2025-01-07 09:00:00")
+	outside := mustPlatformTime(t, "2025-01-07 13:00:00")
+
+	tests := []struct {
+		name     string
+		task     TaskItem
+		taskType string
+		dispatch dispatchRange
+		passed   bool
+		rule     FilterRule
+	}{
+		{"发布时间在范围内", TaskItem{Brief: "数学", DispatchTime: inside}, "producetask", window, true, RuleIncludeKeyword},
+		{"发布时间在范围外", TaskItem{Brief: "数学", DispatchTime: outside}, "producetask", window, false, RuleOutsideTimeWindow},
+		{"没有发布时间", TaskItem{Brief: "数学"}, "producetask", window, false, RuleMissingDispatch},
+		{"未设置时间范围", TaskItem{Brief: "数学"}, "producetask", dispatchRange{}, true, RuleIncludeKeyword},
+		{"关键词不通过时不检查时间", TaskItem{Brief: "英语"}, "producetask", window, false, RuleNoIncludeMatch},
+		{"审核任务不检查时间", TaskItem{Brief: "数学", DispatchTime: outside}, "audittask", window, true, RuleIncludeKeyword},
+		{"审核任务没有发布时间", TaskItem{Brief: "数学"}, "audittask", window, true, RuleIncludeKeyword},
+	}
+	for _, tt := range tests {
+		config := AutoClaimConfig{TaskType: tt.taskType, IncludeKeywords: []string{"数学"}}
+		decision := evaluateTask(tt.task, config, tt.dispatch)
+		if decision.Passed != tt.passed || decision.Rule != tt.rule {
+			t.Errorf("%s: 得到 %+v，期望 passed=%v rule=%s", tt.name, decision, tt.passed, tt.rule)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// redactedValue 替换录制文件中的 Cookie 值和认证信息
+const redactedValue = "REDACTED"
+
+// TrafficEntry 是录制的一次请求和响应
+type TrafficEntry struct {
+	Time           time.Time   `json:"time"`
+	Method         string      `json:"method"`
+	URL            string      `json:"url"`
+	RequestHeader  http.Header `json:"requestHeader,omitempty"`
+	RequestBody    string      `json:"requestBody,omitempty"`
+	Status         int         `json:"status,omitempty"`
+	ResponseHeader http.Header `json:"responseHeader,omitempty"`
+	ResponseBody   string      `json:"responseBody,omitempty"`
+	Error          string      `json:"error,omitempty"` // 网络错误，此时没有响应
+}
+
+// piiFields 是 JSON 请求体和响应体中需要脱敏的字段（不区分大小写），
+// 如 /edushop/user/common/info 返回的账号名和头像
+var piiFields = map[string]bool{
+	"username": true,
+	"uname":    true,
+	"nickname": true,
+	"realname": true,
+	"avatar":   true,
+	"phone":    true,
+	"mobile":   true,
+	"email":    true,
+}
+
+// TrafficRecorder 将接口客户端的请求和响应追加写入 JSON Lines 文件，
+// Cookie、Set-Cookie 和 Authorization 的值以及 JSON 中的账号信息会被脱敏，便于附在问题反馈中
+type TrafficRecorder struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewTrafficRecorder 创建写入 path 的录制器
+func NewTrafficRecorder(path string) *TrafficRecorder {
+	return &TrafficRecorder{path: path}
+}
+
+// defaultRecorder 是 NewClient 创建的客户端默认使用的录制器，为 nil 时不录制
+var defaultRecorder atomic.Pointer[TrafficRecorder]
+
+// SetTrafficRecorder 设置之后创建的接口客户端使用的录制器，r 为 nil 时关闭录制
+func SetTrafficRecorder(r *TrafficRecorder) {
+	defaultRecorder.Store(r)
+}
+
+// Transport 返回录制经过 next 的所有请求的 RoundTripper
+func (r *TrafficRecorder) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &recordingTransport{next: next, recorder: r}
+}
+
+// append 追加一条录制记录
+func (r *TrafficRecorder) append(entry TrafficEntry) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o700); err != nil {
+		return fmt.Errorf("创建录制目录失败: %v", err)
+	}
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("打开录制文件失败: %v", err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+type recordingTransport struct {
+	next     http.RoundTripper
+	recorder *TrafficRecorder
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	entry := TrafficEntry{
+		Time:          time.Now(),
+		Method:        req.Method,
+		URL:           req.URL.String(),
+		RequestHeader: redactHeader(req.Header),
+	}
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			entry.RequestBody = redactBody(data)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		entry.Error = err.Error()
+		t.save(entry)
+		return nil, err
+	}
+
+	// 读出响应体用于录制，再还给调用方
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry.Status = resp.StatusCode
+	entry.ResponseHeader = redactHeader(resp.Header)
+	entry.ResponseBody = redactBody(body)
+	t.save(entry)
+	return resp, nil
+}
+
+// save 写入录制记录，失败时只记录日志，不影响请求
+func (t *recordingTransport) save(entry TrafficEntry) {
+	if err := t.recorder.append(entry); err != nil {
+		log.Printf("录制接口请求失败: %v", err)
+	}
+}
+
+// redactHeader 返回脱敏后的请求头副本
+func redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	cookies := redacted.Values("Cookie")
+	for i, value := range cookies {
+		cookies[i] = redactCookies(value)
+	}
+	setCookies := redacted.Values("Set-Cookie")
+	for i, value := range setCookies {
+		setCookies[i] = redactSetCookie(value)
+	}
+	if redacted.Get("Authorization") != "" {
+		redacted.Set("Authorization", redactedValue)
+	}
+	return redacted
+}
+
+// redactCookies 保留 Cookie 请求头中的名称，替换所有值
+func redactCookies(header string) string {
+	var parts []string
+	for _, pair := range strings.Split(header, ";") {
+		name, _, _ := strings.Cut(strings.TrimSpace(pair), "=")
+		if name != "" {
+			parts = append(parts, name+"="+redactedValue)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// redactSetCookie 替换 Set-Cookie 的值，保留名称和 Path、Expires 等属性
+func redactSetCookie(header string) string {
+	pair, attrs, hasAttrs := strings.Cut(header, ";")
+	name, _, _ := strings.Cut(pair, "=")
+	redacted := strings.TrimSpace(name) + "=" + redactedValue
+	if hasAttrs {
+		redacted += ";" + attrs
+	}
+	return redacted
+}
+
+// redactBody 替换 JSON 内容中 piiFields 字段的值，其他内容和非 JSON 内容保持原样
+func redactBody(body []byte) string {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() || !redactJSON(value) {
+		return string(body)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return string(body)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// redactJSON 递归替换 piiFields 字段的值，返回是否做了替换
+func redactJSON(value any) bool {
+	redacted := false
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if piiFields[strings.ToLower(key)] && field != nil {
+				v[key] = redactedValue
+				redacted = true
+			} else if redactJSON(field) {
+				redacted = true
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if redactJSON(item) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+// ReplayTransport 按录制文件回放响应，不访问网络。请求按方法、路径和查询参数匹配（忽略主机），
+// 同一请求按录制顺序依次返回，用完后重复最后一条，以便轮询可以一直进行
+type ReplayTransport struct {
+	mutex   sync.Mutex
+	entries map[string][]TrafficEntry
+	served  map[string]int
+}
+
+// NewReplayTransport 使用录制记录创建回放 Transport
+func NewReplayTransport(entries []TrafficEntry) (*ReplayTransport, error) {
+	t := &ReplayTransport{entries: map[string][]TrafficEntry{}, served: map[string]int{}}
+	for _, entry := range entries {
+		req, err := http.NewRequest(entry.Method, entry.URL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("录制记录中的地址无效: %v", err)
+		}
+		key := replayKey(req)
+		t.entries[key] = append(t.entries[key], entry)
+	}
+	return t, nil
+}
+
+// LoadReplayTransport 读取 TrafficRecorder 写入的录制文件
+func LoadReplayTransport(path string) (*ReplayTransport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取录制文件失败: %v", err)
+	}
+	defer f.Close()
+
+	var entries []TrafficEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry TrafficEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("解析录制文件第 %d 行失败: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取录制文件失败: %v", err)
+	}
+	return NewReplayTransport(entries)
+}
+
+// replayKey 生成匹配录制记录的键，查询参数按名称排序
+func replayKey(req *http.Request) string {
+	return req.Method + " " + req.URL.Path + "?" + req.URL.Query().Encode()
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	key := replayKey(req)
+	t.mutex.Lock()
+	entries := t.entries[key]
+	if len(entries) == 0 {
+		t.mutex.Unlock()
+		return nil, fmt.Errorf("录制文件中没有 %s 的响应", key)
+	}
+	entry := entries[min(t.served[key], len(entries)-1)]
+	t.served[key]++
+	t.mutex.Unlock()
+
+	if entry.Error != "" {
+		return nil, fmt.Errorf("回放录制的错误: %s", entry.Error)
+	}
+	header := entry.ResponseHeader.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.Status, http.StatusText(entry.Status)),
+		StatusCode:    entry.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(entry.ResponseBody)),
+		ContentLength: int64(len(entry.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// WithTransport 让会话的接口客户端通过 rt 发送请求，例如使用 ReplayTransport 回放录制文件
+func WithTransport(rt http.RoundTripper) AutoClaimerOption {
+	return func(ac *AutoClaimer) {
+		ac.client.HTTP.Transport = rt
+	}
+}
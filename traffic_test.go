@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTrafficRecorderRedactsCookies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "BDUSS", Value: "refreshed-secret", Path: "/"})
+		fmt.Fprint(w, `{"errno":0,"errmsg":"","data":{"userName":"recorded-user"}}`)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	SetTrafficRecorder(NewTrafficRecorder(path))
+	t.Cleanup(func() { SetTrafficRecorder(nil) })
+
+	info, err := NewClient(server.URL, "BDUSS=original-secret; STOKEN=stoken-secret").GetUserInfo()
+	if err != nil || info.Data.UserName != "recorded-user" {
+		t.Fatalf("录制时请求应正常返回: %+v, %v", info, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	capture := string(data)
+	for _, secret := range []string{"original-secret", "stoken-secret", "refreshed-secret", "recorded-user"} {
+		if strings.Contains(capture, secret) {
+			t.Errorf("录制文件包含未脱敏的 %q: %s", secret, capture)
+		}
+	}
+	for _, want := range []string{"BDUSS=REDACTED; STOKEN=REDACTED", "BDUSS=REDACTED; Path=/", `\"userName\":\"REDACTED\"`, "/edushop/user/common/info"} {
+		if !strings.Contains(capture, want) {
+			t.Errorf("录制文件缺少 %q: %s", want, capture)
+		}
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := map[string]string{
+		`{"errno":0,"data":{"userName":"alice","avatar":"https://img/a.png","roleNames":["审核员"]}}`: `{"data":{"avatar":"REDACTED","roleNames":["审核员"],"userName":"REDACTED"},"errno":0}`,
+		`{"data":{"list":[{"taskID":1,"Phone":"13800000000"}],"total":1}}`:                         `{"data":{"list":[{"Phone":"REDACTED","taskID":1}],"total":1}}`,
+		`{"errno":0,"data":{"total":12345678901234567890}}`:                                        `{"errno":0,"data":{"total":12345678901234567890}}`,
+		`{"data":{"userName":null}}`:                                                               `{"data":{"userName":null}}`,
+		`taskIDs=1&taskType=audittask`:                                                             `taskIDs=1&taskType=audittask`,
+	}
+	for body, want := range tests {
+		if got := redactBody([]byte(body)); got != want {
+			t.Errorf("redactBody(%s) = %s\n期望 %s", body, got, want)
+		}
+	}
+}
+
+func TestReplayTransportServesCaptureInOrder(t *testing.T) {
+	replay, err := NewReplayTransport([]TrafficEntry{
+		{Method: "GET", URL: "https://easylearn.baidu.com/edushop/user/common/info", Status: 200, ResponseBody: `{"errno":0,"data":{"userName":"first"}}`},
+		{Method: "GET", URL: "https://easylearn.baidu.com/edushop/user/common/info", Status: 200, ResponseBody: `{"errno":0,"data":{"userName":"second"}}`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient("http://replay.invalid", "")
+	client.HTTP.Transport = replay
+
+	// 忽略主机，按录制顺序返回，用完后重复最后一条
+	for _, want := range []string{"first", "second", "second"} {
+		info, err := client.GetUserInfo()
+		if err != nil || info.Data.UserName != want {
+			t.Fatalf("回放结果 = %+v, %v，期望 %s", info, err, want)
+		}
+	}
+
+	if _, err := client.GetAuditTaskLabel("audittask"); err == nil || !strings.Contains(err.Error(), "没有") {
+		t.Errorf("未录制的请求应返回错误，得到 %v", err)
+	}
+}
+
+func TestRecordedSessionReplaysDeterministically(t *testing.T) {
+	fake := newFakeEasylearn(t)
+	fake.addTasks("audittask",
+		TaskItem{TaskID: 1, Brief: "英语 完形填空"},
+		TaskItem{TaskID: 2, Brief: "数学 二次函数"},
+	)
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	config := AutoClaimConfig{TaskType: "audittask", ClaimLimit: 1, Interval: 0.05, IncludeKeywords: []string{"数学"}}
+
+	// 录制一次真实的认领会话
+	record := config
+	record.ServerBaseURL, record.Cookie = fake.URL, fakeCookie
+	recordEvents := make(chan ClaimEvent, 100)
+	recorder, err := StartAutoClaiming(context.Background(), record,
+		WithTransport(NewTrafficRecorder(path).Transport(nil)),
+		WithEventListener(func(event ClaimEvent) { recordEvents <- event }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recorder.Stop()
+	waitForEvent(t, recordEvents, EventStopped)
+
+	// 用录制文件回放，不访问替身服务器
+	fake.Close()
+	replay, err := LoadReplayTransport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	play := config
+	play.ServerBaseURL, play.Cookie = "http://replay.invalid", "BDUSS=REDACTED"
+	events := make(chan ClaimEvent, 100)
+	player, err := StartAutoClaiming(context.Background(), play,
+		WithTransport(replay),
+		WithEventListener(func(event ClaimEvent) { events <- event }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer player.Stop()
+
+	claimed := waitForEvent(t, events, EventTaskClaimed)
+	if claimed.Task == nil || claimed.Task.TaskID != 2 {
+		t.Errorf("回放认领的任务 = %+v，期望 2", claimed.Task)
+	}
+	if stopped := waitForEvent(t, events, EventStopped); stopped.State != SessionLimitReached {
+		t.Errorf("回放会话停止状态 = %s（%s）", stopped.State, stopped.Message)
+	}
+}
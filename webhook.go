@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// WebhookConfig 是一个外发 Webhook 的配置
+type WebhookConfig struct {
+	URL     string           `json:"url"`
+	Format  string           `json:"format,omitempty"`  // 消息格式，见 webhookFormats，默认 json
+	Events  []ClaimEventType `json:"events,omitempty"`  // 需要通知的事件，默认认领成功和会话停止
+	Timeout *int             `json:"timeout,omitempty"` // 单次请求超时（秒），未设置时为 10 秒
+	Retries *int             `json:"retries,omitempty"` // 失败后的重试次数，未设置时为 3 次，0 表示不重试
+}
+
+// Webhook 未设置超时和重试次数时使用的默认值
+const (
+	defaultWebhookTimeout = 10 * time.Second
+	defaultWebhookRetries = 3
+)
+
+// defaultWebhookEvents 是未指定事件时通知的事件，会话因达到上限、登录态失效或授权过期停止时都会产生 stopped 事件
+var defaultWebhookEvents = []ClaimEventType{EventTaskClaimed, EventStopped}
+
+// webhookFormats 将事件转换为各类机器人接受的请求体
+var webhookFormats = map[string]func(event ClaimEvent) any{
+	"json": func(event ClaimEvent) any { return event },
+	"dingtalk": func(event ClaimEvent) any {
+		return map[string]any{"msgtype": "text", "text": map[string]string{"content": webhookText(event)}}
+	},
+	"wecom": func(event ClaimEvent) any {
+		return map[string]any{"msgtype": "text", "text": map[string]string{"content": webhookText(event)}}
+	},
+	"feishu": func(event ClaimEvent) any {
+		return map[string]any{"msg_type": "text", "content": map[string]string{"text": webhookText(event)}}
+	},
+	"slack": func(event ClaimEvent) any {
+		return map[string]string{"text": webhookText(event)}
+	},
+	"discord": func(event ClaimEvent) any {
+		return map[string]string{"content": webhookText(event)}
+	},
+}
+
+// webhookText 生成机器人消息的文本内容
+func webhookText(event ClaimEvent) string {
+	return fmt.Sprintf("[bedu-claim] %s（已认领 %d 个，%s）", event.Message, event.Claimed, event.Time.Local().Format("2006-01-02 15:04:05"))
+}
+
+// validate 检查 Webhook 配置是否可用
+func (c WebhookConfig) validate() error {
+	if !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+		return fmt.Errorf("无效的 Webhook 地址: %s", c.URL)
+	}
+	if _, ok := webhookFormats[c.format()]; !ok {
+		return fmt.Errorf("不支持的 Webhook 格式: %s", c.Format)
+	}
+	if c.Timeout != nil && *c.Timeout <= 0 {
+		return fmt.Errorf("Webhook 超时必须大于 0 秒: %s", c.URL)
+	}
+	if c.Retries != nil && *c.Retries < 0 {
+		return fmt.Errorf("Webhook 重试次数不能为负数: %s", c.URL)
+	}
+	return nil
+}
+
+// timeout 返回单次请求的超时，未设置时使用默认值
+func (c WebhookConfig) timeout() time.Duration {
+	if c.Timeout == nil || *c.Timeout <= 0 {
+		return defaultWebhookTimeout
+	}
+	return time.Duration(*c.Timeout) * time.Second
+}
+
+// retries 返回失败后的重试次数，未设置时使用默认值，显式设置的 0 表示不重试
+func (c WebhookConfig) retries() int {
+	if c.Retries == nil {
+		return defaultWebhookRetries
+	}
+	return max(*c.Retries, 0)
+}
+
+func (c WebhookConfig) format() string {
+	if c.Format == "" {
+		return "json"
+	}
+	return c.Format
+}
+
+// wants 判断是否需要通知该事件
+func (c WebhookConfig) wants(eventType ClaimEventType) bool {
+	events := c.Events
+	if len(events) == 0 {
+		events = defaultWebhookEvents
+	}
+	return slices.Contains(events, eventType)
+}
+
+// WebhookNotifier 将自动认领事件发送到配置的 Webhook
+type WebhookNotifier struct {
+	hooks      []WebhookConfig
+	client     *http.Client
+	retryDelay time.Duration // 第 n 次重试前等待 n*retryDelay
+}
+
+// NewWebhookNotifier 创建 Webhook 通知器
+func NewWebhookNotifier(hooks []WebhookConfig) *WebhookNotifier {
+	return &WebhookNotifier{hooks: hooks, client: &http.Client{}, retryDelay: time.Second}
+}
+
+// Notify 异步发送事件，可直接作为事件监听器使用
+func (n *WebhookNotifier) Notify(event ClaimEvent) {
+	for _, hook := range n.hooks {
+		if !hook.wants(event.Type) {
+			continue
+		}
+		go func() {
+			if err := n.Send(context.Background(), hook, event); err != nil {
+				log.Printf("发送 Webhook 失败 (%s): %v", hook.URL, err)
+			}
+		}()
+	}
+}
+
+// Send 发送一个事件，网络错误、429 和 5xx 响应会按配置重试
+func (n *WebhookNotifier) Send(ctx context.Context, hook WebhookConfig, event ClaimEvent) error {
+	build, ok := webhookFormats[hook.format()]
+	if !ok {
+		return fmt.Errorf("不支持的 Webhook 格式: %s", hook.Format)
+	}
+	body, err := json.Marshal(build(event))
+	if err != nil {
+		return err
+	}
+
+	timeout, retries := hook.timeout(), hook.retries()
+
+	for attempt := 0; ; attempt++ {
+		var retryable bool
+		retryable, err = n.post(ctx, hook.URL, body, timeout)
+		if err == nil || !retryable || attempt >= retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt+1) * n.retryDelay):
+		}
+	}
+}
+
+// post 发送一次请求，返回失败时是否值得重试
+func (n *WebhookNotifier) post(ctx context.Context, url string, body []byte, timeout time.Duration) (bool, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bedu-claim")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, &HTTPStatusError{StatusCode: resp.StatusCode, URL: url}
+}
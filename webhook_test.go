@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestNotifier 创建重试间隔很短的通知器，避免测试等待
+func newTestNotifier(hooks ...WebhookConfig) *WebhookNotifier {
+	n := NewWebhookNotifier(hooks)
+	n.retryDelay = time.Millisecond
+	return n
+}
+
+// ptr 返回 v 的指针，用于设置可选字段
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func testClaimEvent() ClaimEvent {
+	return ClaimEvent{
+		Type:    EventTaskClaimed,
+		Time:    time.Date(2025, 1, 7, 11, 6, 37, 0, time.UTC),
+		State:   SessionRunning,
+		Message: "已认领任务：二次函数",
+		Claimed: 3,
+		Task:    &TaskItem{TaskID: 42, Brief: "二次函数"},
+		Cookie:  "BDUSS=secret",
+	}
+}
+
+func TestWebhookSendGenericJSON(t *testing.T) {
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer server.Close()
+
+	hook := WebhookConfig{URL: server.URL}
+	if err := newTestNotifier(hook).Send(context.Background(), hook, testClaimEvent()); err != nil {
+		t.Fatalf("Send 返回错误: %v", err)
+	}
+
+	body := <-bodies
+	var got map[string]any
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("请求体不是 JSON: %s", body)
+	}
+	if got["type"] != "task_claimed" || got["message"] != "已认领任务：二次函数" || got["claimed"] != float64(3) {
+		t.Errorf("请求体 = %s", body)
+	}
+	if task, _ := got["task"].(map[string]any); task["taskID"] != float64(42) {
+		t.Errorf("请求体缺少任务信息: %s", body)
+	}
+	if strings.Contains(string(body), "secret") {
+		t.Errorf("请求体不应包含 Cookie: %s", body)
+	}
+}
+
+func TestWebhookSendChatTemplates(t *testing.T) {
+	tests := []struct {
+		format string
+		path   []string // 文本内容在请求体中的位置
+	}{
+		{"dingtalk", []string{"text", "content"}},
+		{"wecom", []string{"text", "content"}},
+		{"feishu", []string{"content", "text"}},
+		{"slack", []string{"text"}},
+		{"discord", []string{"content"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var got map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&got)
+			}))
+			defer server.Close()
+
+			hook := WebhookConfig{URL: server.URL, Format: tt.format}
+			if err := newTestNotifier(hook).Send(context.Background(), hook, testClaimEvent()); err != nil {
+				t.Fatalf("Send 返回错误: %v", err)
+			}
+
+			var value any = got
+			for _, key := range tt.path {
+				m, _ := value.(map[string]any)
+				value = m[key]
+			}
+			text, _ := value.(string)
+			if !strings.Contains(text, "已认领任务：二次函数") || !strings.Contains(text, "已认领 3 个") {
+				t.Errorf("消息内容 = %q，请求体 = %v", text, got)
+			}
+		})
+	}
+}
+
+func TestWebhookRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	hook := WebhookConfig{URL: server.URL, Retries: ptr(3)}
+	if err := newTestNotifier(hook).Send(context.Background(), hook, testClaimEvent()); err != nil {
+		t.Fatalf("Send 返回错误: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("请求次数 = %d，期望 3", calls.Load())
+	}
+}
+
+func TestWebhookGivesUpAfterRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	hook := WebhookConfig{URL: server.URL, Retries: ptr(2)}
+	err := newTestNotifier(hook).Send(context.Background(), hook, testClaimEvent())
+	if err == nil {
+		t.Fatal("期望返回错误")
+	}
+	if calls.Load() != 3 {
+		t.Errorf("请求次数 = %d，期望 3（首次 + 2 次重试）", calls.Load())
+	}
+}
+
+func TestWebhookZeroRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	hook := WebhookConfig{URL: server.URL, Retries: ptr(0)}
+	if err := newTestNotifier(hook).Send(context.Background(), hook, testClaimEvent()); err == nil {
+		t.Fatal("期望返回错误")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("请求次数 = %d，retries 为 0 时期望 1", calls.Load())
+	}
+}
+
+func TestWebhookDefaults(t *testing.T) {
+	var hook WebhookConfig
+	if err := json.Unmarshal([]byte(`{"url": "https://example.com/hook"}`), &hook); err != nil {
+		t.Fatal(err)
+	}
+	if hook.timeout() != defaultWebhookTimeout || hook.retries() != defaultWebhookRetries {
+		t.Errorf("未设置时超时 = %v，重试 = %d", hook.timeout(), hook.retries())
+	}
+	if err := json.Unmarshal([]byte(`{"url": "https://example.com/hook", "timeout": 2, "retries": 0}`), &hook); err != nil {
+		t.Fatal(err)
+	}
+	if hook.timeout() != 2*time.Second || hook.retries() != 0 {
+		t.Errorf("显式设置时超时 = %v，重试 = %d", hook.timeout(), hook.retries())
+	}
+}
+
+func TestWebhookDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	hook := WebhookConfig{URL: server.URL}
+	if err := newTestNotifier(hook).Send(context.Background(), hook, testClaimEvent()); err == nil {
+		t.Fatal("期望返回错误")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("请求次数 = %d，期望 1", calls.Load())
+	}
+}
+
+func TestWebhookTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	hook := WebhookConfig{URL: server.URL, Timeout: ptr(1), Retries: ptr(1)}
+	start := time.Now()
+	if err := newTestNotifier(hook).Send(context.Background(), hook, testClaimEvent()); err == nil {
+		t.Fatal("期望超时错误")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("超时后仍等待了 %v", elapsed)
+	}
+}
+
+func TestWebhookNotifyFiltersEvents(t *testing.T) {
+	received := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event ClaimEvent
+		json.NewDecoder(r.Body).Decode(&event)
+		received <- string(event.Type)
+	}))
+	defer server.Close()
+
+	n := newTestNotifier(WebhookConfig{URL: server.URL, Events: []ClaimEventType{EventStopped}})
+	n.Notify(testClaimEvent())
+	n.Notify(ClaimEvent{Type: EventStopped, State: SessionLimitReached, Message: "认领限制已达到"})
+
+	select {
+	case got := <-received:
+		if got != string(EventStopped) {
+			t.Errorf("收到事件 %s，期望 stopped", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("没有收到 Webhook 请求")
+	}
+	select {
+	case got := <-received:
+		t.Errorf("不应通知 %s 事件", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWebhookConfigValidate(t *testing.T) {
+	valid := []WebhookConfig{
+		{URL: "https://example.com/hook"},
+		{URL: "http://127.0.0.1:9000", Format: "dingtalk"},
+		{URL: "https://example.com/hook", Timeout: ptr(5), Retries: ptr(0)},
+	}
+	for _, hook := range valid {
+		if err := hook.validate(); err != nil {
+			t.Errorf("%+v 应有效: %v", hook, err)
+		}
+	}
+	invalid := []WebhookConfig{
+		{URL: "example.com/hook"},
+		{URL: "https://example.com/hook", Format: "telegram"},
+		{URL: "https://example.com/hook", Timeout: ptr(0)},
+		{URL: "https://example.com/hook", Retries: ptr(-1)},
+	}
+	for _, hook := range invalid {
+		if err := hook.validate(); err == nil {
+			t.Errorf("%+v 应无效", hook)
+		}
+	}
+}